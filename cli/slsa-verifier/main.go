@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 
+	"github.com/slsa-framework/slsa-verifier/v2/logging"
 	"github.com/slsa-framework/slsa-verifier/v2/options"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/release-utils/version"
@@ -37,6 +39,7 @@ For more information on SLSA, visit https://slsa.dev`,
 	c.AddCommand(verifyArtifactCmd())
 	c.AddCommand(verifyImageCmd())
 	c.AddCommand(verifyNpmPackageCmd())
+	c.AddCommand(verifyVSACmd())
 	// We print our own errors and usage in the check function.
 	c.SilenceErrors = true
 	return c
@@ -44,5 +47,7 @@ For more information on SLSA, visit https://slsa.dev`,
 
 func main() {
 	envWarnings()
-	check(rootCmd().Execute())
+	// Print the progress of verification for humans.
+	ctx := logging.WithLogger(context.Background(), logging.TextLogger(os.Stderr))
+	check(rootCmd().ExecuteContext(ctx))
 }
@@ -920,6 +920,9 @@ func Test_runVerifyGCBArtifactImage(t *testing.T) {
 		ptag           *string
 		pversiontag    *string
 		pBuilderID     *string
+		substitutions  map[string]string
+		trigger        *string
+		inputs         map[string]string
 		outBuilderID   string
 		err            error
 		// noversion is a special case where we are not testing all builder versions
@@ -1186,6 +1189,52 @@ func Test_runVerifyGCBArtifactImage(t *testing.T) {
 			minversion:  "v0.3",
 			err:         serrors.ErrorMismatchVersionedTag,
 		},
+		{
+			name:       "substitutions match",
+			artifact:   "gcloud-container-github-tag",
+			provenance: "gcloud-container-github-tag.json",
+			source:     "github.com/slsa-framework/example-package",
+			substitutions: map[string]string{
+				"TRIGGER_BUILD_CONFIG_PATH": "cloudbuild.yaml",
+				"_IMAGE_NAME":               "slsa-tooling/example-package-repo/e2e-gcb-tag-main-annotated-slsa3",
+			},
+			minversion: "v0.3",
+		},
+		{
+			name:          "substitutions mismatch",
+			artifact:      "gcloud-container-github-tag",
+			provenance:    "gcloud-container-github-tag.json",
+			source:        "github.com/slsa-framework/example-package",
+			substitutions: map[string]string{"_IMAGE_NAME": "slsa-tooling/other"},
+			minversion:    "v0.3",
+			err:           serrors.ErrorMismatchBuildSubstitution,
+		},
+		{
+			name:       "trigger match",
+			artifact:   "gcloud-container-github-tag",
+			provenance: "gcloud-container-github-tag.json",
+			source:     "github.com/slsa-framework/example-package",
+			trigger:    pString("push-tag"),
+			minversion: "v0.3",
+		},
+		{
+			name:       "trigger mismatch",
+			artifact:   "gcloud-container-github-tag",
+			provenance: "gcloud-container-github-tag.json",
+			source:     "github.com/slsa-framework/example-package",
+			trigger:    pString("push-branch"),
+			minversion: "v0.3",
+			err:        serrors.ErrorMismatchBuildTrigger,
+		},
+		{
+			name:       "workflow inputs not supported",
+			artifact:   "gcloud-container-github-tag",
+			provenance: "gcloud-container-github-tag.json",
+			source:     "github.com/slsa-framework/example-package",
+			inputs:     map[string]string{"release_version": "v33.0.4"},
+			minversion: "v0.3",
+			err:        serrors.ErrorNotSupported,
+		},
 		{
 			name:        "versioned tag mismatch minor",
 			artifact:    "gcloud-container-github-tag",
@@ -1324,12 +1373,15 @@ func Test_runVerifyGCBArtifactImage(t *testing.T) {
 				// and semver.
 				for _, bid := range builderIDs {
 					cmd := verify.VerifyImageCommand{
-						SourceURI:        tt.source,
-						SourceBranch:     nil,
-						BuilderID:        &bid,
-						SourceTag:        tt.ptag,
-						SourceVersionTag: tt.pversiontag,
-						ProvenancePath:   &provenance,
+						SourceURI:           tt.source,
+						SourceBranch:        nil,
+						BuilderID:           &bid,
+						SourceTag:           tt.ptag,
+						SourceVersionTag:    tt.pversiontag,
+						ProvenancePath:      &provenance,
+						BuildSubstitutions:  tt.substitutions,
+						GCBTrigger:          tt.trigger,
+						BuildWorkflowInputs: tt.inputs,
 					}
 
 					outBuilderID, err := cmd.Exec(context.Background(), []string{image})
@@ -26,10 +26,13 @@ import (
 const (
 	SUCCESS = "PASSED: Verified SLSA provenance"
 	FAILURE = "FAILED: SLSA verification failed"
+
+	VSASuccess = "PASSED: Verified VSA"
+	VSAFailure = "FAILED: VSA verification failed"
 )
 
 func verifyArtifactCmd() *cobra.Command {
-	o := &verify.VerifyOptions{}
+	o := &verify.VerifyArtifactOptions{}
 
 	cmd := &cobra.Command{
 		Use: "verify-artifact [flags] artifact [artifact..]",
@@ -41,11 +44,26 @@ func verifyArtifactCmd() *cobra.Command {
 		},
 		Short: "Verifies SLSA provenance on artifact blobs given as arguments (assuming same provenance)",
 		Run: func(cmd *cobra.Command, args []string) {
+			verifierOpts, err := o.VerifierOptions()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", FAILURE, err)
+				os.Exit(1)
+			}
 			v := verify.VerifyArtifactCommand{
 				ProvenancePath:      o.ProvenancePath,
 				SourceURI:           o.SourceURI,
 				PrintProvenance:     o.PrintProvenance,
 				BuildWorkflowInputs: o.BuildWorkflowInputs.AsMap(),
+				BuildTriggers:       o.BuildTriggers,
+				BuildSubstitutions:  o.BuildSubstitutions.AsMap(),
+				RequireHostedRunner: o.RequireHostedRunner,
+				MatchArtifactName:   o.MatchArtifactName,
+				DigestAlgorithms:    o.DigestAlgorithms,
+				ChecksumsFile:       o.ChecksumsFile,
+				PublicKeys:          o.PublicKeys,
+				SignatureThreshold:  o.SignatureThreshold,
+				VSASigningKey:       o.VSASigningKey,
+				Verifier:            o.NetworkOptions.Verifier(cmd, verifierOpts...),
 			}
 			if cmd.Flags().Changed("source-branch") {
 				v.SourceBranch = &o.SourceBranch
@@ -56,9 +74,24 @@ func verifyArtifactCmd() *cobra.Command {
 			if cmd.Flags().Changed("source-versioned-tag") {
 				v.SourceVersionTag = &o.SourceVersionTag
 			}
+			if cmd.Flags().Changed("source-commit") {
+				v.SourceCommit = &o.SourceCommit
+			}
+			if cmd.Flags().Changed("source-checkout") {
+				v.SourceCheckout = &o.SourceCheckout
+			}
+			if cmd.Flags().Changed("expected-subject-name") {
+				v.SubjectName = &o.SubjectName
+			}
 			if cmd.Flags().Changed("builder-id") {
 				v.BuilderID = &o.BuilderID
 			}
+			if cmd.Flags().Changed("gcb-trigger") {
+				v.GCBTrigger = &o.GCBTrigger
+			}
+			if cmd.Flags().Changed("emit-vsa") {
+				v.EmitVSA = &o.EmitVSA
+			}
 
 			if _, err := v.Exec(cmd.Context(), args); err != nil {
 				fmt.Fprintf(os.Stderr, "%s: %v\n", FAILURE, err)
@@ -76,7 +109,7 @@ func verifyArtifactCmd() *cobra.Command {
 }
 
 func verifyImageCmd() *cobra.Command {
-	o := &verify.VerifyOptions{}
+	o := &verify.VerifyImageOptions{}
 
 	cmd := &cobra.Command{
 		Use: "verify-image [flags] image",
@@ -88,10 +121,25 @@ func verifyImageCmd() *cobra.Command {
 		},
 		Short: "Verifies SLSA provenance on a container image",
 		Run: func(cmd *cobra.Command, args []string) {
+			verifierOpts, err := o.VerifierOptions()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", FAILURE, err)
+				os.Exit(1)
+			}
 			v := verify.VerifyImageCommand{
 				SourceURI:           o.SourceURI,
 				PrintProvenance:     o.PrintProvenance,
 				BuildWorkflowInputs: o.BuildWorkflowInputs.AsMap(),
+				BuildTriggers:       o.BuildTriggers,
+				BuildSubstitutions:  o.BuildSubstitutions.AsMap(),
+				RequireHostedRunner: o.RequireHostedRunner,
+				PublicKeys:          o.PublicKeys,
+				SignatureThreshold:  o.SignatureThreshold,
+				VSASigningKey:       o.VSASigningKey,
+				Verifier:            o.NetworkOptions.Verifier(cmd, verifierOpts...),
+			}
+			if cmd.Flags().Changed("gcb-trigger") {
+				v.GCBTrigger = &o.GCBTrigger
 			}
 			if cmd.Flags().Changed("provenance-path") {
 				v.ProvenancePath = &o.ProvenancePath
@@ -108,9 +156,21 @@ func verifyImageCmd() *cobra.Command {
 			if cmd.Flags().Changed("source-versioned-tag") {
 				v.SourceVersionTag = &o.SourceVersionTag
 			}
+			if cmd.Flags().Changed("source-commit") {
+				v.SourceCommit = &o.SourceCommit
+			}
+			if cmd.Flags().Changed("source-checkout") {
+				v.SourceCheckout = &o.SourceCheckout
+			}
+			if cmd.Flags().Changed("expected-subject-name") {
+				v.SubjectName = &o.SubjectName
+			}
 			if cmd.Flags().Changed("builder-id") {
 				v.BuilderID = &o.BuilderID
 			}
+			if cmd.Flags().Changed("emit-vsa") {
+				v.EmitVSA = &o.EmitVSA
+			}
 
 			if _, err := v.Exec(cmd.Context(), args); err != nil {
 				fmt.Fprintf(os.Stderr, "%s: %v\n", FAILURE, err)
@@ -142,6 +202,8 @@ func verifyNpmPackageCmd() *cobra.Command {
 				SourceURI:           o.SourceURI,
 				PrintProvenance:     o.PrintProvenance,
 				BuildWorkflowInputs: o.BuildWorkflowInputs.AsMap(),
+				VSASigningKey:       o.VSASigningKey,
+				Verifier:            o.NetworkOptions.Verifier(cmd),
 			}
 			if cmd.Flags().Changed("attestations-path") {
 				v.AttestationsPath = o.AttestationsPath
@@ -164,6 +226,15 @@ func verifyNpmPackageCmd() *cobra.Command {
 				fmt.Fprintf(os.Stderr, "%s: --source-versioned-tag not supported\n", FAILURE)
 				os.Exit(1)
 			}
+			if cmd.Flags().Changed("source-commit") {
+				v.SourceCommit = &o.SourceCommit
+			}
+			if cmd.Flags().Changed("source-checkout") {
+				v.SourceCheckout = &o.SourceCheckout
+			}
+			if cmd.Flags().Changed("expected-subject-name") {
+				v.SubjectName = &o.SubjectName
+			}
 			if cmd.Flags().Changed("print-provenance") {
 				fmt.Fprintf(os.Stderr, "%s: --print-provenance not supported\n", FAILURE)
 				os.Exit(1)
@@ -171,6 +242,9 @@ func verifyNpmPackageCmd() *cobra.Command {
 			if cmd.Flags().Changed("builder-id") {
 				v.BuilderID = &o.BuilderID
 			}
+			if cmd.Flags().Changed("emit-vsa") {
+				v.EmitVSA = &o.EmitVSA
+			}
 
 			if _, err := v.Exec(cmd.Context(), args); err != nil {
 				fmt.Fprintf(os.Stderr, "%s: %v\n", FAILURE, err)
@@ -184,3 +258,44 @@ func verifyNpmPackageCmd() *cobra.Command {
 	o.AddFlags(cmd)
 	return cmd
 }
+
+func verifyVSACmd() *cobra.Command {
+	o := &verify.VerifyVSAOptions{}
+
+	cmd := &cobra.Command{
+		Use: "verify-vsa [flags] artifact [artifact..]",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New("expects at least one artifact")
+			}
+			return nil
+		},
+		Short: "Verifies a Verification Summary Attestation (VSA) on artifact blobs given as arguments",
+		Run: func(cmd *cobra.Command, args []string) {
+			v := verify.VerifyVSACommand{
+				AttestationPath:  o.AttestationPath,
+				VerifierID:       o.VerifierID,
+				ResourceURI:      o.ResourceURI,
+				VerifiedLevels:   o.VerifiedLevels,
+				DigestAlgorithms: o.DigestAlgorithms,
+				SignerIdentity:   o.SignerIdentity,
+				SignerIssuer:     o.SignerIssuer,
+				PrintVSA:         o.PrintVSA,
+				Verifier:         o.NetworkOptions.Verifier(cmd),
+			}
+			if cmd.Flags().Changed("public-key") {
+				v.PublicKeyPath = &o.PublicKeyPath
+			}
+
+			if _, err := v.Exec(cmd.Context(), args); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", VSAFailure, err)
+				os.Exit(1)
+			} else {
+				fmt.Fprintf(os.Stderr, "%s\n", VSASuccess)
+			}
+		},
+	}
+
+	o.AddFlags(cmd)
+	return cmd
+}
@@ -16,9 +16,13 @@ package verify
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -34,25 +38,86 @@ type VerifyOptions struct {
 	SourceBranch     string
 	SourceTag        string
 	SourceVersionTag string
+	SourceCommit     string
+	SourceCheckout   string
 	/* Builder Requirements */
 	BuildWorkflowInputs workflowInputs
 	BuilderID           string
+	BuildTriggers       []string
+	RequireHostedRunner bool
+	PublicKeys          []string
+	SignatureThreshold  int
+	BuildSubstitutions  workflowInputs
+	GCBTrigger          string
+	/* Artifact requirements */
+	SubjectName string
 	/* Other */
 	ProvenancePath       string
 	ProvenanceRepository string
 	PrintProvenance      bool
+	EmitVSA              string
+	VSASigningKey        string
+	GCBKeySet            string
+	/* Transparency log */
+	CheckpointState  string
+	Witnesses        []string
+	WitnessThreshold int
+	/* Timestamps */
+	TimestampPolicy string
+	TSACertChains   []string
+	NetworkOptions
 }
 
 var _ Interface = (*VerifyOptions)(nil)
 
 // AddFlags implements Interface.
 func (o *VerifyOptions) AddFlags(cmd *cobra.Command) {
+	o.NetworkOptions.AddFlags(cmd)
+
 	/* Builder options */
 	cmd.Flags().Var(&o.BuildWorkflowInputs, "build-workflow-input",
 		"[optional] a workflow input provided by a user at trigger time in the format 'key=value'. (Only for 'workflow_dispatch' events on GitHub Actions).")
 
 	cmd.Flags().StringVar(&o.BuilderID, "builder-id", "", "[optional] the unique builder ID who created the provenance")
 
+	cmd.Flags().StringSliceVar(&o.BuildTriggers, "build-trigger", nil,
+		"[optional] a trigger event allowed to have started the build, e.g. push or release. Can be repeated. (Only for GitHub Actions).")
+
+	cmd.Flags().BoolVar(&o.RequireHostedRunner, "require-hosted-runner", false,
+		"[optional] require the build to have run on a GitHub-hosted runner. (Only for GitHub Actions).")
+
+	cmd.Flags().Var(&o.BuildSubstitutions, "build-substitution",
+		"[optional] a build substitution in the format 'key=value', e.g. _DEPLOY_ENV=prod. Can be repeated. (Only for Google Cloud Build).")
+
+	cmd.Flags().StringVar(&o.GCBTrigger, "gcb-trigger", "",
+		"[optional] expected name or ID of the trigger that started the build. (Only for Google Cloud Build).")
+
+	cmd.Flags().StringSliceVar(&o.PublicKeys, "public-key", nil,
+		"[optional] path to a PEM-encoded ECDSA, Ed25519 or RSA public key trusted to sign the provenance. Can be repeated. Requires --builder-id")
+
+	cmd.Flags().IntVar(&o.SignatureThreshold, "signature-threshold", 1,
+		"[optional] minimum number of distinct public keys that must have signed the provenance")
+
+	cmd.Flags().StringVar(&o.GCBKeySet, "gcb-key-set", "",
+		"[optional] path to a JSON file, or a directory of JSON files, of Google Cloud Build signing keys to use instead of the embedded keys")
+
+	/* Transparency log options */
+	cmd.Flags().StringVar(&o.CheckpointState, "tlog-checkpoint-state", "",
+		"[optional] path to a file persisting the latest verified transparency log checkpoints, which new checkpoints must be consistent with. (Only for GitHub Actions).")
+
+	cmd.Flags().StringSliceVar(&o.Witnesses, "tlog-witness", nil,
+		"[optional] note verifier key of a witness trusted to co-sign transparency log checkpoints, e.g. witness.example.com+ba3a6e4f+AQ.... Can be repeated. (Only for GitHub Actions).")
+
+	cmd.Flags().IntVar(&o.WitnessThreshold, "tlog-witness-threshold", 1,
+		"[optional] minimum number of witnesses that must have co-signed the transparency log checkpoint")
+
+	/* Timestamp options */
+	cmd.Flags().StringVar(&o.TimestampPolicy, "timestamp-policy", "tlog",
+		"[optional] signing time required in Sigstore bundles: a transparency log entry (tlog), an RFC 3161 timestamp (tsa) or both")
+
+	cmd.Flags().StringSliceVar(&o.TSACertChains, "tsa-cert-chain", nil,
+		"[optional] path to the PEM-encoded certificate chain of a timestamp authority trusted for RFC 3161 timestamps in Sigstore bundles. Can be repeated.")
+
 	/* Source options */
 	cmd.Flags().StringVar(&o.SourceURI, "source-uri", "",
 		"expected source repository that should have produced the binary, e.g. github.com/some/repo")
@@ -64,6 +129,16 @@ func (o *VerifyOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.SourceVersionTag, "source-versioned-tag", "",
 		"[optional] expected version the binary was compiled from. Uses semantic version to match the tag")
 
+	cmd.Flags().StringVar(&o.SourceCommit, "source-commit", "",
+		"[optional] expected git commit the binary was compiled from, in full or abbreviated to at least 12 characters")
+
+	cmd.Flags().StringVar(&o.SourceCheckout, "source-checkout", "",
+		"[optional] path to a local git checkout of the source repository to cross-check the commit, tag and branch against")
+
+	/* Artifact options */
+	cmd.Flags().StringVar(&o.SubjectName, "expected-subject-name", "",
+		"[optional] expected name, or glob pattern, of the provenance subject matching the artifact digest")
+
 	/* Other options */
 	cmd.Flags().StringVar(&o.ProvenancePath, "provenance-path", "",
 		"path to a provenance file")
@@ -74,8 +149,94 @@ func (o *VerifyOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&o.PrintProvenance, "print-provenance", false,
 		"[optional] print the verified provenance to stdout")
 
+	cmd.Flags().StringVar(&o.EmitVSA, "emit-vsa", "",
+		"[optional] path to write a signed Verification Summary Attestation (VSA) to after successful verification")
+
+	cmd.Flags().StringVar(&o.VSASigningKey, "vsa-signing-key", "",
+		"[optional] path to a PEM-encoded ECDSA or Ed25519 private key to sign the VSA with")
+
 	cmd.MarkFlagRequired("source-uri")
 	cmd.MarkFlagsMutuallyExclusive("source-versioned-tag", "source-tag")
+	cmd.MarkFlagsRequiredTogether("emit-vsa", "vsa-signing-key")
+}
+
+// VerifierOptions returns the options of the verifier that are not
+// network options.
+func (o *VerifyOptions) VerifierOptions() ([]verifiers.Option, error) {
+	var opts []verifiers.Option
+	if o.GCBKeySet != "" {
+		keySet, err := verifiers.GCBKeySetFromPath(o.GCBKeySet)
+		if err != nil {
+			return nil, fmt.Errorf("reading GCB key set: %w", err)
+		}
+		opts = append(opts, verifiers.WithGCBKeySet(keySet))
+	}
+	if o.CheckpointState != "" || len(o.Witnesses) > 0 {
+		checkpoints, err := verifiers.CheckpointConfigNew(o.CheckpointState, o.Witnesses, o.WitnessThreshold)
+		if err != nil {
+			return nil, fmt.Errorf("configuring checkpoint verification: %w", err)
+		}
+		opts = append(opts, verifiers.WithCheckpoints(checkpoints))
+	}
+	if o.TimestampPolicy != "" {
+		policy, err := verifiers.TimestampPolicyFromString(o.TimestampPolicy)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, verifiers.WithTimestampPolicy(policy))
+	}
+	for _, path := range o.TSACertChains {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading timestamp authority certificate chain: %w", err)
+		}
+		tsa, err := verifiers.TimestampAuthorityFromPEM(content)
+		if err != nil {
+			return nil, fmt.Errorf("reading timestamp authority certificate chain %s: %w", path, err)
+		}
+		opts = append(opts, verifiers.WithTimestampAuthorities(tsa))
+	}
+	return opts, nil
+}
+
+// VerifyArtifactOptions is the top-level options for the `verifyArtifact` command.
+type VerifyArtifactOptions struct {
+	VerifyOptions
+	/* Artifact requirements */
+	MatchArtifactName bool
+	DigestAlgorithms  []string
+	ChecksumsFile     string
+}
+
+var _ Interface = (*VerifyArtifactOptions)(nil)
+
+// AddFlags implements Interface.
+func (o *VerifyArtifactOptions) AddFlags(cmd *cobra.Command) {
+	o.VerifyOptions.AddFlags(cmd)
+
+	cmd.Flags().BoolVar(&o.MatchArtifactName, "match-artifact-name", false,
+		"[optional] require the provenance subject matching the artifact digest to be named like the artifact file")
+
+	cmd.Flags().StringSliceVar(&o.DigestAlgorithms, "digest-algorithm", nil,
+		fmt.Sprintf("[optional] a digest algorithm, among %v, that must match the provenance subject. Can be repeated. (default sha256)",
+			utils.DigestAlgorithms()))
+
+	cmd.Flags().StringVar(&o.ChecksumsFile, "checksums-file", "",
+		"[optional] path to a checksums file, e.g. SHA256SUMS, in sha256sum, sha512sum or BSD format, that is verified against the provenance instead of the artifacts. The artifacts are verified against its checksums.")
+
+	cmd.MarkFlagsMutuallyExclusive("expected-subject-name", "match-artifact-name")
+}
+
+// VerifyImageOptions is the top-level options for the `verifyImage` command.
+type VerifyImageOptions struct {
+	VerifyOptions
+}
+
+var _ Interface = (*VerifyImageOptions)(nil)
+
+// AddFlags implements Interface.
+func (o *VerifyImageOptions) AddFlags(cmd *cobra.Command) {
+	o.VerifyOptions.AddFlags(cmd)
 }
 
 // VerifyNpmOptions is the top-level options for the `verifyNpmPackage` command.
@@ -91,6 +252,8 @@ var _ Interface = (*VerifyNpmOptions)(nil)
 
 // AddFlags implements Interface.
 func (o *VerifyNpmOptions) AddFlags(cmd *cobra.Command) {
+	o.NetworkOptions.AddFlags(cmd)
+
 	/* Builder options */
 	cmd.Flags().Var(&o.BuildWorkflowInputs, "build-workflow-input",
 		"[optional] a workflow input provided by a user at trigger time in the format 'key=value'. (Only for 'workflow_dispatch' events on GitHub Actions).")
@@ -108,6 +271,15 @@ func (o *VerifyNpmOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.SourceVersionTag, "source-versioned-tag", "",
 		"[optional] expected version the binary was compiled from. Uses semantic version to match the tag")
 
+	cmd.Flags().StringVar(&o.SourceCommit, "source-commit", "",
+		"[optional] expected git commit the binary was compiled from, in full or abbreviated to at least 12 characters")
+
+	cmd.Flags().StringVar(&o.SourceCheckout, "source-checkout", "",
+		"[optional] path to a local git checkout of the source repository to cross-check the commit, tag and branch against")
+
+	cmd.Flags().StringVar(&o.SubjectName, "expected-subject-name", "",
+		"[optional] expected name, or glob pattern, of the provenance subject matching the artifact digest")
+
 	cmd.Flags().StringVar(&o.AttestationsPath, "attestations-path", "",
 		"path to a file containing the attestations")
 
@@ -120,11 +292,118 @@ func (o *VerifyNpmOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&o.PrintProvenance, "print-provenance", false,
 		"[optional] print the verified provenance to stdout")
 
+	cmd.Flags().StringVar(&o.EmitVSA, "emit-vsa", "",
+		"[optional] path to write a signed Verification Summary Attestation (VSA) to after successful verification")
+
+	cmd.Flags().StringVar(&o.VSASigningKey, "vsa-signing-key", "",
+		"[optional] path to a PEM-encoded ECDSA or Ed25519 private key to sign the VSA with")
+
 	cmd.MarkFlagRequired("source-uri")
 	cmd.MarkFlagRequired("builder-id")
 	cmd.MarkFlagRequired("package-name")
 	cmd.MarkFlagRequired("package-version")
 	cmd.MarkFlagsMutuallyExclusive("source-versioned-tag", "source-tag")
+	cmd.MarkFlagsRequiredTogether("emit-vsa", "vsa-signing-key")
+}
+
+// VerifyVSAOptions is the top-level options for the `verifyVSA` command.
+type VerifyVSAOptions struct {
+	AttestationPath  string
+	VerifierID       string
+	ResourceURI      string
+	VerifiedLevels   []string
+	DigestAlgorithms []string
+	PublicKeyPath    string
+	SignerIdentity   string
+	SignerIssuer     string
+	PrintVSA         bool
+	NetworkOptions
+}
+
+var _ Interface = (*VerifyVSAOptions)(nil)
+
+// AddFlags implements Interface.
+func (o *VerifyVSAOptions) AddFlags(cmd *cobra.Command) {
+	o.NetworkOptions.AddFlags(cmd)
+
+	cmd.Flags().StringVar(&o.AttestationPath, "attestation-path", "",
+		"path to a file containing the VSA")
+
+	cmd.Flags().StringVar(&o.VerifierID, "verifier-id", "",
+		"the unique ID of the verifier who issued the VSA")
+
+	cmd.Flags().StringVar(&o.ResourceURI, "resource-uri", "",
+		"the expected resource URI of the VSA, e.g. the artifact name or image reference")
+
+	cmd.Flags().StringSliceVar(&o.VerifiedLevels, "verified-level", nil,
+		"[optional] a minimum level, e.g. SLSA_BUILD_LEVEL_3, the VSA must have verified. Can be repeated.")
+
+	cmd.Flags().StringSliceVar(&o.DigestAlgorithms, "digest-algorithm", nil,
+		fmt.Sprintf("[optional] a digest algorithm, among %v, that must match the VSA subject. Can be repeated. (default sha256)",
+			utils.DigestAlgorithms()))
+
+	cmd.Flags().StringVar(&o.PublicKeyPath, "public-key", "",
+		"[optional] path to the PEM-encoded public key of the verifier. Required unless verifying a keyless signature")
+
+	cmd.Flags().StringVar(&o.SignerIdentity, "signer-identity", "",
+		"[optional] regular expression matching the keyless identity of the verifier, for a VSA in a Sigstore bundle")
+
+	cmd.Flags().StringVar(&o.SignerIssuer, "signer-issuer", "",
+		"[optional] OIDC issuer of the keyless identity of the verifier, for a VSA in a Sigstore bundle")
+
+	cmd.Flags().BoolVar(&o.PrintVSA, "print-vsa", false,
+		"[optional] print the verified VSA to stdout")
+
+	cmd.MarkFlagRequired("attestation-path")
+	cmd.MarkFlagRequired("verifier-id")
+	cmd.MarkFlagRequired("resource-uri")
+	cmd.MarkFlagsRequiredTogether("signer-identity", "signer-issuer")
+	cmd.MarkFlagsMutuallyExclusive("public-key", "signer-identity")
+	cmd.MarkFlagsOneRequired("public-key", "signer-identity")
+}
+
+// NetworkOptions are the timeout and retry options of network requests,
+// shared by all `verify` commands.
+type NetworkOptions struct {
+	Timeout        time.Duration
+	RequestTimeout time.Duration
+	MaxRetries     int
+	RetryWait      time.Duration
+}
+
+var _ Interface = (*NetworkOptions)(nil)
+
+// AddFlags implements Interface.
+func (o *NetworkOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", 0,
+		"[optional] timeout of the verification, e.g. 5m. Zero means no timeout")
+
+	cmd.Flags().DurationVar(&o.RequestTimeout, "request-timeout", 0,
+		"[optional] timeout of each request to Rekor and OCI registries, e.g. 30s. Zero means no timeout")
+
+	cmd.Flags().IntVar(&o.MaxRetries, "max-retries", verifiers.DefaultRetryPolicy.MaxRetries,
+		"[optional] number of retries of a failed request to Rekor or an OCI registry")
+
+	cmd.Flags().DurationVar(&o.RetryWait, "retry-wait", verifiers.DefaultRetryPolicy.MinWait,
+		fmt.Sprintf("[optional] wait before the first retry of a failed request, doubled for each further retry up to %v",
+			verifiers.DefaultRetryPolicy.MaxWait))
+}
+
+// Verifier returns a verifier configured with the options and opts. The
+// retry policy of OCI registries is only changed if a retry flag is set.
+func (o *NetworkOptions) Verifier(cmd *cobra.Command, opts ...verifiers.Option) *verifiers.Verifier {
+	opts = append(opts,
+		verifiers.WithTimeout(o.Timeout),
+		verifiers.WithRequestTimeout(o.RequestTimeout),
+	)
+	if cmd.Flags().Changed("max-retries") || cmd.Flags().Changed("retry-wait") {
+		opts = append(opts, verifiers.WithRetryPolicy(verifiers.RetryPolicy{
+			MaxRetries: o.MaxRetries,
+			MinWait:    o.RetryWait,
+			MaxWait:    max(o.RetryWait, verifiers.DefaultRetryPolicy.MaxWait),
+		}))
+	}
+	return verifiers.VerifierNew(opts...)
 }
 
 type workflowInputs struct {
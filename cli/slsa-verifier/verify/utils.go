@@ -19,6 +19,9 @@ import (
 	"hash"
 	"io"
 	"os"
+
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 )
 
 func computeFileHash(filePath string, h hash.Hash) (string, error) {
@@ -33,3 +36,35 @@ func computeFileHash(filePath string, h hash.Hash) (string, error) {
 	}
 	return hex.EncodeToString(h.Sum(nil)), nil
 }
+
+// computeFileDigests hashes a file with each of the given algorithms
+// in a single pass.
+func computeFileDigests(filePath string, algos []string) (map[string]string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return utils.ComputeDigests(f, algos)
+}
+
+// readPublicKeys reads the PEM-encoded public keys at the given paths.
+func readPublicKeys(paths []string) ([][]byte, error) {
+	keys := make([][]byte, 0, len(paths))
+	for _, path := range paths {
+		key, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func verifierOrDefault(v *verifiers.Verifier) *verifiers.Verifier {
+	if v == nil {
+		return verifiers.VerifierNew()
+	}
+	return v
+}
@@ -16,13 +16,18 @@ package verify
 
 import (
 	"context"
-	"crypto/sha256"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
 
 	"github.com/slsa-framework/slsa-verifier/v2/options"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/vsa"
 )
 
 // Note: nil branch, tag, version-tag and builder-id means we ignore them during verification.
@@ -33,31 +38,169 @@ type VerifyArtifactCommand struct {
 	SourceBranch        *string
 	SourceTag           *string
 	SourceVersionTag    *string
+	SourceCommit        *string
+	SourceCheckout      *string
 	BuildWorkflowInputs map[string]string
+	BuildTriggers       []string
+	BuildSubstitutions  map[string]string
+	GCBTrigger          *string
+	RequireHostedRunner bool
+	PublicKeys          []string
+	SignatureThreshold  int
+	SubjectName         *string
+	MatchArtifactName   bool
+	DigestAlgorithms    []string
 	PrintProvenance     bool
+	EmitVSA             *string
+	VSASigningKey       string
+	// ChecksumsFile is the path of a checksums file, e.g. SHA256SUMS, that
+	// is verified against the provenance instead of the artifacts. The
+	// artifacts are then verified against its checksums.
+	ChecksumsFile string
+	// Verifier is the verifier to use. If nil, the default verifier is used.
+	Verifier *verifiers.Verifier
 }
 
 func (c *VerifyArtifactCommand) Exec(ctx context.Context, artifacts []string) (*utils.TrustedBuilderID, error) {
+	var vsaSigner *dsselib.EnvelopeSigner
+	if c.EmitVSA != nil {
+		signer, err := loadVSASigner(c.VSASigningKey)
+		if err != nil {
+			return nil, err
+		}
+		vsaSigner = signer
+	}
+
+	var verified []verifiedArtifact
+	var err error
+	if c.ChecksumsFile == "" {
+		verified, err = c.verifyArtifacts(ctx, artifacts)
+	} else {
+		verified, err = c.verifyChecksumsFile(ctx, artifacts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if vsaSigner != nil {
+		vsas := make([]*vsa.Statement, 0, len(verified))
+		for _, artifact := range verified {
+			name := filepath.Base(artifact.path)
+			statement, err := newVSA(name, intoto.Subject{Name: name, Digest: artifact.digests},
+				c.ProvenancePath, artifact.provenance, artifact.builderID,
+				artifact.provenanceOpts, artifact.builderOpts)
+			if err != nil {
+				return nil, err
+			}
+			vsas = append(vsas, statement)
+		}
+		if err := writeVSAs(ctx, *c.EmitVSA, vsaSigner, vsas); err != nil {
+			return nil, err
+		}
+	}
+
 	var builderID *utils.TrustedBuilderID
+	if len(verified) > 0 {
+		builderID = verified[0].builderID
+	}
+	return builderID, nil
+}
 
+// verifiedArtifact is an artifact whose provenance is verified, with the
+// options it was verified with.
+type verifiedArtifact struct {
+	path string
+	// digests are the digests of the artifact, the subject of its VSA.
+	digests        map[string]string
+	provenance     []byte
+	builderID      *utils.TrustedBuilderID
+	provenanceOpts *options.ProvenanceOpts
+	builderOpts    *options.BuilderOpts
+}
+
+// verifyChecksumsFile verifies the provenance of the checksums file, then
+// the artifacts against its checksums. The artifacts are verified with the
+// provenance of the checksums file, and their digests are those it lists.
+func (c *VerifyArtifactCommand) verifyChecksumsFile(ctx context.Context, artifacts []string) ([]verifiedArtifact, error) {
+	verified, err := c.verifyArtifacts(ctx, []string{c.ChecksumsFile})
+	if err != nil {
+		return nil, err
+	}
+	checksums, err := verifyChecksums(c.ChecksumsFile, artifacts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]verifiedArtifact, 0, len(artifacts))
 	for _, artifact := range artifacts {
-		artifactHash, err := computeFileHash(artifact, sha256.New())
+		result := verified[0]
+		result.path = artifact
+		result.digests = checksums[artifact]
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// verifyArtifacts verifies the provenance of the artifacts.
+func (c *VerifyArtifactCommand) verifyArtifacts(ctx context.Context, artifacts []string) ([]verifiedArtifact, error) {
+	var builderID *utils.TrustedBuilderID
+	var verified []verifiedArtifact
+
+	publicKeys, err := readPublicKeys(c.PublicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, artifact := range artifacts {
+		// The sha256 digest is always computed because it is used
+		// to look up the provenance in the transparency log.
+		digests, err := computeFileDigests(artifact, append([]string{"sha256"}, c.DigestAlgorithms...))
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Verifying artifact %s: FAILED: %v\n\n", artifact, err)
 			return nil, err
 		}
+		artifactHash := digests["sha256"]
+
+		// Only the requested digests must match the provenance subject.
+		var expectedDigests map[string]string
+		for _, name := range c.DigestAlgorithms {
+			algo, err := utils.NormalizeDigestAlgorithm(name)
+			if err != nil {
+				return nil, err
+			}
+			if expectedDigests == nil {
+				expectedDigests = make(map[string]string)
+			}
+			expectedDigests[algo] = digests[algo]
+		}
+
+		subjectName := c.SubjectName
+		if c.MatchArtifactName {
+			name := filepath.Base(artifact)
+			subjectName = &name
+		}
 
 		provenanceOpts := &options.ProvenanceOpts{
-			ExpectedSourceURI:      c.SourceURI,
-			ExpectedBranch:         c.SourceBranch,
-			ExpectedDigest:         artifactHash,
-			ExpectedVersionedTag:   c.SourceVersionTag,
-			ExpectedTag:            c.SourceTag,
-			ExpectedWorkflowInputs: c.BuildWorkflowInputs,
+			ExpectedSourceURI:          c.SourceURI,
+			ExpectedBranch:             c.SourceBranch,
+			ExpectedDigest:             artifactHash,
+			ExpectedDigests:            expectedDigests,
+			ExpectedSubjectName:        subjectName,
+			ExpectedVersionedTag:       c.SourceVersionTag,
+			ExpectedTag:                c.SourceTag,
+			ExpectedSourceCommit:       c.SourceCommit,
+			SourceCheckout:             c.SourceCheckout,
+			ExpectedWorkflowInputs:     c.BuildWorkflowInputs,
+			ExpectedBuildTriggers:      c.BuildTriggers,
+			ExpectedBuildSubstitutions: c.BuildSubstitutions,
+			ExpectedGCBTrigger:         c.GCBTrigger,
+			RequireHostedRunner:        c.RequireHostedRunner,
 		}
 
 		builderOpts := &options.BuilderOpts{
-			ExpectedID: c.BuilderID,
+			ExpectedID:         c.BuilderID,
+			PublicKeys:         publicKeys,
+			SignatureThreshold: c.SignatureThreshold,
 		}
 
 		provenance, err := os.ReadFile(c.ProvenancePath)
@@ -66,11 +209,12 @@ func (c *VerifyArtifactCommand) Exec(ctx context.Context, artifacts []string) (*
 			return nil, err
 		}
 
-		verifiedProvenance, outBuilderID, err := verifiers.VerifyArtifact(ctx, provenance, artifactHash, provenanceOpts, builderOpts)
+		result, err := verifierOrDefault(c.Verifier).VerifyArtifactResult(ctx, provenance, artifactHash, provenanceOpts, builderOpts)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Verifying artifact %s: FAILED: %v\n\n", artifact, err)
 			return nil, err
 		}
+		verifiedProvenance, outBuilderID := result.Statement, result.BuilderID
 
 		if c.PrintProvenance {
 			fmt.Fprintf(os.Stdout, "%s\n", string(verifiedProvenance))
@@ -83,8 +227,71 @@ func (c *VerifyArtifactCommand) Exec(ctx context.Context, artifacts []string) (*
 			fmt.Fprintf(os.Stderr, "Verifying artifact %s: FAILED: %v\n\n", artifact, err)
 			return nil, err
 		}
+		verified = append(verified, verifiedArtifact{
+			path:           artifact,
+			digests:        digests,
+			provenance:     verifiedProvenance,
+			builderID:      outBuilderID,
+			provenanceOpts: provenanceOpts,
+			builderOpts:    builderOpts,
+		})
+		if result.ProvenanceLine > 0 {
+			fmt.Fprintf(os.Stderr, "Verified provenance on line %d of %s\n", result.ProvenanceLine, c.ProvenancePath)
+		}
 		fmt.Fprintf(os.Stderr, "Verifying artifact %s: PASSED\n\n", artifact)
 	}
 
-	return builderID, nil
+	return verified, nil
+}
+
+// verifyChecksums verifies the artifacts against a checksums file, whose
+// provenance is already verified. Each artifact is verified and reported.
+// It returns the digests listed for each artifact, by artifact path.
+func verifyChecksums(checksumsPath string, artifacts []string) (map[string]map[string]string, error) {
+	content, err := os.ReadFile(checksumsPath)
+	if err != nil {
+		return nil, err
+	}
+	checksums, err := utils.ParseChecksums(content)
+	if err != nil {
+		return nil, err
+	}
+
+	verified := make(map[string]map[string]string, len(artifacts))
+	var errs []error
+	for _, artifact := range artifacts {
+		expected, err := verifyChecksum(checksums, artifact)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Verifying artifact %s: FAILED: %v\n\n", artifact, err)
+			errs = append(errs, fmt.Errorf("%s: %w", artifact, err))
+			continue
+		}
+		verified[artifact] = expected
+		fmt.Fprintf(os.Stderr, "Verifying artifact %s against %s: PASSED\n\n", artifact, checksumsPath)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return verified, nil
+}
+
+// verifyChecksum verifies an artifact against its checksums, and returns
+// them.
+func verifyChecksum(checksums utils.Checksums, artifact string) (map[string]string, error) {
+	expected, err := checksums.Digests(artifact)
+	if err != nil {
+		return nil, err
+	}
+	algos := make([]string, 0, len(expected))
+	for algo := range expected {
+		algos = append(algos, algo)
+	}
+	digests, err := computeFileDigests(artifact, algos)
+	if err != nil {
+		return nil, err
+	}
+	if err := checksums.Verify(artifact, digests); err != nil {
+		return nil, err
+	}
+	return expected, nil
 }
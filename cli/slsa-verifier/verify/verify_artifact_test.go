@@ -0,0 +1,302 @@
+// Copyright 2022 SLSA Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/vsa"
+)
+
+const (
+	testBuilderID = "https://ci.example.com/builder"
+	testSourceURI = "github.com/org/app"
+)
+
+// writeTestFile writes content to name in dir and returns its path.
+func writeTestFile(t *testing.T, dir, name string, content []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func sha256Hex(content []byte) string {
+	digest := sha256.Sum256(content)
+	return hex.EncodeToString(digest[:])
+}
+
+// testProvenance returns SLSA v1.0 provenance for a subject built by
+// builderID, signed with the PEM-encoded private key.
+func testProvenance(t *testing.T, privateKey []byte, builderID, name, digest string) []byte {
+	t.Helper()
+	statement := fmt.Sprintf(`{
+  "_type": "https://in-toto.io/Statement/v1",
+  "predicateType": "https://slsa.dev/provenance/v1",
+  "subject": [{"name": %q, "digest": {"sha256": %q}}],
+  "predicate": {
+    "buildDefinition": {
+      "buildType": "https://ci.example.com/build/v1",
+      "externalParameters": {},
+      "resolvedDependencies": [{
+        "uri": "git+https://github.com/org/app@refs/heads/main",
+        "digest": {"gitCommit": "6f3a9c3d4b2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a"}
+      }]
+    },
+    "runDetails": {"builder": {"id": %q}}
+  }
+}`, name, digest, builderID)
+
+	signer, err := utils.DsseSignerNew(privateKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := signer.SignPayload(context.Background(), intoto.PayloadType, []byte(statement))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := json.Marshal(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+// captureStdout returns what f writes to os.Stdout.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan []byte)
+	go func() {
+		content, _ := io.ReadAll(r)
+		out <- content
+	}()
+	f()
+	w.Close()
+	return string(<-out)
+}
+
+// readVSAs returns the VSAs in a file of DSSE envelopes, one per line.
+func readVSAs(t *testing.T, path string) []vsa.Statement {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var statements []vsa.Statement
+	for _, line := range bytes.Split(bytes.TrimSpace(content), []byte("\n")) {
+		var env dsselib.Envelope
+		if err := json.Unmarshal(line, &env); err != nil {
+			t.Fatal(err)
+		}
+		payload, err := base64.StdEncoding.DecodeString(env.Payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var statement vsa.Statement
+		if err := json.Unmarshal(payload, &statement); err != nil {
+			t.Fatal(err)
+		}
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+// testKeys returns a PEM-encoded private key and the path of its PEM-encoded
+// public key, written to dir.
+func testKeys(t *testing.T, dir string) ([]byte, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	der, err = x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyPath := writeTestFile(t, dir, "key.pub",
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	return privateKey, publicKeyPath
+}
+
+// Test_VerifyArtifactCommand_ChecksumsFile is not parallel: it captures
+// os.Stdout.
+func Test_VerifyArtifactCommand_ChecksumsFile(t *testing.T) {
+	dir := t.TempDir()
+
+	privateKey, publicKeyPath := testKeys(t, dir)
+	vsaKeyPath := writeTestFile(t, dir, "vsa.pem", privateKey)
+
+	app := []byte("app")
+	appPath := writeTestFile(t, dir, "app", app)
+	tamperedPath := writeTestFile(t, dir, "lib", []byte("tampered"))
+	checksums := []byte(fmt.Sprintf("%s  app\n%s  lib\n", sha256Hex(app), sha256Hex([]byte("lib"))))
+	checksumsPath := writeTestFile(t, dir, "SHA256SUMS", checksums)
+	provenancePath := writeTestFile(t, dir, "SHA256SUMS.intoto.jsonl",
+		testProvenance(t, privateKey, testBuilderID, "SHA256SUMS", sha256Hex(checksums)))
+
+	tests := []struct {
+		name      string
+		artifacts []string
+		expected  error
+	}{
+		{
+			name:      "matching artifact",
+			artifacts: []string{appPath},
+		},
+		{
+			name:      "mismatching artifact",
+			artifacts: []string{appPath, tamperedPath},
+			expected:  serrors.ErrorMismatchChecksum,
+		},
+		{
+			name:      "artifact not listed",
+			artifacts: []string{publicKeyPath},
+			expected:  serrors.ErrorMismatchChecksum,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vsaPath := filepath.Join(dir, fmt.Sprintf("vsa-%d.intoto.jsonl", i))
+			builderID := testBuilderID
+			cmd := &VerifyArtifactCommand{
+				ProvenancePath:  provenancePath,
+				BuilderID:       &builderID,
+				SourceURI:       testSourceURI,
+				PublicKeys:      []string{publicKeyPath},
+				PrintProvenance: true,
+				EmitVSA:         &vsaPath,
+				VSASigningKey:   vsaKeyPath,
+				ChecksumsFile:   checksumsPath,
+			}
+
+			var err error
+			stdout := captureStdout(t, func() {
+				_, err = cmd.Exec(context.Background(), tt.artifacts)
+			})
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("unexpected error: got %v, want %v", err, tt.expected)
+			}
+
+			// The printed provenance is that of the checksums file only,
+			// whether or not the artifacts match.
+			var statements []intoto.StatementHeader
+			decoder := json.NewDecoder(strings.NewReader(stdout))
+			for decoder.More() {
+				var statement intoto.StatementHeader
+				if err := decoder.Decode(&statement); err != nil {
+					t.Fatalf("unexpected printed provenance: %v", err)
+				}
+				statements = append(statements, statement)
+			}
+			if len(statements) != 1 || len(statements[0].Subject) != 1 ||
+				statements[0].Subject[0].Name != "SHA256SUMS" {
+				t.Errorf("unexpected printed provenance %v", statements)
+			}
+
+			// A VSA is emitted for each artifact, with the digests listed
+			// in the checksums file, only if all of them match.
+			if tt.expected != nil {
+				if _, err := os.Stat(vsaPath); !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("unexpected VSA file: %v", err)
+				}
+				return
+			}
+			vsas := readVSAs(t, vsaPath)
+			if len(vsas) != 1 || len(vsas[0].Subject) != 1 ||
+				vsas[0].Subject[0].Name != "app" ||
+				len(vsas[0].Subject[0].Digest) != 1 ||
+				vsas[0].Subject[0].Digest["sha256"] != sha256Hex(app) {
+				t.Errorf("unexpected VSAs %v", vsas)
+			}
+		})
+	}
+}
+
+// Test_VerifyArtifactCommand_PublicKeyLevels is not parallel: it captures
+// os.Stdout.
+func Test_VerifyArtifactCommand_PublicKeyLevels(t *testing.T) {
+	dir := t.TempDir()
+	privateKey, publicKeyPath := testKeys(t, dir)
+	vsaKeyPath := writeTestFile(t, dir, "vsa.pem", privateKey)
+
+	// Provenance signed with the caller's key naming a builder trusted
+	// for level 3 only meets level 1.
+	builderID := "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml@refs/tags/v1.9.0"
+	app := []byte("app")
+	appPath := writeTestFile(t, dir, "app", app)
+	provenancePath := writeTestFile(t, dir, "app.intoto.jsonl",
+		testProvenance(t, privateKey, builderID, "app", sha256Hex(app)))
+	vsaPath := filepath.Join(dir, "vsa.intoto.jsonl")
+	cmd := &VerifyArtifactCommand{
+		ProvenancePath: provenancePath,
+		BuilderID:      &builderID,
+		SourceURI:      testSourceURI,
+		PublicKeys:     []string{publicKeyPath},
+		EmitVSA:        &vsaPath,
+		VSASigningKey:  vsaKeyPath,
+	}
+
+	var err error
+	captureStdout(t, func() {
+		_, err = cmd.Exec(context.Background(), []string{appPath})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	statements := readVSAs(t, vsaPath)
+	if len(statements) != 1 {
+		t.Fatalf("unexpected VSAs %v", statements)
+	}
+	if levels := statements[0].Predicate.VerifiedLevels; len(levels) != 1 || levels[0] != "SLSA_BUILD_LEVEL_1" {
+		t.Errorf("unexpected levels %v", levels)
+	}
+}
@@ -18,11 +18,16 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
 
 	"github.com/slsa-framework/slsa-verifier/v2/options"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils/container"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/vsa"
 )
 
 type ComputeDigestFn func(string) (string, error)
@@ -37,8 +42,21 @@ type VerifyImageCommand struct {
 	SourceBranch         *string
 	SourceTag            *string
 	SourceVersionTag     *string
+	SourceCommit         *string
+	SourceCheckout       *string
 	BuildWorkflowInputs  map[string]string
+	BuildTriggers        []string
+	BuildSubstitutions   map[string]string
+	GCBTrigger           *string
+	RequireHostedRunner  bool
+	PublicKeys           []string
+	SignatureThreshold   int
+	SubjectName          *string
 	PrintProvenance      bool
+	EmitVSA              *string
+	VSASigningKey        string
+	// Verifier is the verifier to use. If nil, the default verifier is used.
+	Verifier *verifiers.Verifier
 }
 
 func (c *VerifyImageCommand) Exec(ctx context.Context, artifacts []string) (*utils.TrustedBuilderID, error) {
@@ -54,14 +72,36 @@ func (c *VerifyImageCommand) Exec(ctx context.Context, artifacts []string) (*uti
 		ExpectedSourceURI:            c.SourceURI,
 		ExpectedBranch:               c.SourceBranch,
 		ExpectedDigest:               digest,
+		ExpectedSubjectName:          c.SubjectName,
 		ExpectedVersionedTag:         c.SourceVersionTag,
 		ExpectedTag:                  c.SourceTag,
+		ExpectedSourceCommit:         c.SourceCommit,
+		SourceCheckout:               c.SourceCheckout,
 		ExpectedProvenanceRepository: c.ProvenanceRepository,
 		ExpectedWorkflowInputs:       c.BuildWorkflowInputs,
+		ExpectedBuildTriggers:        c.BuildTriggers,
+		ExpectedBuildSubstitutions:   c.BuildSubstitutions,
+		ExpectedGCBTrigger:           c.GCBTrigger,
+		RequireHostedRunner:          c.RequireHostedRunner,
+	}
+
+	publicKeys, err := readPublicKeys(c.PublicKeys)
+	if err != nil {
+		return nil, err
 	}
 
 	builderOpts := &options.BuilderOpts{
-		ExpectedID: c.BuilderID,
+		ExpectedID:         c.BuilderID,
+		PublicKeys:         publicKeys,
+		SignatureThreshold: c.SignatureThreshold,
+	}
+
+	var vsaSigner *dsselib.EnvelopeSigner
+	if c.EmitVSA != nil {
+		vsaSigner, err = loadVSASigner(c.VSASigningKey)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	var provenance []byte
@@ -72,7 +112,7 @@ func (c *VerifyImageCommand) Exec(ctx context.Context, artifacts []string) (*uti
 		}
 	}
 
-	verifiedProvenance, outBuilderID, err := verifiers.VerifyImage(ctx, artifacts[0], provenance, provenanceOpts, builderOpts)
+	verifiedProvenance, outBuilderID, err := verifierOrDefault(c.Verifier).VerifyImage(ctx, artifacts[0], provenance, provenanceOpts, builderOpts)
 
 	if err != nil {
 		return nil, err
@@ -82,5 +122,25 @@ func (c *VerifyImageCommand) Exec(ctx context.Context, artifacts []string) (*uti
 		fmt.Fprintf(os.Stdout, "%s\n", string(verifiedProvenance))
 	}
 
+	if vsaSigner != nil {
+		// Provenance fetched from the registry is attached to the image.
+		provenanceURI := artifactImage
+		if c.ProvenancePath != nil {
+			provenanceURI = *c.ProvenancePath
+		}
+		subject := intoto.Subject{
+			Name:   strings.SplitN(artifactImage, "@", 2)[0],
+			Digest: map[string]string{"sha256": digest},
+		}
+		statement, err := newVSA(artifactImage, subject, provenanceURI, verifiedProvenance,
+			outBuilderID, provenanceOpts, builderOpts)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeVSAs(ctx, *c.EmitVSA, vsaSigner, []*vsa.Statement{statement}); err != nil {
+			return nil, err
+		}
+	}
+
 	return outBuilderID, nil
 }
@@ -20,10 +20,15 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
 
 	"github.com/slsa-framework/slsa-verifier/v2/options"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/vsa"
 )
 
 type VerifyNpmPackageCommand struct {
@@ -33,10 +38,17 @@ type VerifyNpmPackageCommand struct {
 	SourceBranch        *string
 	SourceTag           *string
 	SourceVersionTag    *string
+	SourceCommit        *string
+	SourceCheckout      *string
 	PackageName         *string
 	PackageVersion      *string
 	BuildWorkflowInputs map[string]string
+	SubjectName         *string
 	PrintProvenance     bool
+	EmitVSA             *string
+	VSASigningKey       string
+	// Verifier is the verifier to use. If nil, the default verifier is used.
+	Verifier *verifiers.Verifier
 }
 
 func (c *VerifyNpmPackageCommand) Exec(ctx context.Context, tarballs []string) (*utils.TrustedBuilderID, error) {
@@ -46,6 +58,17 @@ func (c *VerifyNpmPackageCommand) Exec(ctx context.Context, tarballs []string) (
 		fmt.Fprintf(os.Stderr, "Verifying npm package: FAILED: %v\n\n", err)
 		return nil, err
 	}
+
+	var vsaSigner *dsselib.EnvelopeSigner
+	var vsas []*vsa.Statement
+	if c.EmitVSA != nil {
+		signer, err := loadVSASigner(c.VSASigningKey)
+		if err != nil {
+			return nil, err
+		}
+		vsaSigner = signer
+	}
+
 	for _, tarball := range tarballs {
 		tarballHash, err := computeFileHash(tarball, sha512.New())
 		if err != nil {
@@ -61,8 +84,11 @@ func (c *VerifyNpmPackageCommand) Exec(ctx context.Context, tarballs []string) (
 			ExpectedSourceURI:      c.SourceURI,
 			ExpectedBranch:         c.SourceBranch,
 			ExpectedDigest:         tarballHash,
+			ExpectedSubjectName:    c.SubjectName,
 			ExpectedVersionedTag:   c.SourceVersionTag,
 			ExpectedTag:            c.SourceTag,
+			ExpectedSourceCommit:   c.SourceCommit,
+			SourceCheckout:         c.SourceCheckout,
 			ExpectedWorkflowInputs: c.BuildWorkflowInputs,
 			ExpectedPackageName:    c.PackageName,
 			ExpectedPackageVersion: c.PackageVersion,
@@ -78,7 +104,7 @@ func (c *VerifyNpmPackageCommand) Exec(ctx context.Context, tarballs []string) (
 			return nil, err
 		}
 
-		verifiedProvenance, outBuilderID, err := verifiers.VerifyNpmPackage(ctx, attestations, tarballHash, provenanceOpts, builderOpts)
+		verifiedProvenance, outBuilderID, err := verifierOrDefault(c.Verifier).VerifyNpmPackage(ctx, attestations, tarballHash, provenanceOpts, builderOpts)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Verifying npm package %s: FAILED: %v\n\n", tarball, err)
 			return nil, err
@@ -89,8 +115,37 @@ func (c *VerifyNpmPackageCommand) Exec(ctx context.Context, tarballs []string) (
 		}
 
 		builderID = outBuilderID
+		if vsaSigner != nil {
+			var name, version string
+			if c.PackageName != nil {
+				name = *c.PackageName
+			}
+			if c.PackageVersion != nil {
+				version = *c.PackageVersion
+			}
+			purl := npmPackageURL(name, version)
+			statement, err := newVSA(purl,
+				intoto.Subject{Name: purl, Digest: map[string]string{"sha512": tarballHash}},
+				c.AttestationsPath, verifiedProvenance, outBuilderID, provenanceOpts, builderOpts)
+			if err != nil {
+				return nil, err
+			}
+			vsas = append(vsas, statement)
+		}
 		fmt.Fprintf(os.Stderr, "Verifying npm package %s: PASSED\n\n", tarball)
 	}
 
+	if vsaSigner != nil {
+		if err := writeVSAs(ctx, *c.EmitVSA, vsaSigner, vsas); err != nil {
+			return nil, err
+		}
+	}
+
 	return builderID, nil
 }
+
+// npmPackageURL returns the package URL of an npm package version,
+// e.g. pkg:npm/%40scope/name@1.0.0.
+func npmPackageURL(name, version string) string {
+	return fmt.Sprintf("pkg:npm/%s@%s", strings.Replace(name, "@", "%40", 1), version)
+}
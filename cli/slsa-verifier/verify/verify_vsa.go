@@ -0,0 +1,94 @@
+// Copyright 2023 SLSA Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+// Note: nil public key means the VSA is verified against the keyless signer identity.
+type VerifyVSACommand struct {
+	AttestationPath  string
+	VerifierID       string
+	ResourceURI      string
+	VerifiedLevels   []string
+	DigestAlgorithms []string
+	PublicKeyPath    *string
+	SignerIdentity   string
+	SignerIssuer     string
+	PrintVSA         bool
+	// Verifier is the verifier to use. If nil, the default verifier is used.
+	Verifier *verifiers.Verifier
+}
+
+func (c *VerifyVSACommand) Exec(ctx context.Context, artifacts []string) (*utils.TrustedBuilderID, error) {
+	var verifierID *utils.TrustedBuilderID
+
+	algos := c.DigestAlgorithms
+	if len(algos) == 0 {
+		algos = []string{"sha256"}
+	}
+
+	vsaOpts := &options.VSAOpts{
+		ExpectedVerifierID:     c.VerifierID,
+		ExpectedResourceURI:    c.ResourceURI,
+		ExpectedLevels:         c.VerifiedLevels,
+		ExpectedSignerIdentity: c.SignerIdentity,
+		ExpectedSignerIssuer:   c.SignerIssuer,
+	}
+	if c.PublicKeyPath != nil {
+		key, err := os.ReadFile(*c.PublicKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		vsaOpts.PublicKey = key
+	}
+
+	attestation, err := os.ReadFile(c.AttestationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, artifact := range artifacts {
+		digests, err := computeFileDigests(artifact, algos)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Verifying artifact %s: FAILED: %v\n\n", artifact, err)
+			return nil, err
+		}
+		vsaOpts.ExpectedDigests = digests
+
+		verifiedVSA, outVerifierID, err := verifierOrDefault(c.Verifier).VerifyVSA(ctx, attestation, vsaOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Verifying artifact %s: FAILED: %v\n\n", artifact, err)
+			return nil, err
+		}
+
+		if c.PrintVSA {
+			fmt.Fprintf(os.Stdout, "%s\n", string(verifiedVSA))
+		}
+
+		verifierID = outVerifierID
+		fmt.Fprintf(os.Stderr, "Verified VSA issued by verifier %q\n", verifierID.String())
+		fmt.Fprintf(os.Stderr, "Verifying artifact %s: PASSED\n\n", artifact)
+	}
+
+	return verifierID, nil
+}
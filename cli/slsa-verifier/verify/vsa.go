@@ -0,0 +1,151 @@
+// Copyright 2023 SLSA Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"sigs.k8s.io/release-utils/version"
+
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/vsa"
+)
+
+// vsaPolicyURI documents the expectations recorded in the VSA policy.
+const vsaPolicyURI = vsa.VerifierID + "#option-details"
+
+// loadVSASigner reads the PEM-encoded private key used to sign VSAs.
+// It is loaded before verification so that a bad key fails early.
+func loadVSASigner(keyPath string) (*dsselib.EnvelopeSigner, error) {
+	if keyPath == "" {
+		return nil, errors.New("--vsa-signing-key is required to emit a VSA")
+	}
+	content, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	return utils.DsseSignerNew(content, "")
+}
+
+// vsaPolicy records the user's expectations as the VSA policy.
+func vsaPolicy(provenanceOpts *options.ProvenanceOpts, builderOpts *options.BuilderOpts) (vsa.ResourceDescriptor, error) {
+	expectations := map[string]string{
+		"sourceUri": provenanceOpts.ExpectedSourceURI,
+	}
+	optional := map[string]*string{
+		"builderId":            builderOpts.ExpectedID,
+		"sourceBranch":         provenanceOpts.ExpectedBranch,
+		"sourceTag":            provenanceOpts.ExpectedTag,
+		"sourceVersionedTag":   provenanceOpts.ExpectedVersionedTag,
+		"sourceCommit":         provenanceOpts.ExpectedSourceCommit,
+		"subjectName":          provenanceOpts.ExpectedSubjectName,
+		"packageName":          provenanceOpts.ExpectedPackageName,
+		"packageVersion":       provenanceOpts.ExpectedPackageVersion,
+		"provenanceRepository": provenanceOpts.ExpectedProvenanceRepository,
+	}
+	for k, v := range optional {
+		if v != nil {
+			expectations[k] = *v
+		}
+	}
+	if provenanceOpts.SourceCheckout != nil {
+		expectations["sourceCheckout"] = "true"
+	}
+	for k, v := range provenanceOpts.ExpectedWorkflowInputs {
+		expectations["buildWorkflowInput."+k] = v
+	}
+	for k, v := range provenanceOpts.ExpectedBuildSubstitutions {
+		expectations["buildSubstitution."+k] = v
+	}
+	if provenanceOpts.ExpectedGCBTrigger != nil {
+		expectations["gcbTrigger"] = *provenanceOpts.ExpectedGCBTrigger
+	}
+	if len(provenanceOpts.ExpectedBuildTriggers) > 0 {
+		triggers := append([]string{}, provenanceOpts.ExpectedBuildTriggers...)
+		sort.Strings(triggers)
+		expectations["buildTriggers"] = strings.Join(triggers, ",")
+	}
+	if provenanceOpts.RequireHostedRunner {
+		expectations["requireHostedRunner"] = "true"
+	}
+	if len(provenanceOpts.ExpectedDigests) > 0 {
+		algos := make([]string, 0, len(provenanceOpts.ExpectedDigests))
+		for algo := range provenanceOpts.ExpectedDigests {
+			algos = append(algos, algo)
+		}
+		sort.Strings(algos)
+		expectations["digestAlgorithms"] = strings.Join(algos, ",")
+	}
+	if len(builderOpts.PublicKeys) > 0 {
+		keyIDs := make([]string, 0, len(builderOpts.PublicKeys))
+		for _, key := range builderOpts.PublicKeys {
+			keyID, err := utils.PublicKeyID(key)
+			if err != nil {
+				return vsa.ResourceDescriptor{}, err
+			}
+			keyIDs = append(keyIDs, keyID)
+		}
+		sort.Strings(keyIDs)
+		expectations["publicKeys"] = strings.Join(keyIDs, ",")
+		expectations["signatureThreshold"] = strconv.Itoa(max(builderOpts.SignatureThreshold, 1))
+	}
+	return vsa.Policy(vsaPolicyURI, expectations)
+}
+
+// newVSA creates the VSA of a successfully verified subject.
+func newVSA(resourceURI string, subject intoto.Subject, provenanceURI string, verifiedProvenance []byte,
+	builderID *utils.TrustedBuilderID, provenanceOpts *options.ProvenanceOpts, builderOpts *options.BuilderOpts,
+) (*vsa.Statement, error) {
+	policy, err := vsaPolicy(provenanceOpts, builderOpts)
+	if err != nil {
+		return nil, err
+	}
+	return vsa.New(
+		[]intoto.Subject{subject},
+		resourceURI,
+		version.GetVersionInfo().GitVersion,
+		policy,
+		[]vsa.ResourceDescriptor{vsa.InputAttestation(provenanceURI, verifiedProvenance)},
+		vsa.VerifiedLevels(builderID, builderOpts),
+	), nil
+}
+
+// writeVSAs signs the VSAs and writes them to path, one DSSE envelope
+// per line.
+func writeVSAs(ctx context.Context, path string, signer *dsselib.EnvelopeSigner, statements []*vsa.Statement) error {
+	var out []byte
+	for _, statement := range statements {
+		env, err := vsa.Sign(ctx, statement, signer)
+		if err != nil {
+			return err
+		}
+		line, err := json.Marshal(env)
+		if err != nil {
+			return err
+		}
+		out = append(out, line...)
+		out = append(out, '\n')
+	}
+	return os.WriteFile(path, out, 0o644)
+}
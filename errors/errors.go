@@ -12,6 +12,7 @@ var (
 	ErrorInvalidBuildType          = errors.New("buildType is invalid")
 	ErrorMismatchSource            = errors.New("source used to generate the binary does not match provenance")
 	ErrorMismatchWorkflowInputs    = errors.New("workflow input does not match")
+	ErrorMismatchBuildSubstitution = errors.New("build substitution does not match")
 	ErrorMalformedURI              = errors.New("URI is malformed")
 	ErrorMismatchCertificate       = errors.New("certificate and provenance mismatch")
 	ErrorInvalidCertificate        = errors.New("invalid certificate")
@@ -21,6 +22,7 @@ var (
 	ErrorInvalidSemver             = errors.New("invalid semantic version")
 	ErrorRekorSearch               = errors.New("error searching rekor entries")
 	ErrorMismatchHash              = errors.New("artifact hash does not match provenance subject")
+	ErrorMismatchSubjectName       = errors.New("artifact name does not match provenance subject")
 	ErrorNonVerifiableClaim        = errors.New("provenance claim cannot be verified")
 	ErrorMismatchIntoto            = errors.New("verified intoto provenance does not match text provenance")
 	ErrorInvalidRef                = errors.New("invalid ref")
@@ -44,4 +46,23 @@ var (
 	ErrorInvalidHash               = errors.New("invalid hash")
 	ErrorNotPresent                = errors.New("not present")
 	ErrorInvalidPublicKey          = errors.New("invalid public key")
+	ErrorInvalidPrivateKey         = errors.New("invalid private key")
+	ErrorInvalidSignatureThreshold = errors.New("invalid signature threshold")
+	ErrorMismatchBuildTrigger      = errors.New("build trigger does not match")
+	ErrorMismatchRunnerEnvironment = errors.New("runner environment does not match")
+	ErrorMismatchSourceCommit      = errors.New("commit used to generate the binary does not match provenance")
+	ErrorInvalidSourceCheckout     = errors.New("invalid source checkout")
+	ErrorMismatchSourceCheckout    = errors.New("provenance does not match source checkout")
+	ErrorMismatchVerifierID        = errors.New("verifier ID does not match VSA")
+	ErrorMismatchResourceURI       = errors.New("resource URI does not match VSA")
+	ErrorInvalidVerificationResult = errors.New("VSA verification result is not PASSED")
+	ErrorMismatchVerifiedLevels    = errors.New("VSA verified levels do not meet the minimum")
+	ErrorMismatchKeyValidity       = errors.New("signing key is not valid at the signing time")
+	ErrorInvalidCheckpoint         = errors.New("invalid transparency log checkpoint")
+	ErrorInconsistentCheckpoint    = errors.New("transparency log checkpoint is not consistent with the last verified checkpoint")
+	ErrorMissingWitnessCosignature = errors.New("transparency log checkpoint is not co-signed by enough witnesses")
+	ErrorInvalidTimestamp          = errors.New("invalid RFC 3161 timestamp")
+	ErrorMissingTimestamp          = errors.New("no trusted timestamp")
+	ErrorInvalidChecksums          = errors.New("invalid checksums file")
+	ErrorMismatchChecksum          = errors.New("artifact digest does not match checksums file")
 )
@@ -0,0 +1,68 @@
+// Package logging lets callers receive progress messages from the
+// verifiers, such as the keys and transparency log entries used. The
+// verifiers are silent unless a Logger is set on the context.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Logger receives progress messages. The message is a human-readable
+// sentence and the args are key-value pairs for structured logging, as for
+// log/slog. A *slog.Logger implements Logger.
+type Logger interface {
+	// Info reports a successful verification step.
+	Info(msg string, args ...any)
+	// Warn reports a condition that does not fail verification, or an
+	// attestation that is skipped.
+	Warn(msg string, args ...any)
+}
+
+type loggerKey struct{}
+
+// WithLogger returns a copy of ctx with the logger set.
+func WithLogger(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger set on ctx, or a logger that discards all
+// messages.
+func FromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(Logger); ok && logger != nil {
+		return logger
+	}
+	return discard{}
+}
+
+type discard struct{}
+
+func (discard) Info(string, ...any) {}
+func (discard) Warn(string, ...any) {}
+
+// TextLogger returns a logger that writes each message on its own line,
+// without the args. It is used for the human-readable output of the CLI.
+func TextLogger(w io.Writer) Logger {
+	return &textLogger{w: w}
+}
+
+type textLogger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *textLogger) Info(msg string, _ ...any) {
+	l.write(msg)
+}
+
+func (l *textLogger) Warn(msg string, _ ...any) {
+	l.write(msg)
+}
+
+func (l *textLogger) write(msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintln(l.w, msg)
+}
@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func Test_FromContext(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	// The default logger is silent.
+	FromContext(context.Background()).Info("not logged")
+
+	ctx := WithLogger(context.Background(), slog.New(handler))
+	FromContext(ctx).Info("Verified signature", "keyID", "abc")
+	FromContext(ctx).Warn("Skipped attestation")
+
+	expected := "level=INFO msg=\"Verified signature\" keyID=abc\n" +
+		"level=WARN msg=\"Skipped attestation\"\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("unexpected output %q, expected %q", got, expected)
+	}
+}
+
+func Test_TextLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf strings.Builder
+	logger := TextLogger(&buf)
+	logger.Info("Verified signature with public keys abc", "keyIDs", []string{"abc"})
+	logger.Warn("Build was not triggered from GitHub")
+
+	expected := "Verified signature with public keys abc\nBuild was not triggered from GitHub\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("unexpected output %q, expected %q", got, expected)
+	}
+}
@@ -15,9 +15,27 @@ type ProvenanceOpts struct {
 	// ExpectedDigest is the expected artifact sha included in the provenance.
 	ExpectedDigest string
 
+	// ExpectedDigests maps digest algorithms, named as in in-toto digest sets
+	// (e.g. sha512, sha3_256), to the expected artifact digests. If set, it is
+	// used instead of ExpectedDigest to match subjects: every algorithm present
+	// in both the subject and this map must match.
+	ExpectedDigests map[string]string
+
+	// ExpectedSubjectName is the expected name, or glob pattern, of the
+	// provenance subject matching the expected digests.
+	ExpectedSubjectName *string
+
 	// ExpectedSourceURI is the expected source URI in the provenance.
 	ExpectedSourceURI string
 
+	// ExpectedSourceCommit is the expected git commit of the source, either
+	// in full or abbreviated.
+	ExpectedSourceCommit *string
+
+	// SourceCheckout is the path to a local git checkout of the source
+	// repository, used to cross-check the commit, tag and branch.
+	SourceCheckout *string
+
 	// ExpectedBuilderID is the expected builder ID that is passed from user and verified
 	ExpectedBuilderID string
 
@@ -30,10 +48,62 @@ type ProvenanceOpts struct {
 
 	// ExpectedProvenanceRepository is the provenance repository that is passed from user and not verified
 	ExpectedProvenanceRepository *string
+
+	// ExpectedBuildTriggers is the list of trigger events (e.g. push, release)
+	// allowed to have started the build. An empty list allows any trigger.
+	ExpectedBuildTriggers []string
+
+	// ExpectedBuildSubstitutions is a map of key=value substitutions of a
+	// Google Cloud Build build.
+	ExpectedBuildSubstitutions map[string]string
+
+	// ExpectedGCBTrigger is the expected name or ID of the Google Cloud Build
+	// trigger that started the build.
+	ExpectedGCBTrigger *string
+
+	// RequireHostedRunner requires the build to have run on a GitHub-hosted runner.
+	RequireHostedRunner bool
 }
 
 // BuildOpts are the options for checking the builder.
 type BuilderOpts struct {
 	// ExpectedBuilderID is the builderID passed in from the user to be verified
 	ExpectedID *string
+
+	// PublicKeys are PEM-encoded public keys trusted to sign the provenance,
+	// for builders that sign with static keys rather than Sigstore.
+	PublicKeys [][]byte
+
+	// SignatureThreshold is the minimum number of distinct PublicKeys that
+	// must have signed the provenance. Zero requires a single signature.
+	SignatureThreshold int
+}
+
+// VSAOpts are the options for checking a Verification Summary Attestation.
+type VSAOpts struct {
+	// ExpectedDigests maps digest algorithms to the expected digests of the
+	// VSA subject, as in ProvenanceOpts.ExpectedDigests.
+	ExpectedDigests map[string]string
+
+	// ExpectedVerifierID is the expected verifier.id.
+	ExpectedVerifierID string
+
+	// ExpectedResourceURI is the expected resourceUri.
+	ExpectedResourceURI string
+
+	// ExpectedLevels are the minimum levels, e.g. SLSA_BUILD_LEVEL_2, the
+	// VSA must have verified. A higher level on the same track also satisfies
+	// a minimum level.
+	ExpectedLevels []string
+
+	// PublicKey is the PEM-encoded public key of the verifier. If nil, the VSA
+	// must be a Sigstore bundle signed by the expected keyless identity.
+	PublicKey []byte
+
+	// ExpectedSignerIdentity is a regular expression matched against the
+	// certificate identity of a keyless signature.
+	ExpectedSignerIdentity string
+
+	// ExpectedSignerIssuer is the OIDC issuer of a keyless signature.
+	ExpectedSignerIssuer string
 }
@@ -0,0 +1,258 @@
+// Package provenance provides a read model of verified SLSA provenance that
+// does not depend on the version of the provenance predicate.
+package provenance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	slsacommon "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
+	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	slsa1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+const (
+	// PredicateSLSAProvenanceV01 is the SLSA v0.1 provenance predicate type.
+	PredicateSLSAProvenanceV01 = "https://slsa.dev/provenance/v0.1"
+	// PredicateSLSAProvenanceV02 is the SLSA v0.2 provenance predicate type.
+	PredicateSLSAProvenanceV02 = slsa02.PredicateSLSAProvenance
+	// PredicateSLSAProvenanceV1 is the SLSA v1.0 provenance predicate type.
+	PredicateSLSAProvenanceV1 = slsa1.PredicateSLSAProvenance
+
+	// statementTypeV1 is the in-toto v1 statement type.
+	statementTypeV1 = "https://in-toto.io/Statement/v1"
+)
+
+// Provenance is verified SLSA provenance.
+type Provenance struct {
+	// PredicateType is the provenance predicate type, e.g.
+	// https://slsa.dev/provenance/v1.
+	PredicateType string
+
+	// BuilderID is the builder ID in the predicate.
+	BuilderID string
+
+	// BuildType is the buildType, or the recipe type for SLSA v0.1.
+	BuildType string
+
+	// Subjects are the statement subjects.
+	Subjects []intoto.Subject
+
+	// Source is the source repository the build was run from.
+	Source Source
+
+	// InvocationID identifies the build run, if recorded.
+	InvocationID string
+
+	// StartedOn and FinishedOn are the build start and finish times, if
+	// recorded.
+	StartedOn  *time.Time
+	FinishedOn *time.Time
+
+	// ResolvedDependencies are the resolved dependencies, or the materials
+	// before SLSA v1.0.
+	ResolvedDependencies []ResourceDescriptor
+
+	// ExternalParameters are the external parameters, or the invocation
+	// parameters for SLSA v0.2 and the recipe arguments for SLSA v0.1.
+	ExternalParameters map[string]any
+}
+
+// Source is a source repository at a commit.
+type Source struct {
+	// URI is the repository URI without the git ref, e.g.
+	// git+https://github.com/org/repo.
+	URI string
+
+	// Ref is the git ref, e.g. refs/tags/v1.0.0, if recorded.
+	Ref string
+
+	// Commit is the git commit, if recorded.
+	Commit string
+}
+
+// ResourceDescriptor is a resource used by the build.
+type ResourceDescriptor struct {
+	URI    string
+	Digest map[string]string
+	// Name is only set for SLSA v1.0.
+	Name string
+}
+
+// FromStatement parses a SLSA v0.1, v0.2 or v1.0 provenance statement.
+// The source is the first git dependency, preferring the configSource for
+// SLSA v0.2. Verifiers that know where their builder records the source
+// override it.
+func FromStatement(statement []byte) (*Provenance, error) {
+	var header intoto.StatementHeader
+	if err := json.Unmarshal(statement, &header); err != nil {
+		return nil, fmt.Errorf("%w: %v", serrors.ErrorInvalidDssePayload, err)
+	}
+	if header.Type != intoto.StatementInTotoV01 && header.Type != statementTypeV1 {
+		return nil, fmt.Errorf("%w: unexpected statement type %q", serrors.ErrorInvalidDssePayload, header.Type)
+	}
+
+	var p *Provenance
+	var err error
+	switch header.PredicateType {
+	case PredicateSLSAProvenanceV01:
+		p, err = fromV01(statement)
+	case PredicateSLSAProvenanceV02:
+		p, err = fromV02(statement)
+	case PredicateSLSAProvenanceV1:
+		p, err = fromV1(statement)
+	default:
+		return nil, fmt.Errorf("%w: unexpected predicate type %q", serrors.ErrorInvalidDssePayload, header.PredicateType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.PredicateType = header.PredicateType
+	p.Subjects = header.Subject
+	return p, nil
+}
+
+// predicateV01 holds the SLSA v0.1 fields that are read. The
+// recipe.definedInMaterial field is not read, as Google Cloud Build
+// records it as a string.
+type predicateV01 struct {
+	Builder slsacommon.ProvenanceBuilder `json:"builder"`
+	Recipe  struct {
+		Type      string `json:"type"`
+		Arguments any    `json:"arguments,omitempty"`
+	} `json:"recipe"`
+	Metadata *struct {
+		BuildInvocationID string     `json:"buildInvocationId,omitempty"`
+		BuildStartedOn    *time.Time `json:"buildStartedOn,omitempty"`
+		BuildFinishedOn   *time.Time `json:"buildFinishedOn,omitempty"`
+	} `json:"metadata,omitempty"`
+	Materials []slsacommon.ProvenanceMaterial `json:"materials,omitempty"`
+}
+
+func fromV01(statement []byte) (*Provenance, error) {
+	var s struct {
+		Predicate predicateV01 `json:"predicate"`
+	}
+	if err := json.Unmarshal(statement, &s); err != nil {
+		return nil, fmt.Errorf("%w: %v", serrors.ErrorInvalidDssePayload, err)
+	}
+
+	pred := s.Predicate
+	p := &Provenance{
+		BuilderID:            pred.Builder.ID,
+		BuildType:            pred.Recipe.Type,
+		ExternalParameters:   asMap(pred.Recipe.Arguments),
+		ResolvedDependencies: fromMaterials(pred.Materials),
+	}
+	if pred.Metadata != nil {
+		p.InvocationID = pred.Metadata.BuildInvocationID
+		p.StartedOn = pred.Metadata.BuildStartedOn
+		p.FinishedOn = pred.Metadata.BuildFinishedOn
+	}
+	p.Source = gitSource(p.ResolvedDependencies)
+	return p, nil
+}
+
+func fromV02(statement []byte) (*Provenance, error) {
+	var s struct {
+		Predicate slsa02.ProvenancePredicate `json:"predicate"`
+	}
+	if err := json.Unmarshal(statement, &s); err != nil {
+		return nil, fmt.Errorf("%w: %v", serrors.ErrorInvalidDssePayload, err)
+	}
+
+	pred := s.Predicate
+	p := &Provenance{
+		BuilderID:            pred.Builder.ID,
+		BuildType:            pred.BuildType,
+		ExternalParameters:   asMap(pred.Invocation.Parameters),
+		ResolvedDependencies: fromMaterials(pred.Materials),
+	}
+	if pred.Metadata != nil {
+		p.InvocationID = pred.Metadata.BuildInvocationID
+		p.StartedOn = pred.Metadata.BuildStartedOn
+		p.FinishedOn = pred.Metadata.BuildFinishedOn
+	}
+	if cs := pred.Invocation.ConfigSource; cs.URI != "" {
+		p.Source = SourceNew(cs.URI, cs.Digest)
+	} else {
+		p.Source = gitSource(p.ResolvedDependencies)
+	}
+	return p, nil
+}
+
+func fromV1(statement []byte) (*Provenance, error) {
+	var s struct {
+		Predicate slsa1.ProvenancePredicate `json:"predicate"`
+	}
+	if err := json.Unmarshal(statement, &s); err != nil {
+		return nil, fmt.Errorf("%w: %v", serrors.ErrorInvalidDssePayload, err)
+	}
+
+	pred := s.Predicate
+	p := &Provenance{
+		BuilderID:          pred.RunDetails.Builder.ID,
+		BuildType:          pred.BuildDefinition.BuildType,
+		ExternalParameters: asMap(pred.BuildDefinition.ExternalParameters),
+		InvocationID:       pred.RunDetails.BuildMetadata.InvocationID,
+		StartedOn:          pred.RunDetails.BuildMetadata.StartedOn,
+		FinishedOn:         pred.RunDetails.BuildMetadata.FinishedOn,
+	}
+	for _, d := range pred.BuildDefinition.ResolvedDependencies {
+		p.ResolvedDependencies = append(p.ResolvedDependencies, ResourceDescriptor{
+			URI:    d.URI,
+			Digest: d.Digest,
+			Name:   d.Name,
+		})
+	}
+	p.Source = gitSource(p.ResolvedDependencies)
+	return p, nil
+}
+
+// SourceNew returns the source at a URI such as
+// git+https://github.com/org/repo@refs/heads/main, with the commit in
+// the sha1 or gitCommit digest.
+func SourceNew(uri string, digest map[string]string) Source {
+	s := Source{
+		URI:    uri,
+		Commit: digest["sha1"],
+	}
+	if s.Commit == "" {
+		s.Commit = digest["gitCommit"]
+	}
+	if strings.HasPrefix(uri, "git+") {
+		if u, ref, found := strings.Cut(uri, "@"); found {
+			s.URI, s.Ref = u, ref
+		}
+	}
+	return s
+}
+
+// gitSource returns the source of the first git dependency.
+func gitSource(deps []ResourceDescriptor) Source {
+	for _, d := range deps {
+		if strings.HasPrefix(d.URI, "git+") {
+			return SourceNew(d.URI, d.Digest)
+		}
+	}
+	return Source{}
+}
+
+func fromMaterials(materials []slsacommon.ProvenanceMaterial) []ResourceDescriptor {
+	deps := make([]ResourceDescriptor, 0, len(materials))
+	for _, m := range materials {
+		deps = append(deps, ResourceDescriptor{URI: m.URI, Digest: m.Digest})
+	}
+	return deps
+}
+
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
@@ -0,0 +1,248 @@
+package provenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+const (
+	testDigest = "8fb8a7c1a2b5a8d3d8f0cd9a35a0e1b1d3b0b8a3c7a0d96d9e7ab2a42e2c6ad2"
+	testCommit = "6f3a9c3d4b2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a"
+)
+
+func Test_FromStatement(t *testing.T) {
+	t.Parallel()
+
+	startedOn := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	finishedOn := time.Date(2023, 5, 1, 10, 5, 0, 0, time.UTC)
+	subjects := []intoto.Subject{
+		{
+			Name:   "app",
+			Digest: map[string]string{"sha256": testDigest},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		statement string
+		expected  *Provenance
+		err       error
+	}{
+		{
+			name: "v0.1",
+			statement: `{
+				"_type": "https://in-toto.io/Statement/v0.1",
+				"predicateType": "https://slsa.dev/provenance/v0.1",
+				"subject": [{"name": "app", "digest": {"sha256": "` + testDigest + `"}}],
+				"predicate": {
+					"builder": {"id": "https://cloudbuild.googleapis.com/GoogleHostedWorker@v0.3"},
+					"recipe": {
+						"type": "https://cloudbuild.googleapis.com/CloudBuildYaml@v0.1",
+						"definedInMaterial": "0",
+						"arguments": {"@type": "type.googleapis.com/google.devtools.cloudbuild.v1.Build"}
+					},
+					"metadata": {
+						"buildInvocationId": "build-1",
+						"buildStartedOn": "2023-05-01T10:00:00Z",
+						"buildFinishedOn": "2023-05-01T10:05:00Z"
+					},
+					"materials": [
+						{"uri": "git+https://github.com/org/app@refs/tags/v1.0.0", "digest": {"sha1": "` + testCommit + `"}}
+					]
+				}
+			}`,
+			expected: &Provenance{
+				PredicateType: PredicateSLSAProvenanceV01,
+				BuilderID:     "https://cloudbuild.googleapis.com/GoogleHostedWorker@v0.3",
+				BuildType:     "https://cloudbuild.googleapis.com/CloudBuildYaml@v0.1",
+				Subjects:      subjects,
+				Source: Source{
+					URI:    "git+https://github.com/org/app",
+					Ref:    "refs/tags/v1.0.0",
+					Commit: testCommit,
+				},
+				InvocationID: "build-1",
+				StartedOn:    &startedOn,
+				FinishedOn:   &finishedOn,
+				ResolvedDependencies: []ResourceDescriptor{
+					{
+						URI:    "git+https://github.com/org/app@refs/tags/v1.0.0",
+						Digest: map[string]string{"sha1": testCommit},
+					},
+				},
+				ExternalParameters: map[string]any{"@type": "type.googleapis.com/google.devtools.cloudbuild.v1.Build"},
+			},
+		},
+		{
+			name: "v0.2 config source",
+			statement: `{
+				"_type": "https://in-toto.io/Statement/v0.1",
+				"predicateType": "https://slsa.dev/provenance/v0.2",
+				"subject": [{"name": "app", "digest": {"sha256": "` + testDigest + `"}}],
+				"predicate": {
+					"builder": {"id": "https://ci.example.com/builder"},
+					"buildType": "https://ci.example.com/build@v1",
+					"invocation": {
+						"configSource": {
+							"uri": "git+https://github.com/org/app@refs/heads/main",
+							"digest": {"sha1": "` + testCommit + `"},
+							"entryPoint": "build.yml"
+						},
+						"parameters": {"target": "release"}
+					},
+					"metadata": {"buildInvocationId": "run-1"},
+					"materials": [
+						{"uri": "git+https://github.com/org/tools", "digest": {"sha1": "0000000000000000000000000000000000000000"}}
+					]
+				}
+			}`,
+			expected: &Provenance{
+				PredicateType: PredicateSLSAProvenanceV02,
+				BuilderID:     "https://ci.example.com/builder",
+				BuildType:     "https://ci.example.com/build@v1",
+				Subjects:      subjects,
+				Source: Source{
+					URI:    "git+https://github.com/org/app",
+					Ref:    "refs/heads/main",
+					Commit: testCommit,
+				},
+				InvocationID: "run-1",
+				ResolvedDependencies: []ResourceDescriptor{
+					{
+						URI:    "git+https://github.com/org/tools",
+						Digest: map[string]string{"sha1": "0000000000000000000000000000000000000000"},
+					},
+				},
+				ExternalParameters: map[string]any{"target": "release"},
+			},
+		},
+		{
+			name: "v0.2 materials",
+			statement: `{
+				"_type": "https://in-toto.io/Statement/v0.1",
+				"predicateType": "https://slsa.dev/provenance/v0.2",
+				"subject": [{"name": "app", "digest": {"sha256": "` + testDigest + `"}}],
+				"predicate": {
+					"builder": {"id": "https://ci.example.com/builder"},
+					"buildType": "https://ci.example.com/build@v1",
+					"materials": [
+						{"uri": "oci://registry.example.com/base", "digest": {"sha256": "` + testDigest + `"}},
+						{"uri": "git+https://github.com/org/app.git", "digest": {"gitCommit": "` + testCommit + `"}}
+					]
+				}
+			}`,
+			expected: &Provenance{
+				PredicateType: PredicateSLSAProvenanceV02,
+				BuilderID:     "https://ci.example.com/builder",
+				BuildType:     "https://ci.example.com/build@v1",
+				Subjects:      subjects,
+				Source: Source{
+					URI:    "git+https://github.com/org/app.git",
+					Commit: testCommit,
+				},
+				ResolvedDependencies: []ResourceDescriptor{
+					{
+						URI:    "oci://registry.example.com/base",
+						Digest: map[string]string{"sha256": testDigest},
+					},
+					{
+						URI:    "git+https://github.com/org/app.git",
+						Digest: map[string]string{"gitCommit": testCommit},
+					},
+				},
+			},
+		},
+		{
+			name: "v1.0",
+			statement: `{
+				"_type": "https://in-toto.io/Statement/v1",
+				"predicateType": "https://slsa.dev/provenance/v1",
+				"subject": [{"name": "app", "digest": {"sha256": "` + testDigest + `"}}],
+				"predicate": {
+					"buildDefinition": {
+						"buildType": "https://ci.example.com/build@v1",
+						"externalParameters": {"workflow": {"path": "build.yml"}},
+						"resolvedDependencies": [
+							{"uri": "git+https://github.com/org/app@refs/tags/v1.0.0", "digest": {"gitCommit": "` + testCommit + `"}, "name": "source"}
+						]
+					},
+					"runDetails": {
+						"builder": {"id": "https://ci.example.com/builder"},
+						"metadata": {
+							"invocationId": "run-1",
+							"startedOn": "2023-05-01T10:00:00Z",
+							"finishedOn": "2023-05-01T10:05:00Z"
+						}
+					}
+				}
+			}`,
+			expected: &Provenance{
+				PredicateType: PredicateSLSAProvenanceV1,
+				BuilderID:     "https://ci.example.com/builder",
+				BuildType:     "https://ci.example.com/build@v1",
+				Subjects:      subjects,
+				Source: Source{
+					URI:    "git+https://github.com/org/app",
+					Ref:    "refs/tags/v1.0.0",
+					Commit: testCommit,
+				},
+				InvocationID: "run-1",
+				StartedOn:    &startedOn,
+				FinishedOn:   &finishedOn,
+				ResolvedDependencies: []ResourceDescriptor{
+					{
+						URI:    "git+https://github.com/org/app@refs/tags/v1.0.0",
+						Digest: map[string]string{"gitCommit": testCommit},
+						Name:   "source",
+					},
+				},
+				ExternalParameters: map[string]any{"workflow": map[string]any{"path": "build.yml"}},
+			},
+		},
+		{
+			name: "unknown predicate type",
+			statement: `{
+				"_type": "https://in-toto.io/Statement/v1",
+				"predicateType": "https://slsa.dev/verification_summary/v1",
+				"subject": [{"name": "app", "digest": {"sha256": "` + testDigest + `"}}],
+				"predicate": {}
+			}`,
+			err: serrors.ErrorInvalidDssePayload,
+		},
+		{
+			name: "unknown statement type",
+			statement: `{
+				"_type": "https://in-toto.io/Statement/v2",
+				"predicateType": "https://slsa.dev/provenance/v1",
+				"predicate": {}
+			}`,
+			err: serrors.ErrorInvalidDssePayload,
+		},
+		{
+			name:      "not json",
+			statement: "{",
+			err:       serrors.ErrorInvalidDssePayload,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			prov, err := FromStatement([]byte(tt.statement))
+			if !cmp.Equal(err, tt.err, cmpopts.EquateErrors()) {
+				t.Fatalf(cmp.Diff(err, tt.err, cmpopts.EquateErrors()))
+			}
+			if diff := cmp.Diff(tt.expected, prov, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("unexpected provenance (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -36,6 +36,36 @@ type SLSAVerifier interface {
 	) ([]byte, *utils.TrustedBuilderID, error)
 }
 
+// SLSAResultVerifier is a SLSAVerifier that also returns the details of
+// the verification.
+type SLSAResultVerifier interface {
+	SLSAVerifier
+
+	// VerifyArtifactResult is like VerifyArtifact and returns the
+	// verification result.
+	VerifyArtifactResult(ctx context.Context,
+		provenance []byte, artifactHash string,
+		provenanceOpts *options.ProvenanceOpts,
+		builderOpts *options.BuilderOpts,
+	) (*utils.VerificationResult, error)
+
+	// VerifyImageResult is like VerifyImage and returns the verification
+	// result.
+	VerifyImageResult(ctx context.Context,
+		provenance []byte, artifactImage string,
+		provenanceOpts *options.ProvenanceOpts,
+		builderOpts *options.BuilderOpts,
+	) (*utils.VerificationResult, error)
+
+	// VerifyNpmPackageResult is like VerifyNpmPackage and returns the
+	// verification result.
+	VerifyNpmPackageResult(ctx context.Context,
+		attestations []byte, tarballHash string,
+		provenanceOpts *options.ProvenanceOpts,
+		builderOpts *options.BuilderOpts,
+	) (*utils.VerificationResult, error)
+}
+
 func RegisterVerifier(name string, verifier SLSAVerifier) {
 	SLSAVerifiers[name] = verifier
 }
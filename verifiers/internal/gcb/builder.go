@@ -0,0 +1,62 @@
+package gcb
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb/keys"
+)
+
+// hostedWorkerBuilderID is the builder ID of the Google-hosted workers,
+// without version.
+const hostedWorkerBuilderID = "https://cloudbuild.googleapis.com/GoogleHostedWorker"
+
+// privatePoolRegex matches the builder IDs of private pools, without
+// version: the resource name of the worker pool.
+var privatePoolRegex = regexp.MustCompile(`^https://cloudbuild\.googleapis\.com/projects/([^/@]+)/locations/([^/@]+)/workerPools/([^/@]+)$`)
+
+// privatePoolVersionsV01 are the versions of private pool builders that
+// generate v0.1 provenance.
+var privatePoolVersionsV01 = []string{"v0.3"}
+
+// privatePool is a Cloud Build private pool.
+type privatePool struct {
+	location string
+	name     string
+}
+
+// parsePrivatePool parses the builder ID of a private pool, with or without
+// version.
+func parsePrivatePool(builderID string) (*privatePool, bool) {
+	name, _, _ := strings.Cut(builderID, "@")
+	match := privatePoolRegex.FindStringSubmatch(name)
+	if len(match) != 4 {
+		return nil, false
+	}
+	return &privatePool{
+		location: match[2],
+		name:     match[3],
+	}, true
+}
+
+// isGCBBuilder returns true if the builder ID, without version, is a GCB
+// builder: the Google-hosted worker or a private pool.
+func isGCBBuilder(builderIDName string) bool {
+	if builderIDName == hostedWorkerBuilderID {
+		return true
+	}
+	_, ok := parsePrivatePool(builderIDName)
+	return ok && !strings.Contains(builderIDName, "@")
+}
+
+// verifyKey verifies that the provenance of the pool was signed with a key
+// of its region, or with a global key.
+func (p *privatePool) verifyKey(key *keys.Key) error {
+	if key.Region == "" || key.Region == "global" || key.Region == p.location {
+		return nil
+	}
+	return fmt.Errorf("%w: private pool %q in %q signed with key of %q",
+		serrors.ErrorMismatchBuilderID, p.name, p.location, key.Region)
+}
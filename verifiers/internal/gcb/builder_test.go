@@ -0,0 +1,23 @@
+package gcb
+
+import (
+	"testing"
+)
+
+func Test_isGCBBuilder(t *testing.T) {
+	t.Parallel()
+
+	for id, expected := range map[string]bool{
+		"https://cloudbuild.googleapis.com/GoogleHostedWorker":                                  true,
+		"https://cloudbuild.googleapis.com/projects/p/locations/us-central1/workerPools/w":      true,
+		"https://cloudbuild.googleapis.com/projects/p/locations/us-central1/workerPools/w@v0.3": false,
+		"https://cloudbuild.googleapis.com/projects/p/locations/us-central1/workerPools/w/x":    false,
+		"https://cloudbuild.googleapis.com/projects/p/workerPools/w":                            false,
+		"http://cloudbuild.googleapis.com/projects/p/locations/us-central1/workerPools/w":       false,
+		"https://tekton.dev/chains/v2":                                                          false,
+	} {
+		if got := isGCBBuilder(id); got != expected {
+			t.Errorf("isGCBBuilder(%q): expected %v, got %v", id, expected, got)
+		}
+	}
+}
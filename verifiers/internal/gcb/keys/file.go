@@ -0,0 +1,137 @@
+package keys
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// keySetFile is the format of a key set file:
+//
+//	{
+//	  "keys": [
+//	    {
+//	      "keyId": "projects/verified-builder/locations/global/keyRings/attestor/cryptoKeys/google-hosted-worker/cryptoKeyVersions/2",
+//	      "region": "global",
+//	      "algorithm": "EC_SIGN_P256_SHA256",
+//	      "signatureFormat": "pae",
+//	      "provenanceVersion": "v1.0",
+//	      "notBefore": "2024-01-01T00:00:00Z",
+//	      "publicKeyFile": "google-hosted-worker_2.pem"
+//	    }
+//	  ]
+//	}
+//
+// The public key is either inline in publicKey, PEM-encoded, or in
+// publicKeyFile, relative to the key set file.
+type keySetFile struct {
+	Keys []struct {
+		KeyID             string     `json:"keyId"`
+		Region            string     `json:"region"`
+		Algorithm         string     `json:"algorithm"`
+		SignatureFormat   string     `json:"signatureFormat"`
+		ProvenanceVersion string     `json:"provenanceVersion"`
+		NotBefore         *time.Time `json:"notBefore"`
+		NotAfter          *time.Time `json:"notAfter"`
+		PublicKey         string     `json:"publicKey"`
+		PublicKeyFile     string     `json:"publicKeyFile"`
+	} `json:"keys"`
+}
+
+// KeySetFromPath reads a key set from a JSON file, or from all the JSON
+// files of a directory. It replaces the embedded key set.
+func KeySetFromPath(path string) (*KeySet, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("%w: no key set file in %s", serrors.ErrorInvalidFormat, path)
+		}
+	}
+
+	set := &KeySet{keys: make(map[string]*Key)}
+	for _, file := range files {
+		if err := set.readFile(file); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return set, nil
+}
+
+func (s *KeySet) readFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var file keySetFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return fmt.Errorf("%w: %v", serrors.ErrorInvalidFormat, err)
+	}
+
+	for _, k := range file.Keys {
+		key := &Key{
+			ID:                k.KeyID,
+			Region:            k.Region,
+			Algorithm:         k.Algorithm,
+			Format:            k.SignatureFormat,
+			ProvenanceVersion: k.ProvenanceVersion,
+			NotBefore:         k.NotBefore,
+			NotAfter:          k.NotAfter,
+			name:              k.KeyID,
+		}
+
+		if key.ID == "" {
+			return fmt.Errorf("%w: key without keyId", serrors.ErrorInvalidFormat)
+		}
+		if key.Format != FormatPAE && key.Format != FormatPayload {
+			return fmt.Errorf("%w: key %q: invalid signatureFormat %q",
+				serrors.ErrorInvalidFormat, key.ID, key.Format)
+		}
+		if key.ProvenanceVersion != ProvenanceV01 && key.ProvenanceVersion != ProvenanceV10 {
+			return fmt.Errorf("%w: key %q: invalid provenanceVersion %q",
+				serrors.ErrorInvalidFormat, key.ID, key.ProvenanceVersion)
+		}
+		if key.NotBefore != nil && key.NotAfter != nil && key.NotAfter.Before(*key.NotBefore) {
+			return fmt.Errorf("%w: key %q: notAfter is before notBefore",
+				serrors.ErrorInvalidFormat, key.ID)
+		}
+
+		pemKey := []byte(k.PublicKey)
+		switch {
+		case k.PublicKey != "" && k.PublicKeyFile != "":
+			return fmt.Errorf("%w: key %q: both publicKey and publicKeyFile are set",
+				serrors.ErrorInvalidFormat, key.ID)
+		case k.PublicKeyFile != "":
+			keyPath := k.PublicKeyFile
+			if !filepath.IsAbs(keyPath) {
+				keyPath = filepath.Join(filepath.Dir(path), keyPath)
+			}
+			if pemKey, err = os.ReadFile(keyPath); err != nil {
+				return err
+			}
+		case k.PublicKey == "":
+			return fmt.Errorf("%w: key %q: no public key", serrors.ErrorInvalidFormat, key.ID)
+		}
+		if err := key.setPublicKey(pemKey); err != nil {
+			return err
+		}
+
+		if err := s.add(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package keys
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+func Test_KeySetFromPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		path     string
+		expected error
+	}{
+		{
+			name: "file",
+			path: "./testdata/keyset.json",
+		},
+		{
+			name: "directory",
+			path: "./testdata/dir",
+		},
+		{
+			name:     "duplicate key",
+			path:     "./testdata/duplicate",
+			expected: serrors.ErrorInvalidFormat,
+		},
+		{
+			name:     "algorithm mismatch",
+			path:     "./testdata/algorithm-mismatch.json",
+			expected: serrors.ErrorInvalidPublicKey,
+		},
+		{
+			name:     "invalid signature format",
+			path:     "./testdata/invalid-format.json",
+			expected: serrors.ErrorInvalidFormat,
+		},
+		{
+			name:     "invalid validity window",
+			path:     "./testdata/invalid-window.json",
+			expected: serrors.ErrorInvalidFormat,
+		},
+		{
+			name:     "no public key",
+			path:     "./testdata/no-public-key.json",
+			expected: serrors.ErrorInvalidFormat,
+		},
+		{
+			name:     "no file",
+			path:     "./testdata/missing.json",
+			expected: os.ErrNotExist,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			set, err := KeySetFromPath(tt.path)
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Fatalf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+			if err != nil {
+				return
+			}
+
+			key, ok := set.Get(V10GlobalPAEKeyID)
+			if !ok {
+				t.Fatalf("key %q not found", V10GlobalPAEKeyID)
+			}
+			notBefore := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+			if key.Format != FormatPAE || key.ProvenanceVersion != ProvenanceV10 ||
+				key.NotBefore == nil || !key.NotBefore.Equal(notBefore) || key.Name() != V10GlobalPAEKeyID {
+				t.Errorf("unexpected key %+v", key)
+			}
+		})
+	}
+}
+
+func Test_KeySetFromPath_Rotated(t *testing.T) {
+	t.Parallel()
+
+	set, err := KeySetFromPath("./testdata/rotated.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Keys of the same region are named after their ID.
+	for _, version := range []string{"1", "2"} {
+		id := "projects/verified-builder/locations/global/keyRings/attestor/cryptoKeys/google-hosted-worker/cryptoKeyVersions/" + version
+		key, ok := set.Get(id)
+		if !ok {
+			t.Fatalf("key %q not found", id)
+		}
+		if key.Region != "global" || key.Name() != id {
+			t.Errorf("unexpected key %+v", key)
+		}
+	}
+}
+
+func Test_DefaultKeySet(t *testing.T) {
+	t.Parallel()
+
+	set, err := DefaultKeySet()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id      string
+		name    string
+		format  string
+		version string
+	}{
+		{
+			id:      V10GlobalPAEKeyID,
+			name:    "global-pae-google-hosted-worker_1",
+			format:  FormatPAE,
+			version: ProvenanceV10,
+		},
+		{
+			id:      V01GlobalPAEKeyID,
+			name:    "global-pae-provenanceSigner_1",
+			format:  FormatPAE,
+			version: ProvenanceV01,
+		},
+		{
+			id:      "projects/verified-builder/locations/us-central1/keyRings/attestor/cryptoKeys/builtByGCB/cryptoKeyVersions/1",
+			name:    "us-central1",
+			format:  FormatPayload,
+			version: ProvenanceV01,
+		},
+	}
+	for _, tt := range tests {
+		key, ok := set.Get(tt.id)
+		if !ok {
+			t.Errorf("key %q not found", tt.id)
+			continue
+		}
+		if key.Name() != tt.name || key.Format != tt.format || key.ProvenanceVersion != tt.version {
+			t.Errorf("unexpected key %+v", key)
+		}
+	}
+}
+
+func Test_Key_ValidAt(t *testing.T) {
+	t.Parallel()
+
+	notBefore := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	key := &Key{NotBefore: &notBefore, NotAfter: &notAfter}
+
+	tests := []struct {
+		name     string
+		time     time.Time
+		expected error
+	}{
+		{
+			name: "within window",
+			time: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "at start",
+			time: notBefore,
+		},
+		{
+			name:     "before window",
+			time:     notBefore.Add(-time.Second),
+			expected: serrors.ErrorMismatchKeyValidity,
+		},
+		{
+			name:     "after window",
+			time:     notAfter.Add(time.Second),
+			expected: serrors.ErrorMismatchKeyValidity,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := key.ValidAt(tt.time)
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Errorf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"crypto"
 	"crypto/ecdsa"
-	"crypto/sha256"
+	"crypto/elliptic"
 	"crypto/x509"
 	"embed"
 	"encoding/pem"
 	"fmt"
 	"io/fs"
 	"path"
+	"strings"
+	"sync"
+	"time"
 
 	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
@@ -27,6 +30,10 @@ const (
 	// v0.1 global keys.
 	// Run command `gcloud kms keys versions get-public-key 1 --keyring attestor --key provenanceSigner --project verified-builder --location global`.
 	V01GlobalPAEKeyID = "projects/verified-builder/locations/global/keyRings/attestor/cryptoKeys/provenanceSigner/cryptoKeyVersions/1"
+
+	// v0.1 regional keys, by region.
+	// See README.md.
+	regionalKeyIDFormat = "projects/verified-builder/locations/%s/keyRings/attestor/cryptoKeys/builtByGCB/cryptoKeyVersions/1"
 )
 
 var globalKeyNames = map[string]string{
@@ -34,109 +41,233 @@ var globalKeyNames = map[string]string{
 	V01GlobalPAEKeyID: "global-pae-provenanceSigner_1",
 }
 
-type PublicKey struct {
-	value  []byte
+// Signature formats.
+const (
+	// FormatPAE is a DSSE signature over the PAE-encoded payload.
+	FormatPAE = "pae"
+	// FormatPayload is a legacy signature over the payload only.
+	FormatPayload = "payload"
+)
+
+// Signature algorithms, named after the Cloud KMS algorithms.
+const (
+	AlgorithmECP256SHA256 = "EC_SIGN_P256_SHA256"
+	AlgorithmECP384SHA384 = "EC_SIGN_P384_SHA384"
+)
+
+// Provenance versions signed by a key.
+const (
+	ProvenanceV01 = "v0.1"
+	ProvenanceV10 = "v1.0"
+)
+
+// Key is a GCB signing key and its metadata.
+type Key struct {
+	// ID is the Cloud KMS key version, used as the DSSE key ID.
+	ID string
+	// Region is the location of the key, e.g. us-central1 or global.
+	Region string
+	// Algorithm is the signature algorithm, e.g. EC_SIGN_P256_SHA256.
+	Algorithm string
+	// Format is the signature format, FormatPAE or FormatPayload.
+	Format string
+	// ProvenanceVersion is the version of the provenance signed by the key,
+	// ProvenanceV01 or ProvenanceV10.
+	ProvenanceVersion string
+	// NotBefore and NotAfter are the validity window of the key, if any.
+	NotBefore *time.Time
+	NotAfter  *time.Time
+
+	name   string
 	pubKey *ecdsa.PublicKey
-	region string
-	// TODO: key type and size
+	hash   crypto.Hash
 }
 
-func NewPublicKey(region string) (*PublicKey, error) {
-	content, err := fs.ReadFile(publicKeys, path.Join("materials", region+".key"))
-	if err != nil {
-		return nil, fmt.Errorf("%w: cannot read key materials", err)
+// setPublicKey sets the PEM-encoded public key, which must match the
+// algorithm of the key.
+func (k *Key) setPublicKey(content []byte) error {
+	var curve elliptic.Curve
+	switch k.Algorithm {
+	case AlgorithmECP256SHA256:
+		curve, k.hash = elliptic.P256(), crypto.SHA256
+	case AlgorithmECP384SHA384:
+		curve, k.hash = elliptic.P384(), crypto.SHA384
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q for key %q",
+			serrors.ErrorInvalidPublicKey, k.Algorithm, k.name)
 	}
 
 	block, _ := pem.Decode(content)
 	if block == nil {
-		return nil, fmt.Errorf("%w: %s", serrors.ErrorInvalidPEM, content)
+		return fmt.Errorf("%w: %s", serrors.ErrorInvalidPEM, content)
 	}
 
 	key, err := x509.ParsePKIXPublicKey(block.Bytes)
 	if err != nil {
-		return nil, fmt.Errorf("x509.ParsePKIXPublicKey: %w", err)
+		return fmt.Errorf("x509.ParsePKIXPublicKey: %w", err)
 	}
 
 	pubKey, ok := key.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("%w: public key not of type ECDSA", err)
+	if !ok || pubKey.Curve != curve {
+		return fmt.Errorf("%w: public key of %q does not match algorithm %q",
+			serrors.ErrorInvalidPublicKey, k.name, k.Algorithm)
 	}
+	k.pubKey = pubKey
+	return nil
+}
 
-	return &PublicKey{
-		value:  content,
-		pubKey: pubKey,
-		region: region,
-	}, nil
+// Name returns the name of the key, for messages.
+func (k *Key) Name() string {
+	return k.name
 }
 
-func (p *PublicKey) VerifySignature(digest [32]byte, sig []byte) error {
-	if p.pubKey == nil {
+// VerifySignature verifies a signature over data.
+func (k *Key) VerifySignature(data, sig []byte) error {
+	if k.pubKey == nil {
 		return fmt.Errorf("%w: key is empty", serrors.ErrorInternal)
 	}
-	if !ecdsa.VerifyASN1(p.pubKey, digest[:], sig) {
+	h := k.hash.New()
+	h.Write(data)
+	if !ecdsa.VerifyASN1(k.pubKey, h.Sum(nil), sig) {
 		return fmt.Errorf("%w: cannot verify with public key '%v'",
-			serrors.ErrorInvalidSignature, p.region)
+			serrors.ErrorInvalidSignature, k.name)
 	}
 
 	return nil
 }
 
-func (p *PublicKey) Name() string {
-	return p.region
+// VerifyPAESignature verifies the DSSE signature of the envelope.
+func (k *Key) VerifyPAESignature(ctx context.Context, envelope *dsselib.Envelope) error {
+	verifier, err := dsselib.NewEnvelopeVerifier(k)
+	if err != nil {
+		return err
+	}
+	_, err = verifier.Verify(ctx, envelope)
+	return err
 }
 
-type GlobalPAEKey struct {
-	publicKey *PublicKey
-	id        string
-	Verifier  *dsselib.EnvelopeVerifier
+// Verify implements dsse.Verifier.Verify. It verifies
+// a signature formatted in DSSE-conformant PAE.
+func (k *Key) Verify(_ context.Context, data, sig []byte) error {
+	return k.VerifySignature(data, sig)
 }
 
-func NewGlobalPAEKey(id string) (*GlobalPAEKey, error) {
-	name, ok := globalKeyNames[id]
-	if !ok {
-		return nil, fmt.Errorf("%w: unknown key %v", serrors.ErrorInternal, id)
-	}
+// KeyID implements dsse.Verifier.KeyID.
+func (k *Key) KeyID() (string, error) {
+	return k.ID, nil
+}
 
-	publicKey, err := NewPublicKey(name)
-	if err != nil {
-		return nil, fmt.Errorf("%w: unable to create public key for Global PAE key %v", err, name)
+// Public implements dsse.Verifier.Public.
+func (k *Key) Public() crypto.PublicKey {
+	return k.pubKey
+}
+
+// ValidAt returns an error if t is outside the validity window of the key.
+func (k *Key) ValidAt(t time.Time) error {
+	if (k.NotBefore == nil || !t.Before(*k.NotBefore)) &&
+		(k.NotAfter == nil || !t.After(*k.NotAfter)) {
+		return nil
 	}
 
-	globalPaeKey := &GlobalPAEKey{
-		publicKey: publicKey,
-		id:        id,
+	notBefore, notAfter := "-", "-"
+	if k.NotBefore != nil {
+		notBefore = k.NotBefore.UTC().Format(time.RFC3339)
 	}
-	envVerifier, err := dsselib.NewEnvelopeVerifier(globalPaeKey)
-	if err != nil {
-		return nil, err
+	if k.NotAfter != nil {
+		notAfter = k.NotAfter.UTC().Format(time.RFC3339)
 	}
-	globalPaeKey.Verifier = envVerifier
-	return globalPaeKey, nil
+	return fmt.Errorf("%w: key %q is valid from %s until %s, provenance was signed at %s",
+		serrors.ErrorMismatchKeyValidity, k.name, notBefore, notAfter, t.UTC().Format(time.RFC3339))
 }
 
-func (v *GlobalPAEKey) VerifyPAESignature(envelope *dsselib.Envelope) error {
-	_, err := v.Verifier.Verify(context.Background(), envelope)
-	return err
+// KeySet is a set of GCB signing keys, by key ID.
+type KeySet struct {
+	keys map[string]*Key
 }
 
-// Verify implements dsse.Verifier.Verify. It verifies
-// a signature formatted in DSSE-conformant PAE.
-func (v *GlobalPAEKey) Verify(_ context.Context, data, sig []byte) error {
-	// Verify the signature.
-	digest := sha256.Sum256(data)
-	return v.publicKey.VerifySignature(digest, sig)
+// Get returns the key with the ID, if any.
+func (s *KeySet) Get(id string) (*Key, bool) {
+	key, ok := s.keys[id]
+	return key, ok
 }
 
-// KeyID implements dsse.Verifier.KeyID.
-func (v *GlobalPAEKey) KeyID() (string, error) {
-	return v.id, nil
+// add adds a key, which must have a unique ID.
+func (s *KeySet) add(key *Key) error {
+	if _, ok := s.keys[key.ID]; ok {
+		return fmt.Errorf("%w: duplicate key %q", serrors.ErrorInvalidFormat, key.ID)
+	}
+	s.keys[key.ID] = key
+	return nil
 }
 
-// Public implements dsse.Verifier.Public.
-func (v *GlobalPAEKey) Public() crypto.PublicKey {
-	return v.publicKey
+var (
+	defaultKeySet     *KeySet
+	defaultKeySetErr  error
+	defaultKeySetOnce sync.Once
+)
+
+// DefaultKeySet returns the key set embedded in slsa-verifier: the global
+// PAE keys and the regional keys.
+func DefaultKeySet() (*KeySet, error) {
+	defaultKeySetOnce.Do(func() {
+		defaultKeySet, defaultKeySetErr = embeddedKeySet()
+	})
+	return defaultKeySet, defaultKeySetErr
 }
 
-func (v *GlobalPAEKey) Name() string {
-	return v.publicKey.Name()
+func embeddedKeySet() (*KeySet, error) {
+	entries, err := fs.ReadDir(publicKeys, "materials")
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read key materials", err)
+	}
+
+	set := &KeySet{keys: make(map[string]*Key)}
+	for id, name := range globalKeyNames {
+		version := ProvenanceV01
+		if id == V10GlobalPAEKeyID {
+			version = ProvenanceV10
+		}
+		key := &Key{
+			ID:                id,
+			Region:            "global",
+			Algorithm:         AlgorithmECP256SHA256,
+			Format:            FormatPAE,
+			ProvenanceVersion: version,
+			name:              name,
+		}
+		if err := addEmbeddedKey(set, key); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, entry := range entries {
+		region := strings.TrimSuffix(entry.Name(), ".key")
+		// Files of global PAE keys are named after the key.
+		if strings.HasPrefix(region, "global-pae") {
+			continue
+		}
+		key := &Key{
+			ID:                fmt.Sprintf(regionalKeyIDFormat, region),
+			Region:            region,
+			Algorithm:         AlgorithmECP256SHA256,
+			Format:            FormatPayload,
+			ProvenanceVersion: ProvenanceV01,
+			name:              region,
+		}
+		if err := addEmbeddedKey(set, key); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+func addEmbeddedKey(set *KeySet, key *Key) error {
+	content, err := fs.ReadFile(publicKeys, path.Join("materials", key.name+".key"))
+	if err != nil {
+		return fmt.Errorf("%w: cannot read key materials", err)
+	}
+	if err := key.setPublicKey(content); err != nil {
+		return fmt.Errorf("%w: unable to create public key %v", err, key.name)
+	}
+	return set.add(key)
 }
@@ -1,12 +1,13 @@
 package gcb
 
 import (
-	"crypto/sha256"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
 	"reflect"
 	"strings"
+	"time"
 
 	"golang.org/x/exp/slices"
 
@@ -14,8 +15,11 @@ import (
 	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
 
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/logging"
 	"github.com/slsa-framework/slsa-verifier/v2/options"
+	sprovenance "github.com/slsa-framework/slsa-verifier/v2/provenance"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb/keys"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb/slsaprovenance/common"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb/slsaprovenance/iface"
 	v01 "github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb/slsaprovenance/v0.1"
 	v10 "github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb/slsaprovenance/v1.0"
@@ -48,6 +52,7 @@ type Provenance struct {
 	gcloudProv         *gloudProvenance
 	verifiedProvenance *provenance
 	verifiedStatement  iface.Provenance
+	verifiedKey        *keys.Key
 }
 
 func ProvenanceFromBytes(payload []byte) (*Provenance, error) {
@@ -81,9 +86,37 @@ func (p *Provenance) GetVerifiedIntotoStatement() ([]byte, error) {
 	return d, nil
 }
 
+// GetVerifiedProvenance returns the verified provenance, with the source
+// ref taken from the TAG_NAME or BRANCH_NAME substitutions. Source fields
+// missing from the provenance are left empty.
+func (p *Provenance) GetVerifiedProvenance() (*sprovenance.Provenance, error) {
+	content, err := p.GetVerifiedIntotoStatement()
+	if err != nil {
+		return nil, err
+	}
+	prov, err := sprovenance.FromStatement(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Builds without a source, or without a commit, have an empty source
+	// rather than failing the verification.
+	uri, _ := p.verifiedStatement.SourceURI()
+	commit, _ := p.verifiedStatement.SourceCommit()
+	prov.Source = sprovenance.SourceNew(uri, map[string]string{"sha1": commit})
+	// Builds not triggered by a tag or a branch have no ref.
+	if tag, err := p.verifiedStatement.SourceTag(); err == nil {
+		prov.Source.Ref = "refs/tags/" + tag
+	} else if branch, err := p.verifiedStatement.SourceBranch(); err == nil {
+		prov.Source.Ref = "refs/heads/" + branch
+	}
+	return prov, nil
+}
+
 // VerifyMetadata verifies additional metadata contained in the provenance, which is not part
 // of the DSSE payload or headers. It is part of the payload returned by
-// `gcloud artifacts docker images describe image:tag --format json --show-provenance`.
+// `gcloud artifacts docker images describe image:tag --format json --show-provenance`,
+// or by `gcloud artifacts versions describe` for other packages.
 func (p *Provenance) VerifyMetadata(provenanceOpts *options.ProvenanceOpts) error {
 	if err := p.isVerified(); err != nil {
 		return err
@@ -100,13 +133,18 @@ func (p *Provenance) VerifyMetadata(provenanceOpts *options.ProvenanceOpts) erro
 
 	// Note: this could be verified in `VerifySourceURI`, but it is kept here
 	// because it is not part of the DSSE intoto payload.
-	// The `ResourceURI` is container@sha256:hash, without the tag.
-	// We only verify the URI's sha256 for simplicity.
-	if !strings.HasSuffix(prov.ResourceURI, "@sha256:"+provenanceOpts.ExpectedDigest) {
-		return fmt.Errorf("%w: expected resourceUri '%s', got '%s'",
-			serrors.ErrorMismatchHash, provenanceOpts.ExpectedDigest, prov.ResourceURI)
+	// The `ResourceURI` is container@sha256:hash, without the tag, or the
+	// URI of the package file.
+	return verifyResourceDigest(p.verifiedStatement, prov.ResourceURI, provenanceOpts.ExpectedDigest)
+}
+
+// IsImage returns true if the verified provenance is that of an OCI image,
+// rather than of another Artifact Registry package.
+func (p *Provenance) IsImage() (bool, error) {
+	if err := p.isVerified(); err != nil {
+		return false, err
 	}
-	return nil
+	return isImageResource(p.verifiedProvenance.ResourceURI), nil
 }
 
 // VerifySummary verifies the content of the `image_summary` structure
@@ -120,6 +158,13 @@ func (p *Provenance) VerifySummary(provenanceOpts *options.ProvenanceOpts) error
 		return nil
 	}
 
+	// Packages other than images have no image summary.
+	summary := p.gcloudProv.ImageSummary
+	if summary.Digest == "" && summary.FullyQualifiedDigest == "" &&
+		!isImageResource(p.verifiedProvenance.ResourceURI) {
+		return nil
+	}
+
 	// Validate the digest.
 	if p.gcloudProv.ImageSummary.Digest != "sha256:"+provenanceOpts.ExpectedDigest {
 		return fmt.Errorf("%w: expected summary digest '%s', got '%s'",
@@ -198,6 +243,22 @@ func (p *Provenance) validateBuilderID(id string) error {
 			return nil
 		}
 	}
+
+	// Private pools.
+	if _, ok := parsePrivatePool(id); ok {
+		_, version, hasVersion := strings.Cut(id, "@")
+		switch predicateType {
+		case v01.PredicateSLSAProvenance:
+			if hasVersion && slices.Contains(privatePoolVersionsV01, version) {
+				return nil
+			}
+		case v10.PredicateSLSAProvenance:
+			// v1.0 has no builder version.
+			if !hasVersion {
+				return nil
+			}
+		}
+	}
 	return serrors.ErrorInvalidBuilderID
 }
 
@@ -256,6 +317,14 @@ func validateBuildType(builderID utils.TrustedBuilderID, buildType string) error
 	if slices.Contains(v10.BuilderIDs, builderID.String()) {
 		return validatebuildTypeV10(builderID, buildType)
 	}
+
+	// Private pools have the build types of the Google-hosted worker.
+	if _, ok := parsePrivatePool(builderID.String()); ok {
+		if builderID.Version() == "" {
+			return validatebuildTypeV10(builderID, buildType)
+		}
+		return validatebuildTypeV01(builderID, buildType)
+	}
 	return fmt.Errorf("%w: %v", serrors.ErrorInvalidBuilderID, builderID.String())
 }
 
@@ -305,6 +374,20 @@ func (p *Provenance) VerifyBuilder(builderOpts *options.BuilderOpts) (*utils.Tru
 		}
 	}
 
+	// Anyone can create a private pool, so its builder ID must be expected
+	// explicitly, and the provenance signed with a key of its region.
+	if pool, ok := parsePrivatePool(provBuilderID.String()); ok {
+		if builderOpts == nil || builderOpts.ExpectedID == nil {
+			return nil, fmt.Errorf("%w: private pool %q requires an expected builder ID",
+				serrors.ErrorInvalidBuilderID, provBuilderID.Name())
+		}
+		if p.verifiedKey != nil {
+			if err := pool.verifyKey(p.verifiedKey); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	buildType, err := statement.BuildType()
 	if err != nil {
 		return nil, err
@@ -360,6 +443,34 @@ func getAsString(m map[string]interface{}, key string) (string, error) {
 	return ts, nil
 }
 
+// VerifySubjectName verifies that the subject whose digest is expectedHash
+// is named expectedName, which may be a glob pattern.
+func (p *Provenance) VerifySubjectName(expectedHash, expectedName string) error {
+	if err := p.isVerified(); err != nil {
+		return err
+	}
+
+	subjects, err := p.verifiedStatement.Subjects()
+	if err != nil {
+		return err
+	}
+	return utils.VerifySubjectName(subjects, map[string]string{"sha256": expectedHash}, expectedName)
+}
+
+// VerifySubjectDigests verifies that a subject matches the expected digests
+// of other algorithms, e.g. the sha512 of npm packages.
+func (p *Provenance) VerifySubjectDigests(expected map[string]string) error {
+	if err := p.isVerified(); err != nil {
+		return err
+	}
+
+	subjects, err := p.verifiedStatement.Subjects()
+	if err != nil {
+		return err
+	}
+	return utils.VerifySubjectDigests(subjects, expected)
+}
+
 // VerifySubjectDigest verifies the sha256 of the subject.
 func (p *Provenance) VerifySubjectDigest(expectedHash string) error {
 	if err := p.isVerified(); err != nil {
@@ -370,17 +481,23 @@ func (p *Provenance) VerifySubjectDigest(expectedHash string) error {
 	if err != nil {
 		return err
 	}
+	// Subjects of packages may have other digests, e.g. sha512 for npm.
+	hasSHA256 := false
 	for _, subject := range subjects {
 		digestSet := subject.Digest
 		hash, exists := digestSet["sha256"]
 		if !exists {
-			return fmt.Errorf("%w: %s", serrors.ErrorInvalidDssePayload, "no sha256 subject digest")
+			continue
 		}
+		hasSHA256 = true
 
 		if hash == expectedHash {
 			return nil
 		}
 	}
+	if !hasSHA256 {
+		return fmt.Errorf("%w: %s", serrors.ErrorInvalidDssePayload, "no sha256 subject digest")
+	}
 
 	return fmt.Errorf("expected hash '%s' not found: %w", expectedHash, serrors.ErrorMismatchHash)
 }
@@ -430,7 +547,7 @@ func verifySourceURIV10(builderID utils.TrustedBuilderID, provenanceURI, expecte
 }
 
 // Verify source URI in provenance statement.
-func (p *Provenance) VerifySourceURI(expectedSourceURI string, builderID utils.TrustedBuilderID) error {
+func (p *Provenance) VerifySourceURI(ctx context.Context, expectedSourceURI string, builderID utils.TrustedBuilderID) error {
 	if err := p.isVerified(); err != nil {
 		return err
 	}
@@ -452,10 +569,11 @@ func (p *Provenance) VerifySourceURI(expectedSourceURI string, builderID utils.T
 
 	// The build was not configured with a GitHub trigger. Warn.
 	if strings.HasPrefix(uri, "gs://") {
-		fmt.Fprintf(os.Stderr, `This build was not configured with a GitHub trigger `+
+		logging.FromContext(ctx).Warn(`This build was not configured with a GitHub trigger `+
 			`and will not match on an expected, version controlled source URI. `+
 			`See Cloud Build's documentation on building repositories from GitHub: `+
-			`https://cloud.google.com/build/docs/automating-builds/github/build-repos-from-github`)
+			`https://cloud.google.com/build/docs/automating-builds/github/build-repos-from-github`,
+			"sourceURI", uri)
 	}
 
 	predicateType, err := statement.PredicateType()
@@ -473,6 +591,47 @@ func (p *Provenance) VerifySourceURI(expectedSourceURI string, builderID utils.T
 	}
 }
 
+// VerifySourceCommit verifies that the source commit in the provenance
+// matches the expected commit, which may be abbreviated.
+func (p *Provenance) VerifySourceCommit(expectedCommit string) error {
+	if err := p.isVerified(); err != nil {
+		return err
+	}
+
+	commit, err := p.verifiedStatement.SourceCommit()
+	if err != nil {
+		return fmt.Errorf("%w: %v", serrors.ErrorMismatchSourceCommit, err.Error())
+	}
+	return utils.VerifySourceCommit(commit, expectedCommit)
+}
+
+// VerifySourceCheckout verifies that the source commit and tag in the
+// provenance are consistent with a local git checkout, and that the commit
+// is an ancestor of the expected branch, or of the default branch if nil.
+func (p *Provenance) VerifySourceCheckout(path string, expectedBranch *string) error {
+	if err := p.isVerified(); err != nil {
+		return err
+	}
+
+	commit, err := p.verifiedStatement.SourceCommit()
+	if err != nil {
+		return fmt.Errorf("%w: %v", serrors.ErrorMismatchSourceCheckout, err.Error())
+	}
+
+	// Builds not triggered by a tag have no TAG_NAME substitution.
+	tag, err := p.verifiedStatement.SourceTag()
+	if err != nil && !errors.Is(err, common.ErrSubstitution) {
+		return err
+	}
+
+	var branch string
+	if expectedBranch != nil {
+		branch = *expectedBranch
+	}
+
+	return utils.VerifySourceCheckout(path, commit, tag, branch)
+}
+
 func (p *Provenance) VerifyBranch(branch string) error {
 	if err := p.isVerified(); err != nil {
 		return err
@@ -510,6 +669,57 @@ func (p *Provenance) VerifyVersionedTag(expectedTag string) error {
 	return utils.VerifyVersionedTag(provenanceTag, expectedTag)
 }
 
+// VerifyBuildSubstitutions verifies the build substitutions, built-in
+// ones like TRIGGER_NAME and user-defined ones like _DEPLOY_ENV.
+func (p *Provenance) VerifyBuildSubstitutions(expected map[string]string) error {
+	if err := p.isVerified(); err != nil {
+		return err
+	}
+
+	substitutions, err := p.verifiedStatement.Substitutions()
+	if err != nil {
+		return fmt.Errorf("%w: %v", serrors.ErrorMismatchBuildSubstitution, err)
+	}
+
+	for k, v := range expected {
+		value, ok := substitutions[k]
+		if !ok {
+			return fmt.Errorf("%w: no substitution %q", serrors.ErrorMismatchBuildSubstitution, k)
+		}
+		if value != v {
+			return fmt.Errorf("%w: substitution %q: expected %q, got '%v'",
+				serrors.ErrorMismatchBuildSubstitution, k, v, value)
+		}
+	}
+	return nil
+}
+
+// VerifyTrigger verifies the name or ID of the trigger that started the
+// build.
+func (p *Provenance) VerifyTrigger(expected string) error {
+	if err := p.isVerified(); err != nil {
+		return err
+	}
+
+	var triggers []string
+	if substitutions, err := p.verifiedStatement.Substitutions(); err == nil {
+		if name, ok := substitutions["TRIGGER_NAME"].(string); ok && name != "" {
+			triggers = append(triggers, name)
+		}
+	}
+	if id, err := p.verifiedStatement.TriggerID(); err == nil {
+		triggers = append(triggers, id)
+	}
+	if len(triggers) == 0 {
+		return fmt.Errorf("%w: no trigger in provenance", serrors.ErrorMismatchBuildTrigger)
+	}
+	if !slices.Contains(triggers, expected) {
+		return fmt.Errorf("%w: expected %q, got %q",
+			serrors.ErrorMismatchBuildTrigger, expected, triggers)
+	}
+	return nil
+}
+
 func (p *Provenance) getTag() (string, error) {
 	if err := p.isVerified(); err != nil {
 		return "", err
@@ -519,8 +729,8 @@ func (p *Provenance) getTag() (string, error) {
 }
 
 // verifySignatures iterates over all the signatures in the DSSE and verifies them.
-// It succeeds if one of them can be verified.
-func (p *Provenance) verifySignatures(prov *provenance) error {
+// It succeeds if one of them can be verified with a key of the key set.
+func (p *Provenance) verifySignatures(ctx context.Context, prov *provenance, keySet *keys.KeySet) error {
 	// Verify the envelope type. It should be an intoto type.
 	if prov.Envelope.PayloadType != intoto.PayloadType {
 		return fmt.Errorf("%w: expected payload type '%s', got %s",
@@ -532,7 +742,6 @@ func (p *Provenance) verifySignatures(prov *provenance) error {
 		return err
 	}
 
-	payloadHash := sha256.Sum256(payload)
 	// Verify the signatures.
 	if len(prov.Envelope.Signatures) == 0 {
 		return fmt.Errorf("%w: no signatures found in envelope", serrors.ErrorNoValidSignature)
@@ -541,63 +750,43 @@ func (p *Provenance) verifySignatures(prov *provenance) error {
 	var errs []error
 
 	for _, sig := range prov.Envelope.Signatures {
-		var keyName string
+		key, ok := keySet.Get(sig.KeyID)
+		if !ok {
+			continue
+		}
 
-		// Global PAE keys.
-		if sig.KeyID == keys.V10GlobalPAEKeyID || sig.KeyID == keys.V01GlobalPAEKeyID {
-			// Global key for v1.0 or v0.1.
-			// If the signature is signed with the global PAE key, use a DSSE verifier
+		switch key.Format {
+		case keys.FormatPAE:
+			// Global keys.
+			// If the signature is signed with a PAE key, use a DSSE verifier
 			// to verify the DSSE/PAE-encoded signature.
-			globalPaeKey, err := keys.NewGlobalPAEKey(sig.KeyID)
-			if err != nil {
-				errs = append(errs, err)
-				continue
-			}
-
-			err = globalPaeKey.VerifyPAESignature(&prov.Envelope)
-			if err != nil {
-				errs = append(errs, fmt.Errorf("%w: key %q", err, globalPaeKey.Name()))
+			if err := key.VerifyPAESignature(ctx, &prov.Envelope); err != nil {
+				errs = append(errs, fmt.Errorf("%w: key %q", err, key.Name()))
 				continue
 			}
-			// Success.
-			keyName = globalPaeKey.Name()
-		} else if match := v01.RegionalKeyRegex.FindStringSubmatch(sig.KeyID); len(match) == 2 {
-			// Regional key for v0.1.
+		default:
+			// Regional keys for v0.1.
 			// If the signature is signed with a regional key, verify the legacy
 			// signing which is over the envelope (not PAE-encoded).
-			pubKey, err := keys.NewPublicKey(match[1])
-			if err != nil {
-				errs = append(errs, err)
-				continue
-			}
-
-			// Decode the signature.
 			rsig, err := utils.DecodeSignature(sig.Sig)
 			if err != nil {
 				errs = append(errs, err)
 				continue
 			}
 
-			// Verify the signature.
-			err = pubKey.VerifySignature(payloadHash, rsig)
-			if err != nil {
+			if err := key.VerifySignature(payload, rsig); err != nil {
 				errs = append(errs, err)
 				continue
 			}
-			// Success.
-			keyName = match[1]
-		} else {
-			continue
 		}
 
 		// Success.
 		var stmt iface.Provenance
-		if sig.KeyID == keys.V10GlobalPAEKeyID {
+		if key.ProvenanceVersion == keys.ProvenanceV10 {
 			// v1.0 provenance.
 			stmt, err = v10.New(payload)
 		} else {
 			// v0.1 provenance.
-			// kkeys.V01GlobalPAEKeyID or regional key.
 			stmt, err = v01.New(payload)
 		}
 		if err != nil {
@@ -605,17 +794,54 @@ func (p *Provenance) verifySignatures(prov *provenance) error {
 			continue
 		}
 
+		// Verify that the key was valid when the provenance was signed, so
+		// that keys rotated out still verify the provenance they signed.
+		if err := key.ValidAt(signingTime(payload)); err != nil {
+			logging.FromContext(ctx).Warn(err.Error(), "key", key.Name())
+			errs = append(errs, err)
+			continue
+		}
+
 		p.verifiedStatement = stmt
 		p.verifiedProvenance = prov
-		fmt.Fprintf(os.Stderr, "Verification succeeded with key %q\n", keyName)
+		p.verifiedKey = key
+		logging.FromContext(ctx).Info(fmt.Sprintf("Verification succeeded with key %q", key.Name()),
+			"key", key.Name())
 		return nil
 	}
 
 	return fmt.Errorf("%w: %v", serrors.ErrorNoValidSignature, errs)
 }
 
-// VerifySignature verifiers the signature for a provenance.
-func (p *Provenance) VerifySignature() error {
+// signingTime returns the time the provenance was signed at: the end of the
+// build, or its start, as recorded in the payload. The time is signed by the
+// key whose validity it is checked against, and so trusted as much as that
+// key: a compromised key must be removed from the key set rather than
+// expired. Times in the future, and provenance without build times, are
+// checked at the current time.
+func signingTime(payload []byte) time.Time {
+	now := time.Now()
+	prov, err := sprovenance.FromStatement(payload)
+	switch {
+	case err != nil:
+	case prov.FinishedOn != nil && prov.FinishedOn.Before(now):
+		return *prov.FinishedOn
+	case prov.FinishedOn == nil && prov.StartedOn != nil && prov.StartedOn.Before(now):
+		return *prov.StartedOn
+	}
+	return now
+}
+
+// VerifySignature verifiers the signature for a provenance with the keys of
+// the key set, or with the embedded keys if the key set is nil.
+func (p *Provenance) VerifySignature(ctx context.Context, keySet *keys.KeySet) error {
+	if keySet == nil {
+		var err error
+		if keySet, err = keys.DefaultKeySet(); err != nil {
+			return err
+		}
+	}
+
 	if len(p.gcloudProv.ProvenanceSummary.Provenance) == 0 {
 		return fmt.Errorf("%w: no provenance found", serrors.ErrorInvalidDssePayload)
 	}
@@ -623,7 +849,7 @@ func (p *Provenance) VerifySignature() error {
 	// Iterate over all provenances available.
 	var errs []error
 	for i := range p.gcloudProv.ProvenanceSummary.Provenance {
-		err := p.verifySignatures(&p.gcloudProv.ProvenanceSummary.Provenance[i])
+		err := p.verifySignatures(ctx, &p.gcloudProv.ProvenanceSummary.Provenance[i], keySet)
 		if err != nil {
 			errs = append(errs, err)
 			continue
@@ -1,17 +1,21 @@
 package gcb
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
 	"github.com/slsa-framework/slsa-verifier/v2/options"
+	sprovenance "github.com/slsa-framework/slsa-verifier/v2/provenance"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb/keys"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb/slsaprovenance/iface"
 	v01 "github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb/slsaprovenance/v0.1"
 	v10 "github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb/slsaprovenance/v1.0"
@@ -59,6 +63,7 @@ func Test_VerifyBuilder(t *testing.T) {
 		path      string
 		builderID string
 		version   string
+		keyRegion string
 		expected  error
 	}{
 		// v0.1 provenance.
@@ -187,6 +192,56 @@ func Test_VerifyBuilder(t *testing.T) {
 			version:   versionV10,
 			expected:  serrors.ErrorMismatchBuilderID,
 		},
+		// Private pools.
+		{
+			name:      "v0.1 private pool",
+			path:      "./testdata/gcloud-container-private-pool.json",
+			builderID: "https://cloudbuild.googleapis.com/projects/my-project/locations/us-central1/workerPools/my-pool@v0.3",
+			keyRegion: "us-central1",
+		},
+		{
+			name:      "v0.1 private pool - name only",
+			path:      "./testdata/gcloud-container-private-pool.json",
+			builderID: "https://cloudbuild.googleapis.com/projects/my-project/locations/us-central1/workerPools/my-pool",
+			keyRegion: "global",
+		},
+		{
+			name:     "v0.1 private pool - no expected builder",
+			path:     "./testdata/gcloud-container-private-pool.json",
+			expected: serrors.ErrorInvalidBuilderID,
+		},
+		{
+			name:      "v0.1 private pool - hosted worker expected",
+			path:      "./testdata/gcloud-container-private-pool.json",
+			builderID: "https://cloudbuild.googleapis.com/GoogleHostedWorker",
+			expected:  serrors.ErrorMismatchBuilderID,
+		},
+		{
+			name:      "v0.1 private pool - mismatch pool",
+			path:      "./testdata/gcloud-container-private-pool.json",
+			builderID: "https://cloudbuild.googleapis.com/projects/my-project/locations/us-central1/workerPools/other-pool",
+			expected:  serrors.ErrorMismatchBuilderID,
+		},
+		{
+			name:      "v0.1 private pool - key of other region",
+			path:      "./testdata/gcloud-container-private-pool.json",
+			builderID: "https://cloudbuild.googleapis.com/projects/my-project/locations/us-central1/workerPools/my-pool",
+			keyRegion: "europe-west1",
+			expected:  serrors.ErrorMismatchBuilderID,
+		},
+		{
+			name:      "v1.0 private pool",
+			path:      "./testdata/v1.0-gcloud-container-private-pool.json",
+			builderID: "https://cloudbuild.googleapis.com/projects/my-project/locations/us-central1/workerPools/my-pool",
+			version:   versionV10,
+			keyRegion: "global",
+		},
+		{
+			name:     "v1.0 private pool - no expected builder",
+			path:     "./testdata/v1.0-gcloud-container-private-pool.json",
+			version:  versionV10,
+			expected: serrors.ErrorInvalidBuilderID,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt // Re-initializing variable so it is not changed while executing the closure below
@@ -209,6 +264,9 @@ func Test_VerifyBuilder(t *testing.T) {
 			if err := setStatement(prov, tt.version); err != nil {
 				panic(fmt.Errorf("setStatement: %w", err))
 			}
+			if tt.keyRegion != "" {
+				prov.verifiedKey = &keys.Key{Region: tt.keyRegion}
+			}
 
 			var builderOpts options.BuilderOpts
 			if tt.builderID != "" {
@@ -302,6 +360,35 @@ func Test_validateBuildType(t *testing.T) {
 			buildType: "https://cloud.google.com/build/gcb-buildtypes/google-worker/v0",
 			expected:  serrors.ErrorInvalidBuildType,
 		},
+		// Private pools.
+		{
+			name:      "valid private pool v0.3 recipe type",
+			builderID: "https://cloudbuild.googleapis.com/projects/p/locations/us-central1/workerPools/w@v0.3",
+			buildType: "https://cloudbuild.googleapis.com/CloudBuildSteps@v0.1",
+		},
+		{
+			name:      "invalid private pool v0.3 recipe type",
+			builderID: "https://cloudbuild.googleapis.com/projects/p/locations/us-central1/workerPools/w@v0.3",
+			buildType: "https://cloudbuild.googleapis.com/GoogleHostedWorker@v0.3",
+			expected:  serrors.ErrorInvalidRecipe,
+		},
+		{
+			name:      "valid private pool v1.0",
+			builderID: "https://cloudbuild.googleapis.com/projects/p/locations/us-central1/workerPools/w",
+			buildType: "https://cloud.google.com/build/gcb-buildtypes/google-worker/v1",
+		},
+		{
+			name:      "incorrect private pool buildType v1.0",
+			builderID: "https://cloudbuild.googleapis.com/projects/p/locations/us-central1/workerPools/w",
+			buildType: "https://cloud.google.com/build/gcb-buildtypes/google-worker/v0",
+			expected:  serrors.ErrorInvalidBuildType,
+		},
+		{
+			name:      "invalid private pool resource name",
+			builderID: "https://cloudbuild.googleapis.com/projects/p/workerPools/w",
+			buildType: "https://cloud.google.com/build/gcb-buildtypes/google-worker/v1",
+			expected:  serrors.ErrorInvalidBuilderID,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt // Re-initializing variable so it is not changed while executing the closure below
@@ -580,7 +667,7 @@ func Test_VerifySourceURI(t *testing.T) {
 			if err != nil {
 				panic(fmt.Errorf("BuilderIDNew: %w", err))
 			}
-			err = prov.VerifySourceURI(tt.source, *builderID)
+			err = prov.VerifySourceURI(context.Background(), tt.source, *builderID)
 			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
 				t.Errorf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
 			}
@@ -593,6 +680,7 @@ func Test_VerifySignature(t *testing.T) {
 	tests := []struct {
 		name     string
 		path     string
+		keySet   string
 		expected error
 	}{
 		// v0.1 provenance.
@@ -690,6 +778,41 @@ func Test_VerifySignature(t *testing.T) {
 			name: "signature global v0.1 pae valid only",
 			path: "./testdata/v1.0-gcloud-container-github-v0.1-valid.json",
 		},
+		// External key sets.
+		{
+			name:   "key set v1.0 valid",
+			path:   "./testdata/v1.0-gcloud-container-github-single.json",
+			keySet: "./testdata/keys/valid.json",
+		},
+		{
+			name:   "key set v0.1 regional key rotated out after the build",
+			path:   "./testdata/gcloud-container-github.json",
+			keySet: "./testdata/keys/valid.json",
+		},
+		{
+			name:     "key set missing key",
+			path:     "./testdata/gcloud-container-global-pae-signing-key-successful.json",
+			keySet:   "./testdata/keys/valid.json",
+			expected: serrors.ErrorNoValidSignature,
+		},
+		{
+			name:     "key set key expired before the build",
+			path:     "./testdata/v1.0-gcloud-container-github-single.json",
+			keySet:   "./testdata/keys/expired.json",
+			expected: serrors.ErrorNoValidSignature,
+		},
+		{
+			name:     "key set key not yet valid at the build",
+			path:     "./testdata/gcloud-container-github.json",
+			keySet:   "./testdata/keys/not-yet-valid.json",
+			expected: serrors.ErrorNoValidSignature,
+		},
+		{
+			name:     "key set mismatch provenance version",
+			path:     "./testdata/v1.0-gcloud-container-github-single.json",
+			keySet:   "./testdata/keys/wrong-version.json",
+			expected: serrors.ErrorNoValidSignature,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt // Re-initializing variable so it is not changed while executing the closure below
@@ -706,7 +829,15 @@ func Test_VerifySignature(t *testing.T) {
 				panic(fmt.Errorf("ProvenanceFromBytes: %w", err))
 			}
 
-			err = prov.VerifySignature()
+			var keySet *keys.KeySet
+			if tt.keySet != "" {
+				keySet, err = keys.KeySetFromPath(tt.keySet)
+				if err != nil {
+					panic(fmt.Errorf("keys.KeySetFromPath: %w", err))
+				}
+			}
+
+			err = prov.VerifySignature(context.Background(), keySet)
 			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
 				t.Errorf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
 			}
@@ -791,6 +922,19 @@ func Test_VerifySubjectDigest(t *testing.T) {
 			version:  versionV10,
 			expected: serrors.ErrorMismatchHash,
 		},
+		// Artifact Registry packages.
+		{
+			name:    "maven package",
+			path:    "./testdata/v1.0-gcloud-maven.json",
+			hash:    "5f1c3e5a7b9d1f3e5a7c9e1b3d5f7a9c1e3b5d7f9a1c3e5b7d9f1a3c5e7b9d1f",
+			version: versionV10,
+		},
+		{
+			name:    "npm package with sha512-only subject",
+			path:    "./testdata/v1.0-gcloud-npm.json",
+			hash:    "3a7c1e5b9d3f7a1c5e9b3d7f1a5c9e3b7d1f5a9c3e7b1d5f9a3c7e1b5d9f3a7c",
+			version: versionV10,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt // Re-initializing variable so it is not changed while executing the closure below
@@ -822,6 +966,83 @@ func Test_VerifySubjectDigest(t *testing.T) {
 	}
 }
 
+func Test_VerifySubjectName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		path     string
+		hash     string
+		subject  string
+		version  string
+		expected error
+	}{
+		// v0.1 provenance.
+		{
+			name:    "match name",
+			path:    "./testdata/gcloud-container-github.json",
+			hash:    "1a033b002f89ed2b8ea733162497fb70f1a4049a7f8602d6a33682b4ad9921fd",
+			subject: "https://us-west2-docker.pkg.dev/gosst-scare-sandbox/quickstart-docker-repo/quickstart-image:v14",
+		},
+		{
+			name:    "match glob",
+			path:    "./testdata/gcloud-container-github.json",
+			hash:    "1a033b002f89ed2b8ea733162497fb70f1a4049a7f8602d6a33682b4ad9921fd",
+			subject: "https://us-west2-docker.pkg.dev/gosst-scare-sandbox/quickstart-docker-repo/quickstart-image:*",
+		},
+		{
+			name:     "mismatch name",
+			path:     "./testdata/gcloud-container-github.json",
+			hash:     "1a033b002f89ed2b8ea733162497fb70f1a4049a7f8602d6a33682b4ad9921fd",
+			subject:  "https://us-west2-docker.pkg.dev/gosst-scare-sandbox/quickstart-docker-repo/other-image:v14",
+			expected: serrors.ErrorMismatchSubjectName,
+		},
+		// v1.0 provenance.
+		{
+			name:    "v1.0 match second subject",
+			path:    "./testdata/v1.0-gcloud-container-github.json",
+			hash:    "7e9b6e7ba2842c91cf49f3e214d04a7a496f8214356f41d81a6e6dcad11f11e3",
+			subject: "https://us-central1-docker.pkg.dev/argo-local-khalk/khalk-docker-ar/prod-prov-image:latest",
+			version: versionV10,
+		},
+		{
+			name:     "v1.0 mismatch name",
+			path:     "./testdata/v1.0-gcloud-container-github.json",
+			hash:     "7e9b6e7ba2842c91cf49f3e214d04a7a496f8214356f41d81a6e6dcad11f11e3",
+			subject:  "https://us-central1-docker.pkg.dev/argo-local-khalk/khalk-docker-ar/prod-prov-image:v1",
+			version:  versionV10,
+			expected: serrors.ErrorMismatchSubjectName,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := os.ReadFile(tt.path)
+			if err != nil {
+				panic(fmt.Errorf("os.ReadFile: %w", err))
+			}
+
+			prov, err := ProvenanceFromBytes(content)
+			if err != nil {
+				panic(fmt.Errorf("ProvenanceFromBytes: %w", err))
+			}
+
+			if tt.version == "" {
+				tt.version = versionV01
+			}
+			if err := setStatement(prov, tt.version); err != nil {
+				panic(fmt.Errorf("setStatement: %w", err))
+			}
+
+			err = prov.VerifySubjectName(tt.hash, tt.subject)
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Errorf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
+
 func Test_VerifySummary(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -868,6 +1089,13 @@ func Test_VerifySummary(t *testing.T) {
 			version:  versionV10,
 			expected: serrors.ErrorMismatchHash,
 		},
+		// Artifact Registry packages.
+		{
+			name:    "maven package without image summary",
+			path:    "./testdata/v1.0-gcloud-maven.json",
+			hash:    "0b2e9bd4a1f0df4c7a6f3e1c0a1e0f0c9f5b0c3a7a8b3e1d2c4f6a8b0c2d4e6f",
+			version: versionV10,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt // Re-initializing variable so it is not changed while executing the closure below
@@ -950,6 +1178,26 @@ func Test_VerifyMetadata(t *testing.T) {
 			version:  versionV10,
 			expected: serrors.ErrorInvalidFormat,
 		},
+		// Artifact Registry packages.
+		{
+			name:    "maven package",
+			path:    "./testdata/v1.0-gcloud-maven.json",
+			hash:    "0b2e9bd4a1f0df4c7a6f3e1c0a1e0f0c9f5b0c3a7a8b3e1d2c4f6a8b0c2d4e6f",
+			version: versionV10,
+		},
+		{
+			name:     "maven package - digest of other subject",
+			path:     "./testdata/v1.0-gcloud-maven.json",
+			hash:     "5f1c3e5a7b9d1f3e5a7c9e1b3d5f7a9c1e3b5d7f9a1c3e5b7d9f1a3c5e7b9d1f",
+			version:  versionV10,
+			expected: serrors.ErrorMismatchHash,
+		},
+		{
+			name:    "npm package",
+			path:    "./testdata/v1.0-gcloud-npm.json",
+			hash:    "3a7c1e5b9d3f7a1c5e9b3d7f1a5c9e3b7d1f5a9c3e7b1d5f9a3c7e1b5d9f3a7c",
+			version: versionV10,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt // Re-initializing variable so it is not changed while executing the closure below
@@ -1200,6 +1448,259 @@ func Test_VerifyBranch(t *testing.T) {
 	}
 }
 
+func Test_VerifySourceCommit(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		path    string
+		commit  string
+		version string
+		err     error
+	}{
+		// v0.1 provenance.
+		{
+			name:   "match commit",
+			path:   "./testdata/gcloud-container-tag.json",
+			commit: "c750fd73a1669b095df7c74da9f1ff2032f926c9",
+		},
+		{
+			name:   "match abbreviated commit",
+			path:   "./testdata/gcloud-container-tag.json",
+			commit: "c750fd73a166",
+		},
+		{
+			name:   "no match commit",
+			path:   "./testdata/gcloud-container-tag.json",
+			commit: "d750fd73a1669b095df7c74da9f1ff2032f926c9",
+			err:    serrors.ErrorMismatchSourceCommit,
+		},
+		{
+			name:   "match commit in material uri",
+			path:   "./testdata/gcloud-container-github.json",
+			commit: "fbbb98765e85ad464302dc5977968104d36e455e",
+		},
+		{
+			name:   "no match commit in material uri",
+			path:   "./testdata/gcloud-container-github.json",
+			commit: "c750fd73a1669b095df7c74da9f1ff2032f926c9",
+			err:    serrors.ErrorMismatchSourceCommit,
+		},
+		{
+			name:   "commit too short",
+			path:   "./testdata/gcloud-container-tag.json",
+			commit: "c750fd7",
+			err:    serrors.ErrorInvalidFormat,
+		},
+		// v1.0 provenance.
+		{
+			name:    "v1.0 match commit",
+			path:    "./testdata/v1.0-gcloud-container-github.json",
+			commit:  "2ce3f90facdb51aeb950d5bc641e981be61fdf48",
+			version: versionV10,
+		},
+		{
+			name:    "v1.0 match abbreviated commit",
+			path:    "./testdata/v1.0-gcloud-container-github.json",
+			commit:  "2CE3F90FACDB",
+			version: versionV10,
+		},
+		{
+			name:    "v1.0 no match commit",
+			path:    "./testdata/v1.0-gcloud-container-github.json",
+			commit:  "c750fd73a1669b095df7c74da9f1ff2032f926c9",
+			version: versionV10,
+			err:     serrors.ErrorMismatchSourceCommit,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := os.ReadFile(tt.path)
+			if err != nil {
+				panic(fmt.Errorf("os.ReadFile: %w", err))
+			}
+
+			prov, err := ProvenanceFromBytes(content)
+			if err != nil {
+				panic(fmt.Errorf("ProvenanceFromBytes: %w", err))
+			}
+
+			if tt.version == "" {
+				tt.version = versionV01
+			}
+			if err := setStatement(prov, tt.version); err != nil {
+				panic(fmt.Errorf("setStatement: %w", err))
+			}
+
+			err = prov.VerifySourceCommit(tt.commit)
+			if !cmp.Equal(err, tt.err, cmpopts.EquateErrors()) {
+				t.Errorf(cmp.Diff(err, tt.err, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
+
+func Test_GetVerifiedProvenance(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		path          string
+		version       string
+		predicateType string
+		builderID     string
+		// noMaterials removes the materials of v0.1 provenance.
+		noMaterials bool
+		source      sprovenance.Source
+	}{
+		{
+			name:          "v0.1 tag",
+			path:          "./testdata/gcloud-container-tag.json",
+			predicateType: sprovenance.PredicateSLSAProvenanceV01,
+			builderID:     "https://cloudbuild.googleapis.com/GoogleHostedWorker@v0.3",
+			source: sprovenance.Source{
+				URI:    "git+https://github.com/slsa-framework/example-package",
+				Ref:    "refs/tags/v33.0.4",
+				Commit: "c750fd73a1669b095df7c74da9f1ff2032f926c9",
+			},
+		},
+		{
+			name:          "v0.1 no ref",
+			path:          "./testdata/gcloud-container-github.json",
+			predicateType: sprovenance.PredicateSLSAProvenanceV01,
+			builderID:     "https://cloudbuild.googleapis.com/GoogleHostedWorker@v0.2",
+			source: sprovenance.Source{
+				URI:    "https://github.com/laurentsimon/gcb-tests/commit/fbbb98765e85ad464302dc5977968104d36e455e",
+				Commit: "fbbb98765e85ad464302dc5977968104d36e455e",
+			},
+		},
+		{
+			name:          "v0.1 no source",
+			path:          "./testdata/gcloud-container-tag.json",
+			predicateType: sprovenance.PredicateSLSAProvenanceV01,
+			builderID:     "https://cloudbuild.googleapis.com/GoogleHostedWorker@v0.3",
+			noMaterials:   true,
+			source: sprovenance.Source{
+				Ref: "refs/tags/v33.0.4",
+			},
+		},
+		{
+			name:          "v1.0 branch",
+			path:          "./testdata/v1.0-gcloud-container-github.json",
+			version:       versionV10,
+			predicateType: sprovenance.PredicateSLSAProvenanceV1,
+			builderID:     "https://cloudbuild.googleapis.com/GoogleHostedWorker",
+			source: sprovenance.Source{
+				URI:    "git+https://github.com/khalkie/gcb-prod-prov",
+				Ref:    "refs/heads/main",
+				Commit: "2ce3f90facdb51aeb950d5bc641e981be61fdf48",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := os.ReadFile(tt.path)
+			if err != nil {
+				panic(fmt.Errorf("os.ReadFile: %w", err))
+			}
+
+			prov, err := ProvenanceFromBytes(content)
+			if err != nil {
+				panic(fmt.Errorf("ProvenanceFromBytes: %w", err))
+			}
+
+			if tt.version == "" {
+				tt.version = versionV01
+			}
+			if err := setStatement(prov, tt.version); err != nil {
+				panic(fmt.Errorf("setStatement: %w", err))
+			}
+			if tt.noMaterials {
+				prov.verifiedStatement.(*v01.Provenance).Pred.Materials = nil
+			}
+
+			verified, err := prov.GetVerifiedProvenance()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if verified.PredicateType != tt.predicateType {
+				t.Errorf("unexpected predicate type %q", verified.PredicateType)
+			}
+			if verified.BuilderID != tt.builderID {
+				t.Errorf("unexpected builder ID %q", verified.BuilderID)
+			}
+			if diff := cmp.Diff(tt.source, verified.Source); diff != "" {
+				t.Errorf("unexpected source (-want +got):\n%s", diff)
+			}
+			if len(verified.Subjects) == 0 {
+				t.Errorf("no subjects")
+			}
+			if verified.StartedOn == nil {
+				t.Errorf("no start time")
+			}
+		})
+	}
+}
+
+func Test_VerifySourceCheckout(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		path    string
+		version string
+		err     error
+	}{
+		{
+			name: "v0.1 tag not a repository",
+			path: "./testdata/gcloud-container-tag.json",
+			err:  serrors.ErrorInvalidSourceCheckout,
+		},
+		{
+			name: "v0.1 no tag not a repository",
+			path: "./testdata/gcloud-container-github.json",
+			err:  serrors.ErrorInvalidSourceCheckout,
+		},
+		{
+			name:    "v1.0 not a repository",
+			path:    "./testdata/v1.0-gcloud-container-github.json",
+			version: versionV10,
+			err:     serrors.ErrorInvalidSourceCheckout,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := os.ReadFile(tt.path)
+			if err != nil {
+				panic(fmt.Errorf("os.ReadFile: %w", err))
+			}
+
+			prov, err := ProvenanceFromBytes(content)
+			if err != nil {
+				panic(fmt.Errorf("ProvenanceFromBytes: %w", err))
+			}
+
+			if tt.version == "" {
+				tt.version = versionV01
+			}
+			if err := setStatement(prov, tt.version); err != nil {
+				panic(fmt.Errorf("setStatement: %w", err))
+			}
+
+			err = prov.VerifySourceCheckout(t.TempDir(), nil)
+			if !cmp.Equal(err, tt.err, cmpopts.EquateErrors()) {
+				t.Errorf(cmp.Diff(err, tt.err, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
+
 func Test_VerifyTag(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -1314,6 +1815,175 @@ func Test_VerifyTag(t *testing.T) {
 	}
 }
 
+func Test_VerifyBuildSubstitutions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		path          string
+		substitutions map[string]string
+		version       string
+		err           error
+	}{
+		// v0.1 provenance.
+		{
+			name: "match substitutions",
+			path: "./testdata/gcloud-container-tag.json",
+			substitutions: map[string]string{
+				"TRIGGER_NAME": "push-tag",
+				"REPO_NAME":    "example-package",
+				"_IMAGE_NAME":  "slsa-tooling/example-package-repo/e2e-gcb-tag-main-annotated-slsa3",
+			},
+		},
+		{
+			name:          "mismatch user substitution",
+			path:          "./testdata/gcloud-container-tag.json",
+			substitutions: map[string]string{"_IMAGE_NAME": "slsa-tooling/other"},
+			err:           serrors.ErrorMismatchBuildSubstitution,
+		},
+		{
+			name:          "missing substitution",
+			path:          "./testdata/gcloud-container-tag.json",
+			substitutions: map[string]string{"_DEPLOY_ENV": "prod"},
+			err:           serrors.ErrorMismatchBuildSubstitution,
+		},
+		{
+			name:          "not string substitution",
+			path:          "./testdata/gcloud-container-tag-notstring.json",
+			substitutions: map[string]string{"TAG_NAME": "1234"},
+			err:           serrors.ErrorMismatchBuildSubstitution,
+		},
+		{
+			name:          "no substitutions field",
+			path:          "./testdata/gcloud-container-github.json",
+			substitutions: map[string]string{"TRIGGER_NAME": "push-tag"},
+			err:           serrors.ErrorMismatchBuildSubstitution,
+		},
+		// v1.0 provenance.
+		{
+			name: "v1.0 match substitutions",
+			path: "./testdata/v1.0-gcloud-container-github-tag.json",
+			substitutions: map[string]string{
+				"TRIGGER_NAME":              "sample-trigger-1",
+				"TRIGGER_BUILD_CONFIG_PATH": "cloudbuild.yaml",
+			},
+			version: versionV10,
+		},
+		{
+			name:          "v1.0 mismatch substitution",
+			path:          "./testdata/v1.0-gcloud-container-github-tag.json",
+			substitutions: map[string]string{"REPO_NAME": "other"},
+			version:       versionV10,
+			err:           serrors.ErrorMismatchBuildSubstitution,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := os.ReadFile(tt.path)
+			if err != nil {
+				panic(fmt.Errorf("os.ReadFile: %w", err))
+			}
+
+			prov, err := ProvenanceFromBytes(content)
+			if err != nil {
+				panic(fmt.Errorf("ProvenanceFromBytes: %w", err))
+			}
+
+			if tt.version == "" {
+				tt.version = versionV01
+			}
+			if err := setStatement(prov, tt.version); err != nil {
+				panic(fmt.Errorf("setStatement: %w", err))
+			}
+
+			err = prov.VerifyBuildSubstitutions(tt.substitutions)
+			if !cmp.Equal(err, tt.err, cmpopts.EquateErrors()) {
+				t.Errorf(cmp.Diff(err, tt.err, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
+
+func Test_VerifyTrigger(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		path    string
+		trigger string
+		version string
+		err     error
+	}{
+		// v0.1 provenance.
+		{
+			name:    "match trigger name",
+			path:    "./testdata/gcloud-container-tag.json",
+			trigger: "push-tag",
+		},
+		{
+			name:    "mismatch trigger name",
+			path:    "./testdata/gcloud-container-tag.json",
+			trigger: "push-branch",
+			err:     serrors.ErrorMismatchBuildTrigger,
+		},
+		{
+			name:    "no trigger",
+			path:    "./testdata/gcloud-container-github.json",
+			trigger: "push-tag",
+			err:     serrors.ErrorMismatchBuildTrigger,
+		},
+		// v1.0 provenance.
+		{
+			name:    "v1.0 match trigger name",
+			path:    "./testdata/v1.0-gcloud-container-github-tag.json",
+			trigger: "sample-trigger-1",
+			version: versionV10,
+		},
+		{
+			name:    "v1.0 match trigger id",
+			path:    "./testdata/v1.0-gcloud-container-github-tag.json",
+			trigger: "15e57958-19b3-4a52-a052-6906244088ce",
+			version: versionV10,
+		},
+		{
+			name:    "v1.0 mismatch trigger",
+			path:    "./testdata/v1.0-gcloud-container-github-tag.json",
+			trigger: "sample-trigger-2",
+			version: versionV10,
+			err:     serrors.ErrorMismatchBuildTrigger,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := os.ReadFile(tt.path)
+			if err != nil {
+				panic(fmt.Errorf("os.ReadFile: %w", err))
+			}
+
+			prov, err := ProvenanceFromBytes(content)
+			if err != nil {
+				panic(fmt.Errorf("ProvenanceFromBytes: %w", err))
+			}
+
+			if tt.version == "" {
+				tt.version = versionV01
+			}
+			if err := setStatement(prov, tt.version); err != nil {
+				panic(fmt.Errorf("setStatement: %w", err))
+			}
+
+			err = prov.VerifyTrigger(tt.trigger)
+			if !cmp.Equal(err, tt.err, cmpopts.EquateErrors()) {
+				t.Errorf(cmp.Diff(err, tt.err, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
+
 func Test_VerifyVersionedTag(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -1513,3 +2183,68 @@ func Test_VerifyVersionedTag(t *testing.T) {
 		})
 	}
 }
+
+func Test_signingTime(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2023, 8, 8, 18, 30, 0, 0, time.UTC)
+	finished := time.Date(2023, 8, 8, 18, 40, 0, 0, time.UTC)
+	future := time.Now().Add(24 * time.Hour)
+	statement := func(metadata string) []byte {
+		return []byte(`{"_type":"https://in-toto.io/Statement/v1",` +
+			`"predicateType":"https://slsa.dev/provenance/v1",` +
+			`"subject":[{"name":"app","digest":{"sha256":"abcd"}}],` +
+			`"predicate":{"buildDefinition":{"buildType":"https://cloud.google.com/build/gcb-buildtypes/google-worker/v1"},` +
+			`"runDetails":{"builder":{"id":"https://cloudbuild.googleapis.com/GoogleHostedWorker"},` +
+			`"metadata":{` + metadata + `}}}}`)
+	}
+
+	tests := []struct {
+		name    string
+		payload []byte
+		// expected is the expected time, or the zero time for the current
+		// time.
+		expected time.Time
+	}{
+		{
+			name:     "finish time",
+			payload:  statement(`"startedOn":"2023-08-08T18:30:00Z","finishedOn":"2023-08-08T18:40:00Z"`),
+			expected: finished,
+		},
+		{
+			name:     "start time",
+			payload:  statement(`"startedOn":"2023-08-08T18:30:00Z"`),
+			expected: started,
+		},
+		{
+			name:    "finish time in the future",
+			payload: statement(`"finishedOn":"` + future.Format(time.RFC3339) + `"`),
+		},
+		{
+			name:    "no build time",
+			payload: statement(``),
+		},
+		{
+			name:    "invalid payload",
+			payload: []byte("{"),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			before := time.Now()
+			got := signingTime(tt.payload)
+			if !tt.expected.IsZero() {
+				if !got.Equal(tt.expected) {
+					t.Errorf("expected %v, got %v", tt.expected, got)
+				}
+				return
+			}
+			if got.Before(before) || got.After(time.Now()) {
+				t.Errorf("expected the current time, got %v", got)
+			}
+		})
+	}
+}
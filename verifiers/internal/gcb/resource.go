@@ -0,0 +1,51 @@
+package gcb
+
+import (
+	"fmt"
+	"strings"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb/slsaprovenance/iface"
+)
+
+// imageDigestSeparator separates the image name from its digest in the
+// resource URI of an image, e.g.
+// https://us-docker.pkg.dev/project/repo/image@sha256:<digest>.
+const imageDigestSeparator = "@sha256:"
+
+// isImageResource returns true if the resource URI is the URI of an OCI
+// image. Other resources are Artifact Registry packages, e.g. Maven, npm,
+// Python or generic packages, identified by the URI of the file, such as
+// https://us-maven.pkg.dev/project/repo/com/example/app/1.0/app-1.0.jar.
+func isImageResource(resourceURI string) bool {
+	return strings.Contains(resourceURI, imageDigestSeparator)
+}
+
+// verifyResourceDigest verifies that the resource has the expected sha256
+// digest. Images carry the digest in their URI. The digest of packages is
+// that of the subject named after the resource URI.
+func verifyResourceDigest(stmt iface.Provenance, resourceURI, expectedHash string) error {
+	if isImageResource(resourceURI) {
+		// We only verify the URI's sha256 for simplicity.
+		if !strings.HasSuffix(resourceURI, imageDigestSeparator+expectedHash) {
+			return fmt.Errorf("%w: expected resourceUri '%s', got '%s'",
+				serrors.ErrorMismatchHash, expectedHash, resourceURI)
+		}
+		return nil
+	}
+
+	subjects, err := stmt.Subjects()
+	if err != nil {
+		return err
+	}
+	for _, subject := range subjects {
+		if subject.Name != resourceURI {
+			continue
+		}
+		if subject.Digest["sha256"] == expectedHash {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: expected resourceUri '%s' to be a subject with digest '%s'",
+		serrors.ErrorMismatchHash, resourceURI, expectedHash)
+}
@@ -20,3 +20,13 @@ func GetAsString(m map[string]any, field string) (string, error) {
 	}
 	return i, nil
 }
+
+// GetCommitDigest returns the git commit from a material's digest set.
+func GetCommitDigest(digest map[string]string) (string, error) {
+	for _, alg := range []string{"gitCommit", "sha1"} {
+		if commit, ok := digest[alg]; ok && commit != "" {
+			return commit, nil
+		}
+	}
+	return "", fmt.Errorf("%w: commit digest", serrors.ErrorNotPresent)
+}
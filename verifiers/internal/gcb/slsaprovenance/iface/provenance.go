@@ -24,6 +24,9 @@ type Provenance interface {
 	// SourceURI is the full URI (including tag).
 	SourceURI() (string, error)
 
+	// SourceCommit is the git commit of the source.
+	SourceCommit() (string, error)
+
 	// SourceTag is the tag of the source.
 	SourceTag() (string, error)
 
@@ -35,4 +38,11 @@ type Provenance interface {
 
 	// Get system pararmeters.
 	GetSystemParameters() (map[string]any, error)
+
+	// Substitutions returns the build substitutions, both built-in and
+	// user-defined.
+	Substitutions() (map[string]any, error)
+
+	// TriggerID returns the ID of the trigger that started the build.
+	TriggerID() (string, error)
 }
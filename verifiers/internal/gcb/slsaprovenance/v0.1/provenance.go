@@ -16,7 +16,7 @@ package v01
 import (
 	"encoding/json"
 	"fmt"
-	"regexp"
+	"strings"
 	"time"
 
 	intoto "github.com/in-toto/in-toto-golang/in_toto"
@@ -31,8 +31,6 @@ const (
 	PredicateSLSAProvenance = intotov01.PredicateSLSAProvenance
 )
 
-var RegionalKeyRegex = regexp.MustCompile(`^projects\/verified-builder\/locations\/(.*)\/keyRings\/attestor\/cryptoKeys\/builtByGCB\/cryptoKeyVersions\/1$`)
-
 var BuilderIDs = []string{
 	"https://cloudbuild.googleapis.com/GoogleHostedWorker@v0.2",
 	"https://cloudbuild.googleapis.com/GoogleHostedWorker@v0.3",
@@ -187,6 +185,23 @@ func (p *Provenance) GetSystemParameters() (map[string]any, error) {
 	return m, nil
 }
 
+// Substitutions implements Statement.Substitutions.
+func (p *Provenance) Substitutions() (map[string]any, error) {
+	return p.GetSystemParameters()
+}
+
+// TriggerID implements Statement.TriggerID.
+func (p *Provenance) TriggerID() (string, error) {
+	argsMap, ok := p.Pred.Recipe.Arguments.(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("%w: cannot cast arguments as map", serrors.ErrorInvalidDssePayload)
+	}
+	if _, ok := argsMap["buildTriggerId"]; !ok {
+		return "", fmt.Errorf("%w: buildTriggerId", serrors.ErrorNotPresent)
+	}
+	return common.GetAsString(argsMap, "buildTriggerId")
+}
+
 // SourceURI implements Statement.SourceURI.
 func (p *Provenance) SourceURI() (string, error) {
 	if len(p.Pred.Materials) == 0 {
@@ -200,6 +215,23 @@ func (p *Provenance) SourceURI() (string, error) {
 	return uri, nil
 }
 
+// SourceCommit implements Statement.SourceCommit.
+func (p *Provenance) SourceCommit() (string, error) {
+	if len(p.Pred.Materials) == 0 {
+		return "", fmt.Errorf("%w: %s", serrors.ErrorInvalidDssePayload, "no material")
+	}
+	material := p.Pred.Materials[0]
+	if commit, err := common.GetCommitDigest(material.Digest); err == nil {
+		return commit, nil
+	}
+	// v0.1 provenance does not record a digest, but the commit is
+	// part of the material URI: https://github.com/org/repo/commit/<sha>.
+	if _, commit, ok := strings.Cut(material.URI, "/commit/"); ok && commit != "" {
+		return commit, nil
+	}
+	return "", fmt.Errorf("%w: commit in material", serrors.ErrorNotPresent)
+}
+
 // Subjects implements Statement.Subjects.
 func (p *Provenance) Subjects() ([]intoto.Subject, error) {
 	subj := p.StatementHeader.Subject
@@ -16,6 +16,7 @@ package v10
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	intoto "github.com/in-toto/in-toto-golang/in_toto"
 	intotov1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
@@ -152,6 +153,68 @@ func (p *Provenance) externalParameters() (map[string]any, error) {
 	return extParams, nil
 }
 
+// Substitutions implements Provenance.Substitutions.
+func (p *Provenance) Substitutions() (map[string]any, error) {
+	sysParams, err := p.GetSystemParameters()
+	if err != nil {
+		return nil, err
+	}
+	extParams, err := p.externalParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	// Built-in substitutions are internal parameters, user-defined
+	// substitutions are external parameters. A user-defined substitution
+	// must not shadow a built-in one, e.g. TRIGGER_NAME.
+	substitutions := make(map[string]any)
+	for _, field := range []struct {
+		params map[string]any
+		name   string
+	}{
+		{params: sysParams, name: "systemSubstitutions"},
+		{params: extParams, name: "substitutions"},
+	} {
+		value, ok := field.params[field.name]
+		if !ok {
+			continue
+		}
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot convert %s to a map", common.ErrSubstitution, field.name)
+		}
+		for k, v := range m {
+			if _, ok := substitutions[k]; ok {
+				return nil, fmt.Errorf("%w: %q is both a built-in and a user-defined substitution",
+					common.ErrSubstitution, k)
+			}
+			substitutions[k] = v
+		}
+	}
+	return substitutions, nil
+}
+
+// TriggerID implements Provenance.TriggerID.
+func (p *Provenance) TriggerID() (string, error) {
+	sysParams, err := p.GetSystemParameters()
+	if err != nil {
+		return "", err
+	}
+	if _, ok := sysParams["triggerUri"]; !ok {
+		return "", fmt.Errorf("%w: triggerUri", serrors.ErrorNotPresent)
+	}
+	triggerURI, err := common.GetAsString(sysParams, "triggerUri")
+	if err != nil {
+		return "", err
+	}
+	// The URI is projects/<project>/locations/<location>/triggers/<id>.
+	_, id, ok := strings.Cut(triggerURI, "/triggers/")
+	if !ok || id == "" {
+		return "", fmt.Errorf("%w: triggerUri %q", serrors.ErrorInvalidDssePayload, triggerURI)
+	}
+	return id, nil
+}
+
 // SourceURI implements Provenance.SourceURI.
 func (p *Provenance) SourceURI() (string, error) {
 	extParams, err := p.externalParameters()
@@ -170,6 +233,15 @@ func (p *Provenance) SourceURI() (string, error) {
 	return fmt.Sprintf("%v@%v", repository, ref), nil
 }
 
+// SourceCommit implements Provenance.SourceCommit.
+func (p *Provenance) SourceCommit() (string, error) {
+	deps := p.Pred.BuildDefinition.ResolvedDependencies
+	if len(deps) == 0 {
+		return "", fmt.Errorf("%w: empty resolvedDependencies", serrors.ErrorInvalidDssePayload)
+	}
+	return common.GetCommitDigest(deps[0].Digest)
+}
+
 func configSourceField(extParams map[string]any, field string) (string, error) {
 	// We use externalParameters.buildConfigSource.
 	buildConfigSource, ok := extParams["buildConfigSource"]
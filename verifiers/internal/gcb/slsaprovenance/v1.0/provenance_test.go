@@ -66,3 +66,54 @@ func Test_getSubstitutionsField(t *testing.T) {
 		})
 	}
 }
+
+func Test_Substitutions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		system        map[string]any
+		user          map[string]any
+		substitutions map[string]any
+		err           error
+	}{
+		{
+			name:          "built-in and user-defined",
+			system:        map[string]any{"TRIGGER_NAME": "release"},
+			user:          map[string]any{"_DEPLOY_ENV": "prod"},
+			substitutions: map[string]any{"TRIGGER_NAME": "release", "_DEPLOY_ENV": "prod"},
+		},
+		{
+			name:          "no user-defined",
+			system:        map[string]any{"TRIGGER_NAME": "release"},
+			substitutions: map[string]any{"TRIGGER_NAME": "release"},
+		},
+		{
+			name:   "user-defined shadows built-in",
+			system: map[string]any{"TRIGGER_NAME": "release"},
+			user:   map[string]any{"TRIGGER_NAME": "other", "_DEPLOY_ENV": "prod"},
+			err:    common.ErrSubstitution,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			prov := Provenance{}
+			prov.Pred.BuildDefinition.InternalParameters = map[string]any{"systemSubstitutions": tt.system}
+			extParams := map[string]any{}
+			if tt.user != nil {
+				extParams["substitutions"] = tt.user
+			}
+			prov.Pred.BuildDefinition.ExternalParameters = extParams
+
+			substitutions, err := prov.Substitutions()
+			if !cmp.Equal(err, tt.err, cmpopts.EquateErrors()) {
+				t.Errorf(cmp.Diff(err, tt.err, cmpopts.EquateErrors()))
+			}
+			if err == nil && !cmp.Equal(substitutions, tt.substitutions) {
+				t.Errorf(cmp.Diff(substitutions, tt.substitutions))
+			}
+		})
+	}
+}
@@ -2,11 +2,12 @@ package gcb
 
 import (
 	"context"
+	"fmt"
 
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
 	"github.com/slsa-framework/slsa-verifier/v2/options"
 	register "github.com/slsa-framework/slsa-verifier/v2/register"
-	_ "github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb/keys"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb/keys"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 )
 
@@ -17,26 +18,57 @@ func init() {
 	register.RegisterVerifier(VerifierName, GCBVerifierNew())
 }
 
-type GCBVerifier struct{}
+// Config is the trust configuration of a GCBVerifier.
+type Config struct {
+	// KeySet is the set of GCB signing keys. If nil, the keys embedded in
+	// slsa-verifier are used.
+	KeySet *keys.KeySet
+}
+
+type GCBVerifier struct {
+	cfg Config
+}
 
 func GCBVerifierNew() *GCBVerifier {
 	return &GCBVerifier{}
 }
 
+// GCBVerifierWithConfig returns a GCB verifier with its own trust
+// configuration.
+func GCBVerifierWithConfig(cfg Config) *GCBVerifier {
+	return &GCBVerifier{cfg: cfg}
+}
+
 // IsAuthoritativeFor returns true of the verifier can verify provenance
 // generated by the builderID.
 func (v *GCBVerifier) IsAuthoritativeFor(builderIDName string) bool {
-	// This verifier only supports the GCB builders.
-	return builderIDName == "https://cloudbuild.googleapis.com/GoogleHostedWorker"
+	// This verifier only supports the GCB builders: the Google-hosted
+	// worker and private pools.
+	return isGCBBuilder(builderIDName)
 }
 
-// VerifyArtifact verifies provenance for an artifact.
+// VerifyArtifact verifies provenance for an artifact: a package uploaded to
+// Artifact Registry, e.g. a Maven, npm, Python or generic package.
 func (v *GCBVerifier) VerifyArtifact(ctx context.Context,
 	provenance []byte, artifactHash string,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
 ) ([]byte, *utils.TrustedBuilderID, error) {
-	return nil, nil, serrors.ErrorNotSupported
+	result, err := v.VerifyArtifactResult(ctx, provenance, artifactHash, provenanceOpts, builderOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.Statement, result.BuilderID, nil
+}
+
+// VerifyArtifactResult verifies provenance for an artifact and returns the
+// verification result.
+func (v *GCBVerifier) VerifyArtifactResult(ctx context.Context,
+	provenance []byte, artifactHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	return v.verifyProvenance(ctx, provenance, provenanceOpts, builderOpts, false)
 }
 
 // VerifyNpmPackage verifies an npm package tarball.
@@ -48,81 +80,195 @@ func (v *GCBVerifier) VerifyNpmPackage(ctx context.Context,
 	return nil, nil, serrors.ErrorNotSupported
 }
 
+// VerifyNpmPackageResult verifies an npm package tarball.
+func (v *GCBVerifier) VerifyNpmPackageResult(ctx context.Context,
+	attestations []byte, tarballHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	return nil, serrors.ErrorNotSupported
+}
+
 // VerifyImage verifies provenance for an OCI image.
 func (v *GCBVerifier) VerifyImage(ctx context.Context,
 	provenance []byte, artifactImage string,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
 ) ([]byte, *utils.TrustedBuilderID, error) {
-	prov, err := ProvenanceFromBytes(provenance)
+	result, err := v.VerifyImageResult(ctx, provenance, artifactImage, provenanceOpts, builderOpts)
 	if err != nil {
 		return nil, nil, err
 	}
+	return result.Statement, result.BuilderID, nil
+}
+
+// VerifyImageResult verifies provenance for an OCI image and returns the
+// verification result.
+func (v *GCBVerifier) VerifyImageResult(ctx context.Context,
+	provenance []byte, artifactImage string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	return v.verifyProvenance(ctx, provenance, provenanceOpts, builderOpts, true)
+}
+
+// verifyProvenance verifies the provenance of an image, or of another
+// Artifact Registry package if image is false.
+func (v *GCBVerifier) verifyProvenance(ctx context.Context,
+	provenance []byte,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+	image bool,
+) (*utils.VerificationResult, error) {
+	if err := utils.RejectUnsupportedOptions(provenanceOpts,
+		utils.OptionBuildSubstitutions, utils.OptionGCBTrigger); err != nil {
+		return nil, err
+	}
+
+	prov, err := ProvenanceFromBytes(provenance)
+	if err != nil {
+		return nil, err
+	}
 
 	// Verify signature on the intoto attestation.
-	if err := prov.VerifySignature(); err != nil {
-		return nil, nil, err
+	if err := prov.VerifySignature(ctx, v.cfg.KeySet); err != nil {
+		return nil, err
 	}
+	checks := []utils.Check{utils.CheckSignature}
 
 	// Verify the builder.
 	builderID, err := prov.VerifyBuilder(builderOpts)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
+	checks = append(checks, utils.CheckBuilderID)
 
 	// Verify subject digest.
 	if err := prov.VerifySubjectDigest(provenanceOpts.ExpectedDigest); err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	if len(provenanceOpts.ExpectedDigests) > 0 {
+		if err := prov.VerifySubjectDigests(provenanceOpts.ExpectedDigests); err != nil {
+			return nil, err
+		}
+	}
+	checks = append(checks, utils.CheckSubjectDigest)
+
+	// Verify subject name.
+	if provenanceOpts.ExpectedSubjectName != nil {
+		if err := prov.VerifySubjectName(provenanceOpts.ExpectedDigest, *provenanceOpts.ExpectedSubjectName); err != nil {
+			return nil, err
+		}
+		checks = append(checks, utils.CheckSubjectName)
 	}
 
 	// Verify source.
-	if err := prov.VerifySourceURI(provenanceOpts.ExpectedSourceURI, *builderID); err != nil {
-		return nil, nil, err
+	if err := prov.VerifySourceURI(ctx, provenanceOpts.ExpectedSourceURI, *builderID); err != nil {
+		return nil, err
 	}
+	checks = append(checks, utils.CheckSourceURI)
 
 	// Verify metadata.
 	// This is metadata that GCB appends to the DSSE content.
 	if err := prov.VerifyMetadata(provenanceOpts); err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+
+	// Package provenance does not vouch for an image of the same digest,
+	// nor image provenance for a package.
+	isImage, err := prov.IsImage()
+	if err != nil {
+		return nil, err
+	}
+	if image && !isImage {
+		return nil, fmt.Errorf("%w: provenance of a package, not an image", serrors.ErrorInvalidFormat)
+	}
+	if !image && isImage {
+		return nil, fmt.Errorf("%w: provenance of an image, not a package", serrors.ErrorInvalidFormat)
 	}
 
 	// Verify the summary.
 	// This is an additional structure that GCB prepends to the provenance.
 	if err := prov.VerifySummary(provenanceOpts); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	// Verify the text provenance.
 	// This is an additional structure that GCB prepends to the provenance,
 	// intended for humans. It reflect the DSSE payload.
 	if err := prov.VerifyTextProvenance(); err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	checks = append(checks, utils.CheckProvenanceConsistency)
+
+	// Verify the source commit.
+	if provenanceOpts.ExpectedSourceCommit != nil {
+		if err := prov.VerifySourceCommit(*provenanceOpts.ExpectedSourceCommit); err != nil {
+			return nil, err
+		}
+		checks = append(checks, utils.CheckSourceCommit)
+	}
+
+	// Verify the provenance against the local source checkout.
+	if provenanceOpts.SourceCheckout != nil {
+		if err := prov.VerifySourceCheckout(*provenanceOpts.SourceCheckout, provenanceOpts.ExpectedBranch); err != nil {
+			return nil, err
+		}
+		checks = append(checks, utils.CheckSourceCheckout)
 	}
 
 	// Verify branch.
 	if provenanceOpts.ExpectedBranch != nil {
 		if err := prov.VerifyBranch(*provenanceOpts.ExpectedBranch); err != nil {
-			return nil, nil, err
+			return nil, err
 		}
+		checks = append(checks, utils.CheckBranch)
 	}
 
 	// Verify the tag.
 	if provenanceOpts.ExpectedTag != nil {
 		if err := prov.VerifyTag(*provenanceOpts.ExpectedTag); err != nil {
-			return nil, nil, err
+			return nil, err
 		}
+		checks = append(checks, utils.CheckTag)
 	}
 
 	// Verify the versioned tag.
 	if provenanceOpts.ExpectedVersionedTag != nil {
 		if err := prov.VerifyVersionedTag(*provenanceOpts.ExpectedVersionedTag); err != nil {
-			return nil, nil, err
+			return nil, err
+		}
+		checks = append(checks, utils.CheckVersionedTag)
+	}
+
+	// Verify the build substitutions.
+	if len(provenanceOpts.ExpectedBuildSubstitutions) > 0 {
+		if err := prov.VerifyBuildSubstitutions(provenanceOpts.ExpectedBuildSubstitutions); err != nil {
+			return nil, err
+		}
+		checks = append(checks, utils.CheckBuildSubstitutions)
+	}
+
+	// Verify the trigger.
+	if provenanceOpts.ExpectedGCBTrigger != nil {
+		if err := prov.VerifyTrigger(*provenanceOpts.ExpectedGCBTrigger); err != nil {
+			return nil, err
 		}
+		checks = append(checks, utils.CheckBuildTrigger)
 	}
 
 	content, err := prov.GetVerifiedIntotoStatement()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	verifiedProvenance, err := prov.GetVerifiedProvenance()
+	if err != nil {
+		return nil, err
 	}
-	return content, builderID, nil
+	return &utils.VerificationResult{
+		Statement:  content,
+		Provenance: verifiedProvenance,
+		BuilderID:  builderID,
+		Checks:     checks,
+	}, nil
 }
@@ -0,0 +1,76 @@
+package gcb
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+func Test_verifyProvenance(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		image    bool
+		inputs   map[string]string
+		triggers []string
+		hosted   bool
+		expected error
+	}{
+		{
+			name:  "image",
+			image: true,
+		},
+		{
+			name:     "image provenance for a package",
+			expected: serrors.ErrorInvalidFormat,
+		},
+		{
+			name:     "workflow inputs",
+			image:    true,
+			inputs:   map[string]string{"release": "true"},
+			expected: serrors.ErrorNotSupported,
+		},
+		{
+			name:     "build triggers",
+			image:    true,
+			triggers: []string{"push"},
+			expected: serrors.ErrorNotSupported,
+		},
+		{
+			name:     "hosted runner",
+			image:    true,
+			hosted:   true,
+			expected: serrors.ErrorNotSupported,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := os.ReadFile("./testdata/v1.0-gcloud-container-github.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			provenanceOpts := &options.ProvenanceOpts{
+				ExpectedSourceURI:      "https://github.com/khalkie/gcb-prod-prov",
+				ExpectedDigest:         "7e9b6e7ba2842c91cf49f3e214d04a7a496f8214356f41d81a6e6dcad11f11e3",
+				ExpectedWorkflowInputs: tt.inputs,
+				ExpectedBuildTriggers:  tt.triggers,
+				RequireHostedRunner:    tt.hosted,
+			}
+			_, err = GCBVerifierNew().verifyProvenance(context.Background(), content,
+				provenanceOpts, &options.BuilderOpts{}, tt.image)
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Errorf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
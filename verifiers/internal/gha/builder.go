@@ -4,7 +4,6 @@ import (
 	"crypto/x509"
 	"encoding/asn1"
 	"fmt"
-	"net/url"
 	"strings"
 
 	fulcio "github.com/sigstore/fulcio/pkg/certificate"
@@ -59,6 +58,47 @@ func VerifyCertficateSourceRepository(id *WorkflowIdentity,
 	return nil
 }
 
+// VerifyCertificateBuildTrigger verifies that the event that triggered the build
+// is one of the expected triggers. An empty list of triggers allows any event.
+func VerifyCertificateBuildTrigger(id *WorkflowIdentity, expectedTriggers []string) error {
+	if len(expectedTriggers) == 0 {
+		return nil
+	}
+	for _, trigger := range expectedTriggers {
+		if id.BuildTrigger == trigger {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: expected one of '%s', got '%s'", serrors.ErrorMismatchBuildTrigger,
+		strings.Join(expectedTriggers, ","), id.BuildTrigger)
+}
+
+// VerifyCertificateRunnerEnvironment verifies that the build ran on a
+// GitHub-hosted runner if requireHosted is true.
+func VerifyCertificateRunnerEnvironment(id *WorkflowIdentity, requireHosted bool) error {
+	if !requireHosted {
+		return nil
+	}
+	// Older certificates do not record the runner environment.
+	if id.SubjectHosted == nil {
+		return fmt.Errorf("%w: runner environment not present in certificate", serrors.ErrorMismatchRunnerEnvironment)
+	}
+	if *id.SubjectHosted != HostedGitHub {
+		return fmt.Errorf("%w: expected '%s', got '%s'", serrors.ErrorMismatchRunnerEnvironment,
+			HostedGitHub, *id.SubjectHosted)
+	}
+	return nil
+}
+
+// VerifyCertificateSourceCommit verifies that the source commit in the
+// certificate matches the expected commit, which may be abbreviated.
+func VerifyCertificateSourceCommit(id *WorkflowIdentity, expectedCommit *string) error {
+	if expectedCommit == nil {
+		return nil
+	}
+	return utils.VerifySourceCommit(id.SourceSha1, *expectedCommit)
+}
+
 // VerifyBuilderIdentity verifies the signing certificate information.
 // Builder IDs are verified against an expected builder ID provided in the
 // builerOpts, or against the set of defaultBuilders provided. The identiy
@@ -209,84 +249,25 @@ func getExtension(cert *x509.Certificate, oid asn1.ObjectIdentifier, encoded boo
 	return "", nil
 }
 
-type Hosted int
+type Hosted = utils.Hosted
 
 const (
-	HostedSelf Hosted = iota
-	HostedGitHub
+	HostedSelf   = utils.HostedSelf
+	HostedGitHub = utils.HostedGitHub
 )
 
 // WorkflowIdentity is a identity captured from a Fulcio certificate.
-// See https://github.com/sigstore/fulcio/blob/main/docs/oid-info.md.
-type WorkflowIdentity struct {
-	// The source repository
-	SourceRepository string
-	// The commit SHA where the workflow was triggered.
-	SourceSha1 string
-	// Ref of the source.
-	SourceRef *string
-	// ID of the source repository.
-	SourceID *string
-	//  Source owner ID of repository.
-	SourceOwnerID *string
-
-	// Workflow path OIDC subject - ref of reuseable workflow or trigger workflow.
-	SubjectWorkflow *url.URL
-	// Subject commit sha1.
-	SubjectSha1 *string
-	// Hosted status of the subject.
-	SubjectHosted *Hosted
-
-	// BuildTrigger
-	BuildTrigger string
-	// Build config path, i.e. the trigger workflow.
-	BuildConfigPath *string
-
-	// Run ID
-	RunID *string
-	// Issuer
-	Issuer string
-}
-
-// SubjectWorkflowName returns the subject workflow without the git ref.
-func (id *WorkflowIdentity) SubjectWorkflowName() string {
-	// NOTE: You should be able to copy a net.URL struct safely.
-	// See: https://github.com/golang/go/issues/38351
-	withoutRef := *id.SubjectWorkflow
-	withoutRef.Path = id.SubjectWorkflowPath()
-	return withoutRef.String()
-}
-
-// SubjectWorkflowPath returns the subject workflow without the server url.
-func (id *WorkflowIdentity) SubjectWorkflowPath() string {
-	i := strings.LastIndex(id.SubjectWorkflow.Path, "@")
-	if i == -1 {
-		return id.SubjectWorkflow.Path
-	}
-	return id.SubjectWorkflow.Path[:i]
-}
-
-// SubjectWorkflowRef returns the ref for the subject workflow.
-func (id *WorkflowIdentity) SubjectWorkflowRef() string {
-	i := strings.LastIndex(id.SubjectWorkflow.Path, "@")
-	if i == -1 {
-		return ""
-	}
-	return id.SubjectWorkflow.Path[i+1:]
-}
+type WorkflowIdentity = utils.WorkflowIdentity
 
 func getHosted(cert *x509.Certificate) (*Hosted, error) {
 	runnerEnv, err := getExtension(cert, fulcio.OIDRunnerEnvironment, true)
 	if err != nil {
 		return nil, err
 	}
-	if runnerEnv == "github-hosted" {
-		r := HostedGitHub
-		return &r, nil
-	}
-	if runnerEnv == "self-hosted" {
-		r := HostedSelf
-		return &r, nil
+	for _, r := range []Hosted{HostedGitHub, HostedSelf} {
+		if runnerEnv == r.String() {
+			return &r, nil
+		}
 	}
 	return nil, nil
 }
@@ -5,6 +5,7 @@ import (
 	"crypto/x509/pkix"
 	"encoding/asn1"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -451,6 +452,123 @@ func Test_VerifyCertficateSourceRepository(t *testing.T) {
 	}
 }
 
+func Test_VerifyCertificateBuildTrigger(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		trigger  string
+		triggers []string
+		err      error
+	}{
+		{
+			name:    "no expected triggers",
+			trigger: "pull_request_target",
+		},
+		{
+			name:     "trigger match",
+			trigger:  "push",
+			triggers: []string{"push", "release"},
+		},
+		{
+			name:     "second trigger match",
+			trigger:  "release",
+			triggers: []string{"push", "release"},
+		},
+		{
+			name:     "trigger mismatch",
+			trigger:  "pull_request",
+			triggers: []string{"push", "release"},
+			err:      serrors.ErrorMismatchBuildTrigger,
+		},
+		{
+			name:     "case sensitive trigger mismatch",
+			trigger:  "Push",
+			triggers: []string{"push"},
+			err:      serrors.ErrorMismatchBuildTrigger,
+		},
+		{
+			name:     "empty trigger",
+			triggers: []string{"push"},
+			err:      serrors.ErrorMismatchBuildTrigger,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			workflow := &WorkflowIdentity{
+				SourceRepository: "asraa/slsa-on-github-test",
+				SubjectWorkflow:  Must(url.Parse(common.GoBuilderID + refs123)),
+				BuildTrigger:     tt.trigger,
+				Issuer:           certOidcIssuer,
+			}
+			err := VerifyCertificateBuildTrigger(workflow, tt.triggers)
+			if !errCmp(err, tt.err) {
+				t.Errorf(cmp.Diff(err, tt.err, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
+
+func Test_VerifyCertificateRunnerEnvironment(t *testing.T) {
+	t.Parallel()
+	hostedGitHub := HostedGitHub
+	hostedSelf := HostedSelf
+	tests := []struct {
+		name          string
+		hosted        *Hosted
+		requireHosted bool
+		// message is expected in the error.
+		message string
+		err     error
+	}{
+		{
+			name:   "not required self-hosted",
+			hosted: &hostedSelf,
+		},
+		{
+			name: "not required unknown",
+		},
+		{
+			name:          "required github-hosted",
+			hosted:        &hostedGitHub,
+			requireHosted: true,
+		},
+		{
+			name:          "required self-hosted",
+			hosted:        &hostedSelf,
+			requireHosted: true,
+			message:       "got 'self-hosted'",
+			err:           serrors.ErrorMismatchRunnerEnvironment,
+		},
+		{
+			name:          "required unknown",
+			requireHosted: true,
+			err:           serrors.ErrorMismatchRunnerEnvironment,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			workflow := &WorkflowIdentity{
+				SourceRepository: "asraa/slsa-on-github-test",
+				SubjectWorkflow:  Must(url.Parse(common.GoBuilderID + refs123)),
+				SubjectHosted:    tt.hosted,
+				BuildTrigger:     "push",
+				Issuer:           certOidcIssuer,
+			}
+			err := VerifyCertificateRunnerEnvironment(workflow, tt.requireHosted)
+			if !errCmp(err, tt.err) {
+				t.Errorf(cmp.Diff(err, tt.err, cmpopts.EquateErrors()))
+			}
+			if tt.message != "" && !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error %q does not contain %q", err, tt.message)
+			}
+		})
+	}
+}
+
 func asStringPointer(s string) *string {
 	return &s
 }
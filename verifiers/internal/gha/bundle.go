@@ -1,19 +1,31 @@
 package gha
 
 import (
+	"bytes"
 	"context"
+	"crypto"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
 	bundle_v1 "github.com/sigstore/protobuf-specs/gen/pb-go/bundle/v1"
 	v1 "github.com/sigstore/protobuf-specs/gen/pb-go/rekor/v1"
+	"github.com/sigstore/rekor/pkg/generated/client"
 	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
 	"google.golang.org/protobuf/encoding/protojson"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/logging"
 )
 
 // Bundle specific errors.
@@ -22,8 +34,65 @@ var (
 	ErrorUnexpectedEntryType     = errors.New("unexpected tlog entry type")
 	ErrorMissingCertInBundle     = errors.New("missing signing certificate in bundle")
 	ErrorUnexpectedBundleContent = errors.New("expected DSSE bundle content")
+	ErrorUnsupportedBundle       = errors.New("unsupported bundle media type")
+	ErrorInvalidBundle           = errors.New("bundle does not conform to its media type version")
+)
+
+// bundleVersion is the minor version of a v0 Sigstore bundle.
+type bundleVersion int
+
+const (
+	bundleV01 bundleVersion = iota + 1
+	bundleV02
+	bundleV03
 )
 
+// bundleMediaTypes are the supported Sigstore bundle media types. Since v0.3
+// the version is also part of the type name, see
+// https://github.com/sigstore/protobuf-specs/blob/main/protos/sigstore_bundle.proto.
+var bundleMediaTypes = map[string]bundleVersion{
+	"application/vnd.dev.sigstore.bundle+json;version=0.1": bundleV01,
+	"application/vnd.dev.sigstore.bundle+json;version=0.2": bundleV02,
+	"application/vnd.dev.sigstore.bundle+json;version=0.3": bundleV03,
+	"application/vnd.dev.sigstore.bundle.v0.3+json":        bundleV03,
+}
+
+// validateBundleVersion returns the version of the bundle media type and
+// checks the verification material against it:
+//   - v0.1 tlog entries must have an inclusion promise.
+//   - Since v0.2, tlog entries must have an inclusion proof with a checkpoint.
+//   - Since v0.3, the signing certificate is a single certificate rather than
+//     a chain.
+func validateBundleVersion(bundle *bundle_v1.Bundle) (bundleVersion, error) {
+	version, ok := bundleMediaTypes[bundle.GetMediaType()]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrorUnsupportedBundle, bundle.GetMediaType())
+	}
+
+	material := bundle.GetVerificationMaterial()
+	for i, tlogEntry := range material.GetTlogEntries() {
+		if version == bundleV01 && tlogEntry.GetInclusionPromise() == nil {
+			return 0, fmt.Errorf("%w: v0.1 tlog entry %d without inclusion promise",
+				ErrorInvalidBundle, i)
+		}
+		if version >= bundleV02 && tlogEntry.GetInclusionProof().GetCheckpoint() == nil {
+			return 0, fmt.Errorf("%w: tlog entry %d without inclusion proof checkpoint",
+				ErrorInvalidBundle, i)
+		}
+	}
+	switch material.GetContent().(type) {
+	case *bundle_v1.VerificationMaterial_X509CertificateChain:
+		if version >= bundleV03 {
+			return 0, fmt.Errorf("%w: v0.3 bundle with a certificate chain", ErrorInvalidBundle)
+		}
+	case *bundle_v1.VerificationMaterial_Certificate:
+		if version < bundleV03 {
+			return 0, fmt.Errorf("%w: single certificate in a bundle older than v0.3", ErrorInvalidBundle)
+		}
+	}
+	return version, nil
+}
+
 // IsSigstoreBundle checks if the provenance is a Sigstore bundle.
 func IsSigstoreBundle(bytes []byte) bool {
 	var bundle bundle_v1.Bundle
@@ -34,13 +103,35 @@ func IsSigstoreBundle(bytes []byte) bool {
 }
 
 // verifyRekorEntryFromBundle extracts and verifies the Rekor entry from the Sigstore
-// bundle verification material, validating the SignedEntryTimestamp.
-func verifyRekorEntryFromBundle(ctx context.Context, tlogEntry *v1.TransparencyLogEntry,
-	trustedRoot *TrustedRoot) (
+// bundle verification material, validating the SignedEntryTimestamp. Entries
+// without one, e.g. from tile-based logs, are verified with their inclusion proof.
+// If checkpoints is set, the checkpoint of the inclusion proof is verified
+// against the checkpoint configuration too, with rClient for consistency proofs.
+func verifyRekorEntryFromBundle(ctx context.Context, rClient *client.Rekor,
+	tlogEntry *v1.TransparencyLogEntry, trustedRoot *TrustedRoot, checkpoints *CheckpointConfig) (
 	*models.LogEntryAnon, error,
 ) {
+	if tlogEntry.GetInclusionPromise() == nil {
+		rekorEntry, err := verifyInclusionProofFromBundle(tlogEntry, trustedRoot)
+		if err != nil {
+			return nil, err
+		}
+		if checkpoints != nil {
+			if err := checkpoints.verifyCheckpoint(ctx, rClient, rekorEntry); err != nil {
+				return nil, err
+			}
+		}
+		return rekorEntry, nil
+	}
+
 	canonicalBody := tlogEntry.GetCanonicalizedBody()
 	logID := hex.EncodeToString(tlogEntry.GetLogId().GetKeyId())
+	if trustedRoot.RekorPubKeys == nil {
+		return nil, fmt.Errorf("%w: no log keys", serrors.ErrorRekorPubKey)
+	}
+	if _, ok := trustedRoot.RekorPubKeys.Keys[logID]; !ok {
+		return nil, fmt.Errorf("%w: unknown log %s", serrors.ErrorRekorPubKey, logID)
+	}
 	rekorEntry := &models.LogEntryAnon{
 		Body:           canonicalBody,
 		IntegratedTime: &tlogEntry.IntegratedTime,
@@ -51,17 +142,105 @@ func verifyRekorEntryFromBundle(ctx context.Context, tlogEntry *v1.TransparencyL
 		},
 	}
 
-	// Verify tlog entry.
-	if _, err := verifyTlogEntry(ctx, *rekorEntry, false,
-		trustedRoot.RekorPubKeys); err != nil {
+	// The checkpoint configuration applies to the checkpoint of the
+	// inclusion proof, which is verified with the entry.
+	verifyInclusion := checkpoints != nil
+	if verifyInclusion {
+		inclusionProof := tlogEntry.GetInclusionProof()
+		if inclusionProof.GetCheckpoint() == nil {
+			return nil, fmt.Errorf("%w: no checkpoint in the inclusion proof of the bundle entry",
+				serrors.ErrorInvalidCheckpoint)
+		}
+		rekorEntry.Verification.InclusionProof = inclusionProofFromBundle(inclusionProof)
+	}
+
+	// Verify tlog entry. The inclusion proof is verified against the body
+	// encoded as in the Rekor API.
+	logEntry := *rekorEntry
+	if verifyInclusion {
+		logEntry.Body = base64.StdEncoding.EncodeToString(canonicalBody)
+	}
+	if _, err := verifyTlogEntry(ctx, rClient, logEntry, verifyInclusion,
+		trustedRoot.RekorPubKeys, checkpoints); err != nil {
 		return nil, err
 	}
 
 	return rekorEntry, nil
 }
 
+// verifyInclusionProofFromBundle verifies the inclusion proof of the entry
+// against its checkpoint, signed by a log key of the trusted root. The
+// entry has no integrated time: the signing time must come from a trusted
+// timestamp.
+func verifyInclusionProofFromBundle(tlogEntry *v1.TransparencyLogEntry,
+	trustedRoot *TrustedRoot,
+) (*models.LogEntryAnon, error) {
+	inclusionProof := tlogEntry.GetInclusionProof()
+	if inclusionProof == nil || inclusionProof.GetCheckpoint() == nil {
+		return nil, fmt.Errorf("%w: no inclusion promise or proof", serrors.ErrorInvalidRekorEntry)
+	}
+
+	logID := hex.EncodeToString(tlogEntry.GetLogId().GetKeyId())
+	if trustedRoot.RekorPubKeys == nil {
+		return nil, fmt.Errorf("%w: no log keys", serrors.ErrorRekorPubKey)
+	}
+	logKey, ok := trustedRoot.RekorPubKeys.Keys[logID]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown log %s", serrors.ErrorRekorPubKey, logID)
+	}
+	verifier, err := signature.LoadVerifier(logKey.PubKey, crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", serrors.ErrorRekorPubKey, err)
+	}
+
+	canonicalBody := tlogEntry.GetCanonicalizedBody()
+	leafHash := rfc6962.DefaultHasher.HashLeaf(canonicalBody)
+	if err := proof.VerifyInclusion(rfc6962.DefaultHasher, uint64(inclusionProof.GetLogIndex()),
+		uint64(inclusionProof.GetTreeSize()), leafHash, inclusionProof.GetHashes(),
+		inclusionProof.GetRootHash()); err != nil {
+		return nil, fmt.Errorf("%w: %s", serrors.ErrorInvalidRekorEntry, err)
+	}
+
+	checkpoint := inclusionProof.GetCheckpoint().GetEnvelope()
+	if err := verifyLogCheckpoint(checkpoint, verifier, uint64(inclusionProof.GetTreeSize()),
+		inclusionProof.GetRootHash()); err != nil {
+		return nil, err
+	}
+
+	return &models.LogEntryAnon{
+		Body:     canonicalBody,
+		LogIndex: &tlogEntry.LogIndex,
+		LogID:    &logID,
+		Verification: &models.LogEntryAnonVerification{
+			InclusionProof: inclusionProofFromBundle(inclusionProof),
+		},
+	}, nil
+}
+
+// inclusionProofFromBundle returns the inclusion proof of a bundle entry in
+// the format of the Rekor API.
+func inclusionProofFromBundle(inclusionProof *v1.InclusionProof) *models.InclusionProof {
+	checkpoint := inclusionProof.GetCheckpoint().GetEnvelope()
+	rootHash := hex.EncodeToString(inclusionProof.GetRootHash())
+	hashes := make([]string, 0, len(inclusionProof.GetHashes()))
+	for _, h := range inclusionProof.GetHashes() {
+		hashes = append(hashes, hex.EncodeToString(h))
+	}
+	return &models.InclusionProof{
+		Checkpoint: &checkpoint,
+		Hashes:     hashes,
+		LogIndex:   &inclusionProof.LogIndex,
+		RootHash:   &rootHash,
+		TreeSize:   &inclusionProof.TreeSize,
+	}
+}
+
 // getEnvelopeFromBundle extracts the DSSE envelope from the Sigstore bundle.
 func getEnvelopeFromBundle(bundle *bundle_v1.Bundle) (*dsselib.Envelope, error) {
+	if bundle.GetMessageSignature() != nil {
+		// A message signature signs an artifact digest, not an attestation.
+		return nil, fmt.Errorf("%w: got a message signature", ErrorUnexpectedBundleContent)
+	}
 	dsseEnvelope := bundle.GetDsseEnvelope()
 	if dsseEnvelope == nil {
 		return nil, ErrorUnexpectedBundleContent
@@ -92,17 +271,37 @@ func getEnvelopeFromBundleBytes(content []byte) (*dsselib.Envelope, error) {
 	return env, nil
 }
 
-// getLeafCertFromBundle extracts the signing cert from the Sigstore bundle.
+// getLeafCertFromBundle extracts the signing cert from the Sigstore bundle:
+// the first certificate of the chain up to v0.2, or the single certificate
+// since v0.3. Bundles signed with a public key have no certificate, only a
+// hint of the key, and are not supported: keys passed with --public-key are
+// only used for DSSE envelopes.
 func getLeafCertFromBundle(bundle *bundle_v1.Bundle) (*x509.Certificate, error) {
-	certChain := bundle.GetVerificationMaterial().GetX509CertificateChain().GetCertificates()
-	if len(certChain) == 0 {
+	var certBytes []byte
+	material := bundle.GetVerificationMaterial()
+	switch content := material.GetContent().(type) {
+	case *bundle_v1.VerificationMaterial_X509CertificateChain:
+		certChain := content.X509CertificateChain.GetCertificates()
+		if len(certChain) == 0 {
+			return nil, ErrorMissingCertInBundle
+		}
+		// The first certificate is the leaf cert: see
+		// https://github.com/sigstore/protobuf-specs/blob/16541696de137c6281d66d075a4924d9bbd181ff/protos/sigstore_common.proto#L170
+		certBytes = certChain[0].GetRawBytes()
+	case *bundle_v1.VerificationMaterial_Certificate:
+		certBytes = content.Certificate.GetRawBytes()
+	case *bundle_v1.VerificationMaterial_PublicKey:
+		return nil, fmt.Errorf("%w: %w: bundle signed with public key %q, pass a DSSE envelope instead",
+			serrors.ErrorNotSupported, ErrorMissingCertInBundle, content.PublicKey.GetHint())
+	default:
 		return nil, ErrorMissingCertInBundle
 	}
 
-	// The first certificate is the leaf cert: see
-	// https://github.com/sigstore/protobuf-specs/blob/16541696de137c6281d66d075a4924d9bbd181ff/protos/sigstore_common.proto#L170
-	certBytes := certChain[0].GetRawBytes()
-	return x509.ParseCertificate(certBytes)
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", serrors.ErrorInvalidCertificate, err)
+	}
+	return cert, nil
 }
 
 // matchRekorEntryWithEnvelope ensures that the log entry references the given
@@ -110,13 +309,18 @@ func getLeafCertFromBundle(bundle *bundle_v1.Bundle) (*x509.Certificate, error)
 // tlog timestamp attests to the signature creation time.
 func matchRekorEntryWithEnvelope(tlogEntry *v1.TransparencyLogEntry, env *dsselib.Envelope) error {
 	kindVersion := tlogEntry.GetKindVersion()
-	if kindVersion.Kind != "intoto" &&
-		kindVersion.Version != "0.0.2" {
-		return fmt.Errorf("%w: expected intoto:0.0.2, got %s:%s", ErrorUnexpectedEntryType,
-			kindVersion.Kind, kindVersion.Version)
+	switch {
+	case kindVersion.GetKind() == "intoto" && kindVersion.GetVersion() == "0.0.2":
+		return matchIntotoEntryWithEnvelope(tlogEntry.GetCanonicalizedBody(), env)
+	case kindVersion.GetKind() == "dsse" && kindVersion.GetVersion() == "0.0.2":
+		return matchDSSEEntryWithEnvelope(tlogEntry.GetCanonicalizedBody(), env)
+	default:
+		return fmt.Errorf("%w: expected intoto:0.0.2 or dsse:0.0.2, got %s:%s", ErrorUnexpectedEntryType,
+			kindVersion.GetKind(), kindVersion.GetVersion())
 	}
+}
 
-	canonicalBody := tlogEntry.GetCanonicalizedBody()
+func matchIntotoEntryWithEnvelope(canonicalBody []byte, env *dsselib.Envelope) error {
 	var toto models.Intoto
 	var intotoObj models.IntotoV002Schema
 	if err := json.Unmarshal(canonicalBody, &toto); err != nil {
@@ -155,14 +359,75 @@ func matchRekorEntryWithEnvelope(tlogEntry *v1.TransparencyLogEntry, env *dsseli
 	return nil
 }
 
+// dsseV002Entry is the canonical body of a dsse:0.0.2 entry of a tile-based
+// Rekor log. Only the fields matched with the envelope are declared.
+type dsseV002Entry struct {
+	Spec struct {
+		DSSEV002 *struct {
+			PayloadHash struct {
+				Algorithm string `json:"algorithm"`
+				Digest    []byte `json:"digest"`
+			} `json:"payloadHash"`
+			Signatures []struct {
+				Content []byte `json:"content"`
+			} `json:"signatures"`
+		} `json:"dsseV002"`
+	} `json:"spec"`
+}
+
+func matchDSSEEntryWithEnvelope(canonicalBody []byte, env *dsselib.Envelope) error {
+	var entry dsseV002Entry
+	if err := json.Unmarshal(canonicalBody, &entry); err != nil {
+		return fmt.Errorf("%w: %s", ErrorUnexpectedEntryType, err)
+	}
+	spec := entry.Spec.DSSEV002
+	if spec == nil {
+		return fmt.Errorf("%w: missing dsseV002 spec", ErrorUnexpectedEntryType)
+	}
+
+	if spec.PayloadHash.Algorithm != "SHA2_256" {
+		return fmt.Errorf("%w: payload hash algorithm %q", ErrorUnexpectedEntryType,
+			spec.PayloadHash.Algorithm)
+	}
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return fmt.Errorf("%w: %s", serrors.ErrorInvalidEncoding, err)
+	}
+	payloadHash := sha256.Sum256(payload)
+	if !bytes.Equal(spec.PayloadHash.Digest, payloadHash[:]) {
+		return fmt.Errorf("%w: payload hash", ErrorMismatchSignature)
+	}
+
+	if len(env.Signatures) != len(spec.Signatures) {
+		return fmt.Errorf("expected %d sigs in canonical body, got %d",
+			len(env.Signatures), len(spec.Signatures))
+	}
+	for _, sig := range env.Signatures {
+		var matchCanonical bool
+		for _, canonicalSig := range spec.Signatures {
+			if base64.StdEncoding.EncodeToString(canonicalSig.Content) == sig.Sig {
+				matchCanonical = true
+			}
+		}
+		if !matchCanonical {
+			return ErrorMismatchSignature
+		}
+	}
+
+	return nil
+}
+
 // VerifyProvenanceBundle verifies the DSSE envelope using the offline Rekor bundle and
 // returns the verified DSSE envelope containing the provenance
-// and the signing certificate given the provenance.
+// and the signing certificate given the provenance. If checkpoints is set, the
+// checkpoint of the transparency log entry is verified too.
 func VerifyProvenanceBundle(ctx context.Context, bundleBytes []byte,
-	trustedRoot *TrustedRoot) (
+	trustedRoot *TrustedRoot, rClient *client.Rekor, checkpoints *CheckpointConfig,
+	policy TimestampPolicy) (
 	*SignedAttestation, error,
 ) {
-	proposedSignedAtt, err := verifyBundleAndEntryFromBytes(ctx, bundleBytes, trustedRoot, true)
+	proposedSignedAtt, err := verifyBundleAndEntryFromBytes(ctx, bundleBytes, trustedRoot,
+		rClient, checkpoints, true, policy)
 	if err != nil {
 		return nil, err
 	}
@@ -173,34 +438,119 @@ func VerifyProvenanceBundle(ctx context.Context, bundleBytes []byte,
 	return proposedSignedAtt, nil
 }
 
-// verifyBundleAndEntry validates the rekor entry inn the bundle
-// and that the entry (cert, signatures) matches the data in the bundle.
-func verifyBundleAndEntry(ctx context.Context, bundle *bundle_v1.Bundle,
-	trustedRoot *TrustedRoot, requireCert bool,
-) (*SignedAttestation, error) {
-	// We only expect one TLOG entry. If this changes in the future, we must iterate
-	// for a matching one.
-	if bundle.GetVerificationMaterial() == nil ||
-		len(bundle.GetVerificationMaterial().GetTlogEntries()) == 0 {
-		return nil, fmt.Errorf("bundle missing offline tlog verification material %d", len(bundle.GetVerificationMaterial().GetTlogEntries()))
+// VerifyBundleWithIdentity verifies the DSSE envelope in a Sigstore bundle
+// signed by a keyless identity other than a GitHub builder, e.g. a verifier
+// issuing VSAs, and returns the verified envelope. The subject is a regular
+// expression matched against the certificate identity.
+func VerifyBundleWithIdentity(ctx context.Context, bundleBytes []byte,
+	trustedRoot *TrustedRoot, policy TimestampPolicy, issuer, subjectRegexp string,
+) (*dsselib.Envelope, error) {
+	signedAtt, err := verifyBundleAndEntryFromBytes(ctx, bundleBytes, trustedRoot, nil, nil, true, policy)
+	if err != nil {
+		return nil, err
 	}
+	if err := verifySignedAttestationWithIdentity(signedAtt, trustedRoot, cosign.Identity{
+		Issuer:        issuer,
+		SubjectRegExp: subjectRegexp,
+	}); err != nil {
+		return nil, err
+	}
+
+	return signedAtt.Envelope, nil
+}
 
-	// Verify tlog entry.
-	tlogEntry := bundle.GetVerificationMaterial().GetTlogEntries()[0]
-	rekorEntry, err := verifyRekorEntryFromBundle(ctx, tlogEntry, trustedRoot)
+// verifyRekorEntryWithEnvelope verifies a tlog entry of the bundle and
+// that it matches the envelope.
+func verifyRekorEntryWithEnvelope(ctx context.Context, rClient *client.Rekor,
+	tlogEntry *v1.TransparencyLogEntry, env *dsselib.Envelope, trustedRoot *TrustedRoot,
+	checkpoints *CheckpointConfig,
+) (*models.LogEntryAnon, error) {
+	rekorEntry, err := verifyRekorEntryFromBundle(ctx, rClient, tlogEntry, trustedRoot, checkpoints)
 	if err != nil {
 		return nil, err
 	}
 
+	// Match tlog entry signature with the envelope.
+	if err := matchRekorEntryWithEnvelope(tlogEntry, env); err != nil {
+		return nil, fmt.Errorf("matching bundle entry with content: %w", err)
+	}
+	return rekorEntry, nil
+}
+
+// verifyBundleAndEntry validates the bundle against its media type version,
+// the rekor entry and the RFC 3161 timestamps in the bundle, as required by
+// the timestamp policy, and that the entry (cert, signatures) matches the
+// data in the bundle. If checkpoints is set, the checkpoint of the entry is
+// verified against it, with rClient for consistency proofs.
+func verifyBundleAndEntry(ctx context.Context, bundle *bundle_v1.Bundle,
+	trustedRoot *TrustedRoot, rClient *client.Rekor, checkpoints *CheckpointConfig,
+	requireCert bool, policy TimestampPolicy,
+) (*SignedAttestation, error) {
+	if _, err := validateBundleVersion(bundle); err != nil {
+		return nil, err
+	}
+
+	tlogEntries := bundle.GetVerificationMaterial().GetTlogEntries()
+	timestamps := bundle.GetVerificationMaterial().GetTimestampVerificationData().GetRfc3161Timestamps()
+	if len(tlogEntries) == 0 && (policy.requiresTlog() || len(timestamps) == 0) {
+		return nil, fmt.Errorf("%w: bundle missing offline tlog verification material %d",
+			serrors.ErrorNoValidRekorEntries, len(tlogEntries))
+	}
+	if len(timestamps) == 0 && policy.requiresTSA() {
+		return nil, fmt.Errorf("%w: bundle missing RFC 3161 timestamp", serrors.ErrorMissingTimestamp)
+	}
+
 	// Extract DSSE envelope.
 	env, err := getEnvelopeFromBundle(bundle)
 	if err != nil {
 		return nil, err
 	}
 
-	// Match tlog entry signature with the envelope.
-	if err := matchRekorEntryWithEnvelope(tlogEntry, env); err != nil {
-		return nil, fmt.Errorf("matching bundle entry with content: %w", err)
+	// Bundles may have entries of several logs: the first entry that
+	// verifies and matches the envelope is used.
+	var rekorEntry *models.LogEntryAnon
+	var errs []error
+	for _, tlogEntry := range tlogEntries {
+		rekorEntry, err = verifyRekorEntryWithEnvelope(ctx, rClient, tlogEntry, env, trustedRoot, checkpoints)
+		if err == nil {
+			break
+		}
+		errs = append(errs, err)
+	}
+	if rekorEntry == nil && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	// Verify the timestamps over the signature.
+	var signedTimes []time.Time
+	if len(timestamps) > 0 {
+		signatures := bundle.GetDsseEnvelope().GetSignatures()
+		if len(signatures) == 0 {
+			return nil, fmt.Errorf("%w: no signature in the envelope", serrors.ErrorNoValidSignature)
+		}
+		signedTimes, err = verifyRFC3161Timestamps(timestamps, signatures[0].GetSig(),
+			trustedRoot.TimestampAuthorities)
+		// Under the tlog policy, timestamps are optional when the tlog entry
+		// has an integrated time: those that cannot be verified are ignored.
+		if err != nil && (policy.requiresTSA() || rekorEntry == nil || rekorEntry.IntegratedTime == nil) {
+			return nil, err
+		}
+		if err != nil {
+			logging.FromContext(ctx).Warn(fmt.Sprintf("Ignoring the RFC 3161 timestamps of the bundle: %v", err),
+				"error", err)
+			signedTimes = nil
+		}
+	}
+
+	// Entries without an inclusion promise have no integrated time: it is
+	// taken from the trusted timestamps over the signature.
+	if rekorEntry != nil && rekorEntry.IntegratedTime == nil {
+		if len(signedTimes) == 0 {
+			return nil, fmt.Errorf("%w: no RFC 3161 timestamp for an entry without integrated time",
+				serrors.ErrorMissingTimestamp)
+		}
+		integratedTime := signedTimes[0].Unix()
+		rekorEntry.IntegratedTime = &integratedTime
 	}
 
 	// Get certificate from bundle.
@@ -216,13 +566,15 @@ func verifyBundleAndEntry(ctx context.Context, bundle *bundle_v1.Bundle,
 		SigningCert: cert,
 		Envelope:    env,
 		RekorEntry:  rekorEntry,
+		Timestamps:  signedTimes,
 	}, nil
 }
 
 // verifyBundleAndEntryFromBytes validates the rekor entry inn the bundle
 // and that the entry (cert, signatures) matches the data in the bundle.
 func verifyBundleAndEntryFromBytes(ctx context.Context, bundleBytes []byte,
-	trustedRoot *TrustedRoot, requireCert bool,
+	trustedRoot *TrustedRoot, rClient *client.Rekor, checkpoints *CheckpointConfig,
+	requireCert bool, policy TimestampPolicy,
 ) (*SignedAttestation, error) {
 	// Extract the SigningCert, Envelope, and RekorEntry from the bundle.
 	var bundle bundle_v1.Bundle
@@ -231,5 +583,5 @@ func verifyBundleAndEntryFromBytes(ctx context.Context, bundleBytes []byte,
 	}
 
 	return verifyBundleAndEntry(ctx, &bundle,
-		trustedRoot, requireCert)
+		trustedRoot, rClient, checkpoints, requireCert, policy)
 }
@@ -1,12 +1,27 @@
 package gha
 
 import (
+	"bytes"
 	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	bundle_v1 "github.com/sigstore/protobuf-specs/gen/pb-go/bundle/v1"
+	common "github.com/sigstore/protobuf-specs/gen/pb-go/common/v1"
+	"github.com/sigstore/protobuf-specs/gen/pb-go/dsse"
+	v1 "github.com/sigstore/protobuf-specs/gen/pb-go/rekor/v1"
+	"github.com/sigstore/sigstore/pkg/tuf"
+	"google.golang.org/protobuf/encoding/protojson"
+
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
 )
 
@@ -67,7 +82,7 @@ func Test_verifyBundle(t *testing.T) {
 				panic(fmt.Errorf("os.ReadFile: %w", err))
 			}
 
-			_, err = VerifyProvenanceBundle(ctx, content, trustedRoot)
+			_, err = VerifyProvenanceBundle(ctx, content, trustedRoot, nil, nil, TimestampPolicyTlog)
 
 			if !errCmp(err, tt.expected) {
 				t.Errorf(cmp.Diff(err, tt.expected))
@@ -75,3 +90,655 @@ func Test_verifyBundle(t *testing.T) {
 		})
 	}
 }
+
+// testBundleWithInclusionProof returns a bundle whose entry, of a
+// tile-based log, has an inclusion proof but no inclusion promise.
+func testBundleWithInclusionProof(t *testing.T, log *testLog, tsa *testTSA, at time.Time) *bundle_v1.Bundle {
+	t.Helper()
+
+	payload := []byte(`{"_type":"https://in-toto.io/Statement/v0.1"}`)
+	sig := []byte("signature")
+	payloadHash := sha256.Sum256(payload)
+	body, err := json.Marshal(map[string]any{
+		"apiVersion": "0.0.2",
+		"kind":       "dsse",
+		"spec": map[string]any{
+			"dsseV002": map[string]any{
+				"payloadHash": map[string]any{"algorithm": "SHA2_256", "digest": payloadHash[:]},
+				"signatures":  []map[string]any{{"content": sig}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index := log.tree.Size()
+	log.tree.AppendData(body, []byte("next"))
+	size := log.tree.Size()
+	hashes, err := log.tree.InclusionProof(index, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &bundle_v1.Bundle{
+		MediaType: "application/vnd.dev.sigstore.bundle.v0.3+json",
+		Content: &bundle_v1.Bundle_DsseEnvelope{DsseEnvelope: &dsse.Envelope{
+			Payload:     payload,
+			PayloadType: "application/vnd.in-toto+json",
+			Signatures:  []*dsse.Signature{{Sig: sig}},
+		}},
+		VerificationMaterial: &bundle_v1.VerificationMaterial{
+			TlogEntries: []*v1.TransparencyLogEntry{{
+				LogIndex:    int64(index),
+				LogId:       &common.LogId{KeyId: testLogID(t, log.pubKey)},
+				KindVersion: &v1.KindVersion{Kind: "dsse", Version: "0.0.2"},
+				InclusionProof: &v1.InclusionProof{
+					LogIndex:   int64(index),
+					RootHash:   log.tree.HashAt(size),
+					TreeSize:   int64(size),
+					Hashes:     hashes,
+					Checkpoint: &v1.Checkpoint{Envelope: log.checkpoint(t, size)},
+				},
+				CanonicalizedBody: body,
+			}},
+			TimestampVerificationData: &bundle_v1.TimestampVerificationData{
+				Rfc3161Timestamps: []*common.RFC3161SignedTimestamp{tsa.timestamp(t, sig, at)},
+			},
+		},
+	}
+}
+
+func testLogID(t *testing.T, pubKey crypto.PublicKey) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logID := sha256.Sum256(der)
+	return logID[:]
+}
+
+func Test_verifyBundleWithInclusionProof(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	tsa := testTSANew(t)
+	at := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	tests := []struct {
+		name   string
+		policy TimestampPolicy
+		// modify changes the bundle of the log and the trusted root.
+		modify   func(t *testing.T, b *bundle_v1.Bundle, log *testLog, root *TrustedRoot)
+		expected error
+	}{
+		{
+			name: "valid bundle",
+		},
+		{
+			name: "checkpoint co-signed by a witness",
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog, root *TrustedRoot) {
+				witness, _ := testWitness(t, "witness.example.com")
+				proof := b.GetVerificationMaterial().GetTlogEntries()[0].GetInclusionProof()
+				proof.Checkpoint.Envelope = log.checkpoint(t, uint64(proof.GetTreeSize()), witness)
+			},
+		},
+		{
+			name: "entry not in the tree",
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog, root *TrustedRoot) {
+				b.GetVerificationMaterial().GetTlogEntries()[0].GetInclusionProof().LogIndex++
+			},
+			expected: serrors.ErrorInvalidRekorEntry,
+		},
+		{
+			name: "checkpoint of another tree",
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog, root *TrustedRoot) {
+				proof := b.GetVerificationMaterial().GetTlogEntries()[0].GetInclusionProof()
+				proof.Checkpoint.Envelope = log.checkpoint(t, uint64(proof.GetTreeSize())-1)
+			},
+			expected: serrors.ErrorInvalidCheckpoint,
+		},
+		{
+			name: "checkpoint signed by another key",
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog, root *TrustedRoot) {
+				other := testLogNew(t, 0, "entry")
+				other.tree = log.tree
+				proof := b.GetVerificationMaterial().GetTlogEntries()[0].GetInclusionProof()
+				proof.Checkpoint.Envelope = other.checkpoint(t, uint64(proof.GetTreeSize()))
+			},
+			expected: serrors.ErrorInvalidCheckpoint,
+		},
+		{
+			name: "no checkpoint",
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog, root *TrustedRoot) {
+				b.GetVerificationMaterial().GetTlogEntries()[0].GetInclusionProof().Checkpoint = nil
+			},
+			expected: ErrorInvalidBundle,
+		},
+		{
+			name: "untrusted log",
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog, root *TrustedRoot) {
+				root.RekorPubKeys.Keys = map[string]cosign.TransparencyLogPubKey{}
+			},
+			expected: serrors.ErrorRekorPubKey,
+		},
+		{
+			name: "no timestamp",
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog, root *TrustedRoot) {
+				b.GetVerificationMaterial().TimestampVerificationData = nil
+			},
+			expected: serrors.ErrorMissingTimestamp,
+		},
+		{
+			name: "untrusted timestamp",
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog, root *TrustedRoot) {
+				root.TimestampAuthorities = []*TimestampAuthority{testTSANew(t).authority()}
+			},
+			expected: serrors.ErrorInvalidTimestamp,
+		},
+		{
+			name: "entry of another signature",
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog, root *TrustedRoot) {
+				b.GetDsseEnvelope().GetSignatures()[0].Sig = []byte("other")
+			},
+			expected: ErrorMismatchSignature,
+		},
+		{
+			name: "entry of another payload",
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog, root *TrustedRoot) {
+				b.GetDsseEnvelope().Payload = []byte("{}")
+			},
+			expected: ErrorMismatchSignature,
+		},
+		{
+			name:   "timestamp and tlog entry required",
+			policy: TimestampPolicyBoth,
+		},
+		{
+			name:   "timestamp required",
+			policy: TimestampPolicyTSA,
+		},
+		{
+			name:   "timestamp without tlog entry",
+			policy: TimestampPolicyTSA,
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog, root *TrustedRoot) {
+				b.GetVerificationMaterial().TlogEntries = nil
+			},
+		},
+		{
+			name:   "tlog entry required",
+			policy: TimestampPolicyTlog,
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog, root *TrustedRoot) {
+				b.GetVerificationMaterial().TlogEntries = nil
+			},
+			expected: serrors.ErrorNoValidRekorEntries,
+		},
+		{
+			name:   "tlog entry required with timestamp",
+			policy: TimestampPolicyBoth,
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog, root *TrustedRoot) {
+				b.GetVerificationMaterial().TlogEntries = nil
+			},
+			expected: serrors.ErrorNoValidRekorEntries,
+		},
+		{
+			name:   "timestamp required without timestamp",
+			policy: TimestampPolicyTSA,
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog, root *TrustedRoot) {
+				b.GetVerificationMaterial().TimestampVerificationData = nil
+			},
+			expected: serrors.ErrorMissingTimestamp,
+		},
+		{
+			name:   "neither tlog entry nor timestamp",
+			policy: TimestampPolicyTSA,
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog, root *TrustedRoot) {
+				b.GetVerificationMaterial().TlogEntries = nil
+				b.GetVerificationMaterial().TimestampVerificationData = nil
+			},
+			expected: serrors.ErrorNoValidRekorEntries,
+		},
+		{
+			name: "unexpected entry type",
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog, root *TrustedRoot) {
+				b.GetVerificationMaterial().GetTlogEntries()[0].KindVersion.Kind = "hashedrekord"
+			},
+			expected: ErrorUnexpectedEntryType,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			log := testLogNew(t, 5, "entry")
+			bundle := testBundleWithInclusionProof(t, log, tsa, at)
+			logID := hex.EncodeToString(testLogID(t, log.pubKey))
+			trustedRoot := &TrustedRoot{
+				RekorPubKeys: &cosign.TrustedTransparencyLogPubKeys{
+					Keys: map[string]cosign.TransparencyLogPubKey{
+						logID: {PubKey: log.pubKey, Status: tuf.Active},
+					},
+				},
+				TimestampAuthorities: []*TimestampAuthority{tsa.authority()},
+			}
+			if tt.modify != nil {
+				tt.modify(t, bundle, log, trustedRoot)
+			}
+
+			signedAtt, err := verifyBundleAndEntry(ctx, bundle, trustedRoot, nil, nil, false, tt.policy)
+			if !errCmp(err, tt.expected) {
+				t.Fatalf(cmp.Diff(err, tt.expected))
+			}
+			if err != nil {
+				return
+			}
+			if signedAtt.RekorEntry != nil {
+				if got := *signedAtt.RekorEntry.IntegratedTime; got != at.Unix() {
+					t.Errorf("integrated time %d, expected %d", got, at.Unix())
+				}
+			}
+			if len(signedAtt.Timestamps) != 1 || !signedAtt.Timestamps[0].Equal(at) {
+				t.Errorf("timestamps %v, expected %v", signedAtt.Timestamps, at)
+			}
+		})
+	}
+}
+
+// testAddInclusionPromise adds an inclusion promise of the log to the entry,
+// integrated at the given time.
+func testAddInclusionPromise(t *testing.T, log *testLog, entry *v1.TransparencyLogEntry, at time.Time) {
+	t.Helper()
+
+	// The fields are in the order of the canonical JSON.
+	payload, err := json.Marshal(struct {
+		Body           []byte `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogID          string `json:"logID"`
+		LogIndex       int64  `json:"logIndex"`
+	}{
+		Body:           entry.GetCanonicalizedBody(),
+		IntegratedTime: at.Unix(),
+		LogID:          hex.EncodeToString(entry.GetLogId().GetKeyId()),
+		LogIndex:       entry.GetLogIndex(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := log.signer.SignMessage(bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry.IntegratedTime = at.Unix()
+	entry.InclusionPromise = &v1.InclusionPromise{SignedEntryTimestamp: set}
+}
+
+func Test_verifyBundleVersions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	tsa := testTSANew(t)
+	at := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	const (
+		v01       = "application/vnd.dev.sigstore.bundle+json;version=0.1"
+		v02       = "application/vnd.dev.sigstore.bundle+json;version=0.2"
+		v03       = "application/vnd.dev.sigstore.bundle+json;version=0.3"
+		v03Suffix = "application/vnd.dev.sigstore.bundle.v0.3+json"
+	)
+	tests := []struct {
+		name      string
+		mediaType string
+		// promise and proof are the verification data of the tlog entry.
+		promise bool
+		proof   bool
+		// cert is the signing certificate form: chain, certificate or
+		// publicKey.
+		cert   string
+		policy TimestampPolicy
+		// untrustedTimestamps is set if the timestamps cannot be verified.
+		untrustedTimestamps bool
+		// modify changes the bundle of the log.
+		modify   func(t *testing.T, b *bundle_v1.Bundle, log *testLog)
+		expected error
+	}{
+		{
+			name:      "v0.1 with inclusion promise",
+			mediaType: v01,
+			promise:   true,
+			cert:      "chain",
+		},
+		{
+			name:      "v0.1 with inclusion promise and proof",
+			mediaType: v01,
+			promise:   true,
+			proof:     true,
+			cert:      "chain",
+		},
+		{
+			name:      "v0.1 without inclusion promise",
+			mediaType: v01,
+			proof:     true,
+			cert:      "chain",
+			expected:  ErrorInvalidBundle,
+		},
+		{
+			name:      "v0.1 with single certificate",
+			mediaType: v01,
+			promise:   true,
+			cert:      "certificate",
+			expected:  ErrorInvalidBundle,
+		},
+		{
+			name:      "v0.2 with inclusion proof",
+			mediaType: v02,
+			proof:     true,
+			cert:      "chain",
+		},
+		{
+			name:      "v0.2 with inclusion promise and proof",
+			mediaType: v02,
+			promise:   true,
+			proof:     true,
+			cert:      "chain",
+		},
+		{
+			name:      "v0.2 without inclusion proof",
+			mediaType: v02,
+			promise:   true,
+			cert:      "chain",
+			expected:  ErrorInvalidBundle,
+		},
+		{
+			name:      "v0.3 with single certificate",
+			mediaType: v03,
+			proof:     true,
+			cert:      "certificate",
+		},
+		{
+			name:      "v0.3 media type with version suffix",
+			mediaType: v03Suffix,
+			proof:     true,
+			cert:      "certificate",
+		},
+		{
+			name:      "v0.3 with inclusion promise and proof",
+			mediaType: v03Suffix,
+			promise:   true,
+			proof:     true,
+			cert:      "certificate",
+		},
+		{
+			name:      "v0.3 with certificate chain",
+			mediaType: v03Suffix,
+			proof:     true,
+			cert:      "chain",
+			expected:  ErrorInvalidBundle,
+		},
+		{
+			name:      "v0.3 without inclusion proof",
+			mediaType: v03Suffix,
+			promise:   true,
+			cert:      "certificate",
+			expected:  ErrorInvalidBundle,
+		},
+		{
+			name:      "public key hint",
+			mediaType: v03Suffix,
+			proof:     true,
+			cert:      "publicKey",
+			expected:  serrors.ErrorNotSupported,
+		},
+		{
+			name:      "unknown version",
+			mediaType: "application/vnd.dev.sigstore.bundle+json;version=0.4",
+			proof:     true,
+			cert:      "certificate",
+			expected:  ErrorUnsupportedBundle,
+		},
+		{
+			name:     "no media type",
+			proof:    true,
+			cert:     "certificate",
+			expected: ErrorUnsupportedBundle,
+		},
+		{
+			name:      "message signature",
+			mediaType: v03Suffix,
+			proof:     true,
+			cert:      "certificate",
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog) {
+				b.Content = &bundle_v1.Bundle_MessageSignature{MessageSignature: &common.MessageSignature{
+					MessageDigest: &common.HashOutput{
+						Algorithm: common.HashAlgorithm_SHA2_256,
+						Digest:    make([]byte, sha256.Size),
+					},
+					Signature: []byte("signature"),
+				}}
+			},
+			expected: ErrorUnexpectedBundleContent,
+		},
+		{
+			name:                "untrusted timestamp with inclusion promise",
+			mediaType:           v03Suffix,
+			promise:             true,
+			proof:               true,
+			cert:                "certificate",
+			untrustedTimestamps: true,
+		},
+		{
+			name:                "untrusted timestamp with inclusion promise and timestamp required",
+			mediaType:           v03Suffix,
+			promise:             true,
+			proof:               true,
+			cert:                "certificate",
+			policy:              TimestampPolicyBoth,
+			untrustedTimestamps: true,
+			expected:            serrors.ErrorInvalidTimestamp,
+		},
+		{
+			name:                "untrusted timestamp without inclusion promise",
+			mediaType:           v03Suffix,
+			proof:               true,
+			cert:                "certificate",
+			untrustedTimestamps: true,
+			expected:            serrors.ErrorInvalidTimestamp,
+		},
+		{
+			name:      "entry of an untrusted log first",
+			mediaType: v03Suffix,
+			proof:     true,
+			cert:      "certificate",
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog) {
+				other := testBundleWithInclusionProof(t, testLogNew(t, 3, "other"), tsa, at)
+				material := b.GetVerificationMaterial()
+				material.TlogEntries = append(other.GetVerificationMaterial().GetTlogEntries(),
+					material.GetTlogEntries()...)
+			},
+		},
+		{
+			name:      "entry of another signature first",
+			mediaType: v03Suffix,
+			proof:     true,
+			cert:      "certificate",
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog) {
+				other := testBundleWithInclusionProof(t, log, tsa, at)
+				other.GetDsseEnvelope().GetSignatures()[0].Sig = []byte("other")
+				material := b.GetVerificationMaterial()
+				material.TlogEntries = append(other.GetVerificationMaterial().GetTlogEntries(),
+					material.GetTlogEntries()...)
+			},
+		},
+		{
+			name:      "no matching entry",
+			mediaType: v03Suffix,
+			proof:     true,
+			cert:      "certificate",
+			modify: func(t *testing.T, b *bundle_v1.Bundle, log *testLog) {
+				other := testBundleWithInclusionProof(t, testLogNew(t, 3, "other"), tsa, at)
+				material := b.GetVerificationMaterial()
+				material.TlogEntries = append(other.GetVerificationMaterial().GetTlogEntries(),
+					other.GetVerificationMaterial().GetTlogEntries()...)
+			},
+			expected: serrors.ErrorRekorPubKey,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			log := testLogNew(t, 5, "entry")
+			bundle := testBundleWithInclusionProof(t, log, tsa, at)
+			bundle.MediaType = tt.mediaType
+			entry := bundle.GetVerificationMaterial().GetTlogEntries()[0]
+			if tt.promise {
+				testAddInclusionPromise(t, log, entry, at)
+			}
+			if !tt.proof {
+				entry.InclusionProof = nil
+			}
+			switch tt.cert {
+			case "chain":
+				bundle.VerificationMaterial.Content = &bundle_v1.VerificationMaterial_X509CertificateChain{
+					X509CertificateChain: &common.X509CertificateChain{
+						Certificates: []*common.X509Certificate{{RawBytes: tsa.leaf.Raw}, {RawBytes: tsa.root.Raw}},
+					},
+				}
+			case "certificate":
+				bundle.VerificationMaterial.Content = &bundle_v1.VerificationMaterial_Certificate{
+					Certificate: &common.X509Certificate{RawBytes: tsa.leaf.Raw},
+				}
+			case "publicKey":
+				bundle.VerificationMaterial.Content = &bundle_v1.VerificationMaterial_PublicKey{
+					PublicKey: &common.PublicKeyIdentifier{Hint: "key"},
+				}
+			}
+			if tt.modify != nil {
+				tt.modify(t, bundle, log)
+			}
+			content, err := protojson.Marshal(bundle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !IsSigstoreBundle(content) {
+				t.Fatal("not a Sigstore bundle")
+			}
+
+			logID := hex.EncodeToString(testLogID(t, log.pubKey))
+			trustedRoot := &TrustedRoot{
+				RekorPubKeys: &cosign.TrustedTransparencyLogPubKeys{
+					Keys: map[string]cosign.TransparencyLogPubKey{
+						logID: {PubKey: log.pubKey, Status: tuf.Active},
+					},
+				},
+				TimestampAuthorities: []*TimestampAuthority{tsa.authority()},
+			}
+			if tt.untrustedTimestamps {
+				trustedRoot.TimestampAuthorities = []*TimestampAuthority{testTSANew(t).authority()}
+			}
+			signedAtt, err := verifyBundleAndEntryFromBytes(ctx, content, trustedRoot, nil, nil, true, tt.policy)
+			if !errCmp(err, tt.expected) {
+				t.Fatalf(cmp.Diff(err, tt.expected))
+			}
+			if err != nil {
+				return
+			}
+			if !signedAtt.SigningCert.Equal(tsa.leaf) {
+				t.Errorf("unexpected signing certificate %v", signedAtt.SigningCert.Subject)
+			}
+			if got := *signedAtt.RekorEntry.IntegratedTime; got != at.Unix() {
+				t.Errorf("integrated time %d, expected %d", got, at.Unix())
+			}
+			if got := len(signedAtt.Timestamps); (got == 0) != tt.untrustedTimestamps {
+				t.Errorf("%d verified timestamps", got)
+			}
+		})
+	}
+}
+
+func Test_verifyBundleCheckpoints(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	tsa := testTSANew(t)
+	at := time.Now().Add(-time.Minute).Truncate(time.Second)
+	witness, witnessKey := testWitness(t, "witness.example.com")
+	checkpoints, err := CheckpointConfigNew("", []string{witnessKey}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		// promise and proof are the verification data of the tlog entry.
+		promise  bool
+		proof    bool
+		cosigned bool
+		expected error
+	}{
+		{
+			name:     "inclusion proof co-signed",
+			proof:    true,
+			cosigned: true,
+		},
+		{
+			name:     "inclusion proof not co-signed",
+			proof:    true,
+			expected: serrors.ErrorMissingWitnessCosignature,
+		},
+		{
+			name:     "inclusion promise and proof co-signed",
+			promise:  true,
+			proof:    true,
+			cosigned: true,
+		},
+		{
+			name:     "inclusion promise and proof not co-signed",
+			promise:  true,
+			proof:    true,
+			expected: serrors.ErrorMissingWitnessCosignature,
+		},
+		{
+			name:     "inclusion promise only",
+			promise:  true,
+			expected: serrors.ErrorInvalidCheckpoint,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			log := testLogNew(t, 5, "entry")
+			bundle := testBundleWithInclusionProof(t, log, tsa, at)
+			entry := bundle.GetVerificationMaterial().GetTlogEntries()[0]
+			if tt.cosigned {
+				proof := entry.GetInclusionProof()
+				proof.Checkpoint.Envelope = log.checkpoint(t, uint64(proof.GetTreeSize()), witness)
+			}
+			if tt.promise {
+				testAddInclusionPromise(t, log, entry, at)
+			}
+			if !tt.proof {
+				bundle.MediaType = "application/vnd.dev.sigstore.bundle+json;version=0.1"
+				entry.InclusionProof = nil
+			}
+			trustedRoot := &TrustedRoot{
+				RekorPubKeys: &cosign.TrustedTransparencyLogPubKeys{
+					Keys: map[string]cosign.TransparencyLogPubKey{
+						hex.EncodeToString(testLogID(t, log.pubKey)): {PubKey: log.pubKey, Status: tuf.Active},
+					},
+				},
+				TimestampAuthorities: []*TimestampAuthority{tsa.authority()},
+			}
+
+			_, err := verifyBundleAndEntry(ctx, bundle, trustedRoot, nil, checkpoints, false, TimestampPolicyTlog)
+			if !errCmp(err, tt.expected) {
+				t.Fatalf(cmp.Diff(err, tt.expected))
+			}
+		})
+	}
+}
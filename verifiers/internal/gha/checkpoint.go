@@ -0,0 +1,292 @@
+package gha
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/util"
+	rverify "github.com/sigstore/rekor/pkg/verify"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"golang.org/x/mod/sumdb/note"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// CheckpointConfig configures the verification of the checkpoints, or
+// signed tree heads, of the transparency log entries. Beyond the signature
+// of the log, it protects against a log presenting a forked view.
+type CheckpointConfig struct {
+	statePath string
+	witnesses []note.Verifier
+	threshold int
+}
+
+// CheckpointConfigNew returns a checkpoint configuration.
+//
+// If statePath is set, the latest verified checkpoint of each log is
+// persisted to this file, and new checkpoints must be consistent with it.
+//
+// If witnesses are set, at least threshold of them must have co-signed
+// the checkpoint. Witness keys are note verifier keys, e.g.
+// witness.example.com+ba3a6e4f+AQ....
+func CheckpointConfigNew(statePath string, witnesses []string, threshold int) (*CheckpointConfig, error) {
+	cfg := &CheckpointConfig{
+		statePath: statePath,
+		threshold: threshold,
+	}
+	for _, key := range witnesses {
+		verifier, err := note.NewVerifier(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: witness key %q: %v", serrors.ErrorInvalidPublicKey, key, err)
+		}
+		cfg.witnesses = append(cfg.witnesses, verifier)
+	}
+	if len(cfg.witnesses) > 0 && (threshold < 1 || threshold > len(cfg.witnesses)) {
+		return nil, fmt.Errorf("%w: %d witnesses, threshold %d",
+			serrors.ErrorInvalidSignatureThreshold, len(cfg.witnesses), threshold)
+	}
+	return cfg, nil
+}
+
+// verifyCheckpoint verifies the checkpoint of the inclusion proof of an
+// entry, whose log signature is already verified.
+func (c *CheckpointConfig) verifyCheckpoint(ctx context.Context, rClient *client.Rekor,
+	e *models.LogEntryAnon,
+) error {
+	if e.Verification == nil || e.Verification.InclusionProof == nil ||
+		e.Verification.InclusionProof.Checkpoint == nil {
+		return fmt.Errorf("%w: no checkpoint in the inclusion proof", serrors.ErrorInvalidCheckpoint)
+	}
+	text := *e.Verification.InclusionProof.Checkpoint
+
+	var sth util.SignedCheckpoint
+	if err := sth.UnmarshalText([]byte(text)); err != nil {
+		return fmt.Errorf("%w: %v", serrors.ErrorInvalidCheckpoint, err)
+	}
+
+	if err := c.verifyWitnesses(&sth); err != nil {
+		return err
+	}
+
+	if c.statePath == "" {
+		return nil
+	}
+	return c.verifyConsistency(ctx, rClient, &sth, text)
+}
+
+// verifyWitnesses verifies that enough witnesses co-signed the checkpoint.
+func (c *CheckpointConfig) verifyWitnesses(sth *util.SignedCheckpoint) error {
+	if len(c.witnesses) == 0 {
+		return nil
+	}
+
+	cosigned := 0
+	for _, witness := range c.witnesses {
+		for _, sig := range sth.Signatures {
+			if sig.Name != witness.Name() || sig.Hash != witness.KeyHash() {
+				continue
+			}
+			sigBytes, err := base64.StdEncoding.DecodeString(sig.Base64)
+			if err != nil {
+				continue
+			}
+			if witness.Verify([]byte(sth.Note), sigBytes) {
+				cosigned++
+				break
+			}
+		}
+	}
+	if cosigned < c.threshold {
+		return fmt.Errorf("%w: %d co-signatures, expected %d",
+			serrors.ErrorMissingWitnessCosignature, cosigned, c.threshold)
+	}
+	return nil
+}
+
+// checkpointState is the format of the state file: the latest verified
+// checkpoint of each log, by origin.
+type checkpointState struct {
+	Checkpoints map[string]string `json:"checkpoints"`
+}
+
+// checkpointStateMu serializes the updates of state files.
+var checkpointStateMu sync.Mutex
+
+// verifyConsistency verifies that the checkpoint is consistent with the
+// latest verified checkpoint of the log, and persists it if it is newer.
+func (c *CheckpointConfig) verifyConsistency(ctx context.Context, rClient *client.Rekor,
+	sth *util.SignedCheckpoint, text string,
+) error {
+	checkpointStateMu.Lock()
+	defer checkpointStateMu.Unlock()
+
+	state, err := readCheckpointState(c.statePath)
+	if err != nil {
+		return err
+	}
+
+	if prevText, ok := state.Checkpoints[sth.Origin]; ok {
+		var prev util.SignedCheckpoint
+		if err := prev.UnmarshalText([]byte(prevText)); err != nil {
+			return fmt.Errorf("%w: %s: %v", serrors.ErrorInvalidCheckpoint, c.statePath, err)
+		}
+
+		treeID, err := treeIDFromOrigin(sth.Origin)
+		if err != nil {
+			return err
+		}
+
+		// A checkpoint older than the persisted one must be consistent
+		// with it too, or the log is presenting another view.
+		older, newer := &prev, sth
+		if prev.Size > sth.Size {
+			older, newer = sth, &prev
+		}
+		if older.Size > 0 {
+			if err := rverify.ProveConsistency(ctx, rClient, older, newer, treeID); err != nil {
+				return fmt.Errorf("%w: log %q from size %d to %d: %v",
+					serrors.ErrorInconsistentCheckpoint, sth.Origin, older.Size, newer.Size, err)
+			}
+		}
+		if prev.Size >= sth.Size {
+			return nil
+		}
+	}
+
+	state.Checkpoints[sth.Origin] = text
+	return writeCheckpointState(c.statePath, state)
+}
+
+// treeIDFromOrigin returns the tree ID of a Rekor checkpoint origin, e.g.
+// "rekor.sigstore.dev - 1193050959916656506".
+func treeIDFromOrigin(origin string) (string, error) {
+	i := strings.LastIndex(origin, " - ")
+	if i < 0 || i+3 == len(origin) {
+		return "", fmt.Errorf("%w: no tree ID in origin %q", serrors.ErrorInvalidCheckpoint, origin)
+	}
+	return origin[i+3:], nil
+}
+
+func readCheckpointState(path string) (*checkpointState, error) {
+	state := &checkpointState{Checkpoints: make(map[string]string)}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", serrors.ErrorInvalidFormat, path, err)
+	}
+	if state.Checkpoints == nil {
+		state.Checkpoints = make(map[string]string)
+	}
+	return state, nil
+}
+
+// writeCheckpointState writes the state file atomically, so that an
+// interrupted write does not lose the previous state.
+func writeCheckpointState(path string, state *checkpointState) error {
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// verifyLogCheckpoint verifies that the checkpoint is signed by the log
+// and commits to the tree of the inclusion proof. Checkpoints may carry
+// other signatures, e.g. witness co-signatures, so one valid signature of
+// the log is enough.
+func verifyLogCheckpoint(text string, verifier signature.Verifier, treeSize uint64, rootHash []byte) error {
+	var sth util.SignedCheckpoint
+	if err := sth.UnmarshalText([]byte(text)); err != nil {
+		return fmt.Errorf("%w: %v", serrors.ErrorInvalidCheckpoint, err)
+	}
+	if sth.Size != treeSize || !bytes.Equal(sth.Hash, rootHash) {
+		return fmt.Errorf("%w: checkpoint of size %d does not match the inclusion proof of size %d",
+			serrors.ErrorInvalidCheckpoint, sth.Size, treeSize)
+	}
+
+	for _, sig := range sth.Signatures {
+		signed := util.SignedNote{Note: sth.Note, Signatures: []note.Signature{sig}}
+		if signed.Verify(verifier) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no valid signature of the log", serrors.ErrorInvalidCheckpoint)
+}
+
+// withLogSignatures returns the entry with only the signatures of the log
+// key on its checkpoint, so that witness co-signatures do not fail the
+// verification of the log signature. Invalid checkpoints are returned as
+// is, to fail this verification.
+func withLogSignatures(e models.LogEntryAnon, pubKey crypto.PublicKey) models.LogEntryAnon {
+	if e.Verification == nil || e.Verification.InclusionProof == nil ||
+		e.Verification.InclusionProof.Checkpoint == nil {
+		return e
+	}
+
+	var sth util.SignedCheckpoint
+	if err := sth.UnmarshalText([]byte(*e.Verification.InclusionProof.Checkpoint)); err != nil {
+		return e
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		return e
+	}
+	keyHash := sha256.Sum256(der)
+	logHash := binary.BigEndian.Uint32(keyHash[:])
+
+	var sigs []note.Signature
+	for _, sig := range sth.Signatures {
+		if sig.Hash == logHash {
+			sigs = append(sigs, sig)
+		}
+	}
+	if len(sigs) == 0 || len(sigs) == len(sth.Signatures) {
+		return e
+	}
+
+	sth.Signatures = sigs
+	text := sth.SignedNote.String()
+	verification := *e.Verification
+	inclusionProof := *verification.InclusionProof
+	inclusionProof.Checkpoint = &text
+	verification.InclusionProof = &inclusionProof
+	e.Verification = &verification
+	return e
+}
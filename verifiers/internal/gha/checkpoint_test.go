@@ -0,0 +1,369 @@
+package gha
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/util"
+	rverify "github.com/sigstore/rekor/pkg/verify"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"github.com/transparency-dev/merkle/testonly"
+	"golang.org/x/mod/sumdb/note"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+const testTreeID = 1193050959916656506
+
+// testLog is a local stand-in for a Rekor log.
+type testLog struct {
+	tree   *testonly.Tree
+	signer signature.Signer
+	pubKey crypto.PublicKey
+}
+
+func testLogNew(t *testing.T, size int, prefix string) *testLog {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := signature.LoadECDSASigner(key, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree := testonly.New(rfc6962.DefaultHasher)
+	for i := 0; i < size; i++ {
+		tree.AppendData([]byte(fmt.Sprintf("%s%d", prefix, i)))
+	}
+	return &testLog{tree: tree, signer: signer, pubKey: key.Public()}
+}
+
+// checkpoint returns the checkpoint of the log at size, signed by the log
+// and co-signed by the witnesses.
+func (l *testLog) checkpoint(t *testing.T, size uint64, witnesses ...note.Signer) string {
+	t.Helper()
+
+	text, err := util.CreateAndSignCheckpoint(context.Background(), "rekor.example.com",
+		testTreeID, size, l.tree.HashAt(size), l.signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(witnesses) == 0 {
+		return string(text)
+	}
+
+	var sth util.SignedCheckpoint
+	if err := sth.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	for _, witness := range witnesses {
+		sig, err := witness.Sign([]byte(sth.Note))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sth.Signatures = append(sth.Signatures, note.Signature{
+			Name:   witness.Name(),
+			Hash:   witness.KeyHash(),
+			Base64: base64.StdEncoding.EncodeToString(sig),
+		})
+	}
+	return sth.SignedNote.String()
+}
+
+// client returns a client of a Rekor server serving consistency proofs of
+// the log.
+func (l *testLog) client(t *testing.T) *client.Rekor {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/log/proof" || r.URL.Query().Get("treeID") != strconv.Itoa(testTreeID) {
+			http.NotFound(w, r)
+			return
+		}
+		first, _ := strconv.ParseUint(r.URL.Query().Get("firstSize"), 10, 64)
+		last, _ := strconv.ParseUint(r.URL.Query().Get("lastSize"), 10, 64)
+		proof, err := l.tree.ConsistencyProof(first, last)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rootHash := hex.EncodeToString(l.tree.HashAt(last))
+		resp := models.ConsistencyProof{RootHash: &rootHash}
+		for _, h := range proof {
+			resp.Hashes = append(resp.Hashes, hex.EncodeToString(h))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	rClient, err := rekorClientNew(srv.URL, &Config{Retry: &RetryPolicy{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rClient
+}
+
+func entryWithCheckpoint(checkpoint string) *models.LogEntryAnon {
+	return &models.LogEntryAnon{
+		Verification: &models.LogEntryAnonVerification{
+			InclusionProof: &models.InclusionProof{Checkpoint: &checkpoint},
+		},
+	}
+}
+
+func testWitness(t *testing.T, name string) (note.Signer, string) {
+	t.Helper()
+
+	skey, vkey, err := note.GenerateKey(rand.Reader, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := note.NewSigner(skey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, vkey
+}
+
+func Test_CheckpointConfigNew(t *testing.T) {
+	t.Parallel()
+
+	_, vkey := testWitness(t, "witness.example.com")
+	tests := []struct {
+		name      string
+		witnesses []string
+		threshold int
+		expected  error
+	}{
+		{
+			name: "state only",
+		},
+		{
+			name:      "witness",
+			witnesses: []string{vkey},
+			threshold: 1,
+		},
+		{
+			name:      "invalid witness key",
+			witnesses: []string{"witness.example.com+00000000+AQ"},
+			threshold: 1,
+			expected:  serrors.ErrorInvalidPublicKey,
+		},
+		{
+			name:      "zero threshold",
+			witnesses: []string{vkey},
+			expected:  serrors.ErrorInvalidSignatureThreshold,
+		},
+		{
+			name:      "threshold above witnesses",
+			witnesses: []string{vkey},
+			threshold: 2,
+			expected:  serrors.ErrorInvalidSignatureThreshold,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := CheckpointConfigNew("state.json", tt.witnesses, tt.threshold)
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Errorf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
+
+func Test_verifyCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	log := testLogNew(t, 8, "entry")
+	// fork shares the first 3 entries of log.
+	fork := testLogNew(t, 3, "entry")
+	for i := 3; i < 8; i++ {
+		fork.tree.AppendData([]byte(fmt.Sprintf("fork%d", i)))
+	}
+	fork.signer, fork.pubKey = log.signer, log.pubKey
+
+	witness1, vkey1 := testWitness(t, "witness1.example.com")
+	witness2, vkey2 := testWitness(t, "witness2.example.com")
+
+	tests := []struct {
+		name      string
+		persisted string
+		witnesses []string
+		threshold int
+		// checkpoint is the checkpoint of the entry.
+		checkpoint string
+		// state is the size of the persisted checkpoint after verification.
+		state    uint64
+		expected error
+	}{
+		{
+			name:       "first checkpoint",
+			checkpoint: log.checkpoint(t, 5),
+			state:      5,
+		},
+		{
+			name:       "newer consistent checkpoint",
+			persisted:  log.checkpoint(t, 3),
+			checkpoint: log.checkpoint(t, 5),
+			state:      5,
+		},
+		{
+			name:       "older consistent checkpoint",
+			persisted:  log.checkpoint(t, 5),
+			checkpoint: log.checkpoint(t, 3),
+			state:      5,
+		},
+		{
+			name:       "same checkpoint",
+			persisted:  log.checkpoint(t, 5),
+			checkpoint: log.checkpoint(t, 5),
+			state:      5,
+		},
+		{
+			name:       "newer forked checkpoint",
+			persisted:  fork.checkpoint(t, 4),
+			checkpoint: log.checkpoint(t, 6),
+			state:      4,
+			expected:   serrors.ErrorInconsistentCheckpoint,
+		},
+		{
+			name:       "forked checkpoint of same size",
+			persisted:  fork.checkpoint(t, 5),
+			checkpoint: log.checkpoint(t, 5),
+			state:      5,
+			expected:   serrors.ErrorInconsistentCheckpoint,
+		},
+		{
+			name:       "co-signed checkpoint",
+			witnesses:  []string{vkey1, vkey2},
+			threshold:  1,
+			checkpoint: log.checkpoint(t, 5, witness2),
+			state:      5,
+		},
+		{
+			name:       "not enough co-signatures",
+			witnesses:  []string{vkey1, vkey2},
+			threshold:  2,
+			checkpoint: log.checkpoint(t, 5, witness1),
+			expected:   serrors.ErrorMissingWitnessCosignature,
+		},
+		{
+			name:       "co-signed by other witness",
+			witnesses:  []string{vkey1},
+			threshold:  1,
+			checkpoint: log.checkpoint(t, 5, witness2),
+			expected:   serrors.ErrorMissingWitnessCosignature,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			statePath := filepath.Join(t.TempDir(), "checkpoints.json")
+			var origin string
+			if tt.persisted != "" {
+				var sth util.SignedCheckpoint
+				if err := sth.UnmarshalText([]byte(tt.persisted)); err != nil {
+					t.Fatal(err)
+				}
+				origin = sth.Origin
+				state := &checkpointState{Checkpoints: map[string]string{origin: tt.persisted}}
+				if err := writeCheckpointState(statePath, state); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cfg, err := CheckpointConfigNew(statePath, tt.witnesses, tt.threshold)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = cfg.verifyCheckpoint(context.Background(), log.client(t), entryWithCheckpoint(tt.checkpoint))
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Fatalf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+			if tt.state == 0 {
+				if _, err := os.Stat(statePath); err == nil && tt.persisted == "" {
+					t.Errorf("unexpected state file")
+				}
+				return
+			}
+
+			state, err := readCheckpointState(statePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(state.Checkpoints) != 1 {
+				t.Fatalf("unexpected state %v", state.Checkpoints)
+			}
+			for _, text := range state.Checkpoints {
+				var sth util.SignedCheckpoint
+				if err := sth.UnmarshalText([]byte(text)); err != nil {
+					t.Fatal(err)
+				}
+				if sth.Size != tt.state {
+					t.Errorf("persisted size %d, expected %d", sth.Size, tt.state)
+				}
+			}
+		})
+	}
+}
+
+func Test_withLogSignatures(t *testing.T) {
+	t.Parallel()
+
+	log := testLogNew(t, 4, "entry")
+	witness, _ := testWitness(t, "witness.example.com")
+	e := entryWithCheckpoint(log.checkpoint(t, 4, witness))
+	rootHash := hex.EncodeToString(log.tree.HashAt(4))
+	e.Verification.InclusionProof.RootHash = &rootHash
+
+	verifier, err := signature.LoadVerifier(log.pubKey, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The co-signature fails the verification of the log signature.
+	if err := rverify.VerifyCheckpointSignature(e, verifier); err == nil {
+		t.Fatalf("expected co-signed checkpoint to fail verification")
+	}
+
+	logEntry := withLogSignatures(*e, log.pubKey)
+	if err := rverify.VerifyCheckpointSignature(&logEntry, verifier); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// The entry itself keeps the co-signature.
+	var sth util.SignedCheckpoint
+	if err := sth.UnmarshalText([]byte(*e.Verification.InclusionProof.Checkpoint)); err != nil {
+		t.Fatal(err)
+	}
+	if len(sth.Signatures) != 2 {
+		t.Errorf("expected 2 signatures, got %d", len(sth.Signatures))
+	}
+}
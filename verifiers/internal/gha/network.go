@@ -0,0 +1,138 @@
+package gha
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	httptransport "github.com/go-openapi/runtime/client"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/go-retryablehttp"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	"github.com/sigstore/rekor/pkg/generated/client"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// RetryPolicy is the retry policy of failed requests, with exponential
+// backoff.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+
+	// MinWait is the wait before the first retry. It doubles for each
+	// further retry, up to MaxWait.
+	MinWait time.Duration
+	MaxWait time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of requests to Rekor.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinWait:    time.Second,
+	MaxWait:    30 * time.Second,
+}
+
+// rekorClientNew returns a client for the Rekor instance at addr.
+func rekorClientNew(addr string, cfg *Config) (*client.Rekor, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", serrors.ErrorInternal, err)
+	}
+	if u.Path == "" {
+		u.Path = client.DefaultBasePath
+	}
+
+	var transport http.RoundTripper = cleanhttp.DefaultTransport()
+	if cfg.HTTPClient != nil && cfg.HTTPClient.Transport != nil {
+		transport = cfg.HTTPClient.Transport
+	}
+	retry := DefaultRetryPolicy
+	if cfg.Retry != nil {
+		retry = *cfg.Retry
+	}
+
+	retryableClient := retryablehttp.NewClient()
+	retryableClient.HTTPClient = &http.Client{
+		Transport: transport,
+		// The timeout applies to each attempt.
+		Timeout: cfg.RequestTimeout,
+	}
+	retryableClient.RetryMax = retry.MaxRetries
+	retryableClient.RetryWaitMin = retry.MinWait
+	retryableClient.RetryWaitMax = retry.MaxWait
+	retryableClient.Logger = nil
+
+	rt := httptransport.NewWithClient(u.Host, u.Path, []string{u.Scheme}, retryableClient.StandardClient())
+	return client.New(rt, nil), nil
+}
+
+// registryOptions returns the options of requests to OCI registries.
+func registryOptions(ctx context.Context, cfg *Config) ociremote.Option {
+	opts := []remote.Option{
+		// Keep the default authentication of cosign.
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+		remote.WithContext(ctx),
+	}
+
+	var transport http.RoundTripper
+	if cfg.HTTPClient != nil {
+		transport = cfg.HTTPClient.Transport
+	}
+	if cfg.RequestTimeout > 0 {
+		if transport == nil {
+			transport = remote.DefaultTransport
+		}
+		transport = &timeoutTransport{base: transport, timeout: cfg.RequestTimeout}
+	}
+	if transport != nil {
+		opts = append(opts, remote.WithTransport(transport))
+	}
+
+	if cfg.Retry != nil {
+		opts = append(opts, remote.WithRetryBackoff(remote.Backoff{
+			Duration: cfg.Retry.MinWait,
+			Factor:   2,
+			Jitter:   0.1,
+			// Steps is the number of attempts.
+			Steps: cfg.Retry.MaxRetries + 1,
+			Cap:   cfg.Retry.MaxWait,
+		}))
+	}
+	return ociremote.WithRemoteOptions(opts...)
+}
+
+// timeoutTransport limits the time of each request, including reading the
+// response body.
+type timeoutTransport struct {
+	base    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelBody cancels the context of the request when the body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
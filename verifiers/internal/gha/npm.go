@@ -68,6 +68,7 @@ func (b *BundleBytes) UnmarshalJSON(data []byte) error {
 type Npm struct {
 	ctx                   context.Context
 	root                  *TrustedRoot
+	timestampPolicy       TimestampPolicy
 	verifiedBuilderID     *utils.TrustedBuilderID
 	verifiedProvenanceAtt *SignedAttestation
 	verifiedPublishAtt    *SignedAttestation
@@ -129,7 +130,8 @@ func extractAttestations(attestations []attestation) (*attestation, *attestation
 
 func (n *Npm) verifyProvenanceAttestationSignature() error {
 	// Re-use the standard bundle verification.
-	signedProvenance, err := VerifyProvenanceBundle(n.ctx, n.provenanceAttestation.BundleBytes, n.root)
+	signedProvenance, err := VerifyProvenanceBundle(n.ctx, n.provenanceAttestation.BundleBytes, n.root, nil, nil,
+		n.timestampPolicy)
 	if err != nil {
 		return err
 	}
@@ -139,7 +141,8 @@ func (n *Npm) verifyProvenanceAttestationSignature() error {
 
 func (n *Npm) verifyPublishAttestationSignature() error {
 	// First verify the bundle and its rekor entry.
-	signedPublish, err := verifyBundleAndEntryFromBytes(n.ctx, n.publishAttestation.BundleBytes, n.root, false)
+	signedPublish, err := verifyBundleAndEntryFromBytes(n.ctx, n.publishAttestation.BundleBytes, n.root, nil, nil, false,
+		n.timestampPolicy)
 	if err != nil {
 		return err
 	}
@@ -155,7 +158,7 @@ func (n *Npm) verifyPublishAttestationSignature() error {
 		return err
 	}
 
-	_, err = envVerifier.Verify(context.Background(), signedPublish.Envelope)
+	_, err = envVerifier.Verify(n.ctx, signedPublish.Envelope)
 	if err != nil {
 		return fmt.Errorf("%w: %w", serrors.ErrorInvalidSignature, err)
 	}
@@ -272,7 +275,7 @@ func (n *Npm) verifyBuilderID(
 	defaultBuilders map[string]bool,
 ) (*utils.TrustedBuilderID, error) {
 	// Verify certificate information.
-	builder, err := verifyNpmEnvAndCert(
+	builder, err := verifyNpmEnvAndCert(n.ctx,
 		n.ProvenanceEnvelope(),
 		n.ProvenanceLeafCertificate(),
 		provenanceOpts, builderOpts,
@@ -397,6 +400,17 @@ func (n *Npm) verifyPublishAttestationSubjectDigest(expectedHash string) error {
 	return fmt.Errorf("expected hash '%s' not found: %w", expectedHash, serrors.ErrorMismatchHash)
 }
 
+func (n *Npm) verifyPublishAttestationSubjectName(expectedHash, expectedName string) error {
+	publishSubjects, err := subjectsFromAttestation(n.verifiedPublishAtt)
+	if err != nil {
+		return err
+	}
+
+	// 8 bit represented in hex, so 8/2=4.
+	expectedAlgo := fmt.Sprintf("sha%v", len(expectedHash)*4)
+	return utils.VerifySubjectName(publishSubjects, map[string]string{expectedAlgo: expectedHash}, expectedName)
+}
+
 func verifyPublishSubjectVersion(att *SignedAttestation, expectedVersion string) error {
 	_, version, err := publishPredicateData(att)
 	if err != nil {
@@ -5,8 +5,8 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
+	"time"
 
 	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
 	"github.com/sigstore/rekor/pkg/generated/client"
@@ -14,7 +14,9 @@ import (
 
 	"github.com/slsa-framework/slsa-github-generator/signing/envelope"
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/logging"
 	"github.com/slsa-framework/slsa-verifier/v2/options"
+	sprovenance "github.com/slsa-framework/slsa-verifier/v2/provenance"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/iface"
@@ -28,8 +30,10 @@ type SignedAttestation struct {
 	Envelope *dsselib.Envelope
 	// The signing certificate
 	SigningCert *x509.Certificate
-	// The associated verified Rekor entry
+	// The associated verified Rekor entry, if any
 	RekorEntry *models.LogEntryAnon
+	// The times of the verified RFC 3161 timestamps of the signature, if any
+	Timestamps []time.Time
 }
 
 // EnvelopeFromBytes reads a DSSE envelope from the given payload.
@@ -204,10 +208,29 @@ func verifyDigest(prov iface.Provenance, expectedHash string) error {
 	return fmt.Errorf("expected hash '%s' not found: %w", expectedHash, serrors.ErrorMismatchHash)
 }
 
+func verifyDigests(prov iface.Provenance, expectedDigests map[string]string) error {
+	subjects, err := prov.Subjects()
+	if err != nil {
+		return err
+	}
+
+	return utils.VerifySubjectDigests(subjects, expectedDigests)
+}
+
+func verifySubjectName(prov iface.Provenance, expectedDigests map[string]string, expectedName string) error {
+	subjects, err := prov.Subjects()
+	if err != nil {
+		return err
+	}
+
+	return utils.VerifySubjectName(subjects, expectedDigests, expectedName)
+}
+
 // VerifyProvenanceSignature returns the verified DSSE envelope containing the provenance
-// and the signing certificate given the provenance and artifact hash.
+// and the signing certificate given the provenance and artifact hash. If checkpoints
+// is set, the checkpoint of the transparency log entry is verified too.
 func VerifyProvenanceSignature(ctx context.Context, trustedRoot *TrustedRoot,
-	rClient *client.Rekor,
+	rClient *client.Rekor, checkpoints *CheckpointConfig,
 	provenance []byte, artifactHash string) (
 	*SignedAttestation, error,
 ) {
@@ -215,15 +238,15 @@ func VerifyProvenanceSignature(ctx context.Context, trustedRoot *TrustedRoot,
 	// to use the Redis index for searching by artifact SHA.
 	if hasCertInEnvelope(provenance) {
 		// Get Rekor entries corresponding to provenance
-		return GetValidSignedAttestationWithCert(rClient, provenance, trustedRoot)
+		return GetValidSignedAttestationWithCert(ctx, rClient, provenance, trustedRoot, checkpoints)
 	}
 
 	// Fallback on using the redis search index to get matching UUIDs.
-	fmt.Fprintf(os.Stderr, "No certificate provided, trying Redis search index to find entries by subject digest\n")
+	logging.FromContext(ctx).Info("No certificate provided, trying Redis search index to find entries by subject digest")
 
 	// Verify the provenance and return the signing certificate.
 	return SearchValidSignedAttestation(ctx, artifactHash,
-		provenance, rClient, trustedRoot)
+		provenance, rClient, trustedRoot, checkpoints)
 }
 
 // VerifyNpmPackageProvenance verifies provenance for an npm package.
@@ -329,6 +352,35 @@ func isValidDelegatorBuilderID(prov iface.Provenance) error {
 	return utils.IsValidBuilderTag(builderRef, false)
 }
 
+// verifiedProvenance returns the provenance in the verified envelope, with
+// the source the builder recorded.
+func verifiedProvenance(env *dsselib.Envelope, trustedBuilderID *utils.TrustedBuilderID) (*sprovenance.Provenance, error) {
+	payload, err := utils.PayloadFromEnvelope(env)
+	if err != nil {
+		return nil, err
+	}
+	verified, err := sprovenance.FromStatement(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	prov, err := slsaprovenance.ProvenanceFromEnvelope(trustedBuilderID.Name(), env)
+	if err != nil {
+		return nil, err
+	}
+	// Source fields missing from the provenance, or that cannot be parsed,
+	// are left empty rather than failing the verification.
+	if sourceURI, err := prov.SourceURI(); err == nil {
+		if uri, ref, err := utils.ParseGitURIAndRef(sourceURI); err == nil {
+			verified.Source.URI, verified.Source.Ref = uri, ref
+		}
+	}
+	if commit, err := prov.SourceCommit(); err == nil {
+		verified.Source.Commit = commit
+	}
+	return verified, nil
+}
+
 // builderID returns the trusted builder ID from the provenance.
 // The certTrustedBuilderID input is from the Fulcio certificate.
 func builderID(env *dsselib.Envelope, certTrustedBuilderID *utils.TrustedBuilderID) (*utils.TrustedBuilderID, error) {
@@ -392,6 +444,34 @@ func VerifyProvenance(env *dsselib.Envelope, provenanceOpts *options.ProvenanceO
 	return VerifyProvenanceCommonOptions(prov, provenanceOpts)
 }
 
+// provenanceOptionsChecks returns the checks VerifyProvenanceCommonOptions
+// performs for the options.
+func provenanceOptionsChecks(provenanceOpts *options.ProvenanceOpts) []utils.Check {
+	checks := []utils.Check{utils.CheckSourceURI, utils.CheckSubjectDigest}
+	if provenanceOpts.ExpectedSubjectName != nil {
+		checks = append(checks, utils.CheckSubjectName)
+	}
+	if provenanceOpts.ExpectedSourceCommit != nil {
+		checks = append(checks, utils.CheckSourceCommit)
+	}
+	if provenanceOpts.SourceCheckout != nil {
+		checks = append(checks, utils.CheckSourceCheckout)
+	}
+	if provenanceOpts.ExpectedBranch != nil {
+		checks = append(checks, utils.CheckBranch)
+	}
+	if provenanceOpts.ExpectedTag != nil {
+		checks = append(checks, utils.CheckTag)
+	}
+	if provenanceOpts.ExpectedVersionedTag != nil {
+		checks = append(checks, utils.CheckVersionedTag)
+	}
+	if len(provenanceOpts.ExpectedWorkflowInputs) > 0 {
+		checks = append(checks, utils.CheckWorkflowInputs)
+	}
+	return checks
+}
+
 // VerifyProvenanceCommonOptions verifies the given provenance.
 func VerifyProvenanceCommonOptions(prov iface.Provenance, provenanceOpts *options.ProvenanceOpts) error {
 	// Verify source.
@@ -400,10 +480,35 @@ func VerifyProvenanceCommonOptions(prov iface.Provenance, provenanceOpts *option
 	}
 
 	// Verify subject digest.
-	if err := verifyDigest(prov, provenanceOpts.ExpectedDigest); err != nil {
+	if len(provenanceOpts.ExpectedDigests) > 0 {
+		if err := verifyDigests(prov, provenanceOpts.ExpectedDigests); err != nil {
+			return err
+		}
+	} else if err := verifyDigest(prov, provenanceOpts.ExpectedDigest); err != nil {
 		return err
 	}
 
+	// Verify subject name.
+	if provenanceOpts.ExpectedSubjectName != nil {
+		if err := verifySubjectName(prov, utils.ExpectedDigests(provenanceOpts), *provenanceOpts.ExpectedSubjectName); err != nil {
+			return err
+		}
+	}
+
+	// Verify the source commit.
+	if provenanceOpts.ExpectedSourceCommit != nil {
+		if err := VerifySourceCommit(prov, *provenanceOpts.ExpectedSourceCommit); err != nil {
+			return err
+		}
+	}
+
+	// Verify the provenance against the local source checkout.
+	if provenanceOpts.SourceCheckout != nil {
+		if err := VerifySourceCheckout(prov, *provenanceOpts.SourceCheckout, provenanceOpts.ExpectedBranch); err != nil {
+			return err
+		}
+	}
+
 	// Verify the branch.
 	if provenanceOpts.ExpectedBranch != nil {
 		if err := VerifyBranch(prov, *provenanceOpts.ExpectedBranch); err != nil {
@@ -459,6 +564,46 @@ func VerifyWorkflowInputs(prov iface.Provenance, inputs map[string]string) error
 	return nil
 }
 
+// VerifySourceCommit verifies that the source commit in the provenance
+// matches the expected value, which may be abbreviated.
+func VerifySourceCommit(prov iface.Provenance, expectedCommit string) error {
+	commit, err := prov.SourceCommit()
+	if err != nil {
+		return err
+	}
+
+	return utils.VerifySourceCommit(commit, expectedCommit)
+}
+
+// VerifySourceCheckout verifies that the source commit and tag in the
+// provenance are consistent with a local git checkout, and that the commit
+// is an ancestor of the expected branch, or of the default branch if nil.
+func VerifySourceCheckout(prov iface.Provenance, path string, expectedBranch *string) error {
+	commit, err := prov.SourceCommit()
+	if err != nil {
+		return err
+	}
+
+	ref, err := prov.GetTag()
+	if err != nil {
+		return err
+	}
+	var tag string
+	if ref != "" {
+		tag, err = utils.TagFromGitRef(ref)
+		if err != nil {
+			return fmt.Errorf("verifying tag: %w", err)
+		}
+	}
+
+	var branch string
+	if expectedBranch != nil {
+		branch = *expectedBranch
+	}
+
+	return utils.VerifySourceCheckout(path, commit, tag, branch)
+}
+
 // VerifyBranch verifies that the source branch in the provenance matches the
 // expected value.
 func VerifyBranch(prov iface.Provenance, expectedBranch string) error {
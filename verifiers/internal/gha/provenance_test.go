@@ -1,6 +1,8 @@
 package gha
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -11,10 +13,12 @@ import (
 	slsacommon "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
 	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
 	slsa1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
 	"github.com/slsa-framework/slsa-verifier/v2/options"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	sprovenance "github.com/slsa-framework/slsa-verifier/v2/provenance"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/iface"
 )
@@ -25,6 +29,7 @@ type testProvenance struct {
 	builderID         string
 	buildType         string
 	sourceURI         string
+	sourceCommit      string
 	triggerURI        string
 	subjects          []intoto.Subject
 	branch            string
@@ -41,6 +46,7 @@ type testProvenance struct {
 func (p *testProvenance) BuilderID() (string, error)           { return p.builderID, nil }
 func (p *testProvenance) BuildType() (string, error)           { return p.buildType, nil }
 func (p *testProvenance) SourceURI() (string, error)           { return p.sourceURI, nil }
+func (p *testProvenance) SourceCommit() (string, error)        { return p.sourceCommit, nil }
 func (p *testProvenance) TriggerURI() (string, error)          { return p.triggerURI, nil }
 func (p *testProvenance) Subjects() ([]intoto.Subject, error)  { return p.subjects, nil }
 func (p *testProvenance) GetBranch() (string, error)           { return p.branch, nil }
@@ -225,6 +231,58 @@ func Test_VerifyDigest(t *testing.T) {
 	}
 }
 
+func Test_verifySubjectName(t *testing.T) {
+	t.Parallel()
+	sha256 := "2ce3f90facdb51aeb950d5bc641e981be61fdf482ce3f90facdb51aeb950d5bc"
+	sha512 := sha256 + sha256
+	prov := &testProvenance{
+		subjects: []intoto.Subject{
+			{
+				Name:   "tool-linux-amd64",
+				Digest: slsacommon.DigestSet{"sha256": sha256},
+			},
+			{
+				Name:   "pkg:npm/%40scope%2Ftool@1.0.0",
+				Digest: slsacommon.DigestSet{"sha512": sha512},
+			},
+		},
+	}
+	tests := []struct {
+		name     string
+		hash     string
+		subject  string
+		expected error
+	}{
+		{
+			name:    "sha256 name match",
+			hash:    sha256,
+			subject: "tool-linux-amd64",
+		},
+		{
+			name:     "sha256 name mismatch",
+			hash:     sha256,
+			subject:  "tool-windows.exe",
+			expected: serrors.ErrorMismatchSubjectName,
+		},
+		{
+			name:    "sha512 glob match",
+			hash:    sha512,
+			subject: "pkg:npm/*",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := verifySubjectName(prov, utils.ExpectedDigests(&options.ProvenanceOpts{ExpectedDigest: tt.hash}), tt.subject); !errCmp(err, tt.expected) {
+				t.Errorf(cmp.Diff(err, tt.expected))
+			}
+		})
+	}
+}
+
 func Test_verifySourceURI(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -630,6 +688,93 @@ func Test_VerifyBranch(t *testing.T) {
 	}
 }
 
+func Test_VerifySourceCommit(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		prov     iface.Provenance
+		commit   string
+		expected error
+	}{
+		{
+			name: "full commit match",
+			prov: &testProvenance{
+				sourceCommit: "2ce3f90facdb51aeb950d5bc641e981be61fdf48",
+			},
+			commit: "2ce3f90facdb51aeb950d5bc641e981be61fdf48",
+		},
+		{
+			name: "abbreviated commit match",
+			prov: &testProvenance{
+				sourceCommit: "2ce3f90facdb51aeb950d5bc641e981be61fdf48",
+			},
+			commit: "2ce3f90facdb",
+		},
+		{
+			name: "commit mismatch",
+			prov: &testProvenance{
+				sourceCommit: "2ce3f90facdb51aeb950d5bc641e981be61fdf48",
+			},
+			commit:   "3ce3f90facdb",
+			expected: serrors.ErrorMismatchSourceCommit,
+		},
+		{
+			name:     "commit empty",
+			prov:     &testProvenance{},
+			commit:   "2ce3f90facdb",
+			expected: serrors.ErrorMismatchSourceCommit,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := VerifySourceCommit(tt.prov, tt.commit); !errCmp(err, tt.expected) {
+				t.Errorf(cmp.Diff(err, tt.expected))
+			}
+		})
+	}
+}
+
+func Test_VerifySourceCheckout(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		prov     iface.Provenance
+		expected error
+	}{
+		{
+			name: "not a repository",
+			prov: &testProvenance{
+				sourceCommit: "2ce3f90facdb51aeb950d5bc641e981be61fdf48",
+				tag:          "refs/tags/v1.0.0",
+			},
+			expected: serrors.ErrorInvalidSourceCheckout,
+		},
+		{
+			name: "invalid tag ref",
+			prov: &testProvenance{
+				sourceCommit: "2ce3f90facdb51aeb950d5bc641e981be61fdf48",
+				tag:          "refs/heads/main",
+			},
+			expected: serrors.ErrorInvalidRef,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := VerifySourceCheckout(tt.prov, t.TempDir(), nil); !errCmp(err, tt.expected) {
+				t.Errorf(cmp.Diff(err, tt.expected))
+			}
+		})
+	}
+}
+
 func Test_VerifyWorkflowInputs(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -1377,3 +1522,99 @@ func Test_VerifyUntrustedProvenance(t *testing.T) {
 		})
 	}
 }
+
+func Test_verifiedProvenance(t *testing.T) {
+	t.Parallel()
+
+	builderID := "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml@refs/tags/v1.9.0"
+	tests := []struct {
+		name      string
+		materials []slsacommon.ProvenanceMaterial
+		source    sprovenance.Source
+	}{
+		{
+			name: "source with ref and commit",
+			materials: []slsacommon.ProvenanceMaterial{{
+				URI:    "git+https://github.com/org/repo@refs/tags/v1.0.0",
+				Digest: slsacommon.DigestSet{"sha1": "abcdef"},
+			}},
+			source: sprovenance.Source{
+				URI:    "git+https://github.com/org/repo",
+				Ref:    "refs/tags/v1.0.0",
+				Commit: "abcdef",
+			},
+		},
+		{
+			name: "no commit",
+			materials: []slsacommon.ProvenanceMaterial{{
+				URI: "git+https://github.com/org/repo@refs/tags/v1.0.0",
+			}},
+			source: sprovenance.Source{
+				URI: "git+https://github.com/org/repo",
+				Ref: "refs/tags/v1.0.0",
+			},
+		},
+		{
+			name: "no ref",
+			materials: []slsacommon.ProvenanceMaterial{{
+				URI:    "git+https://github.com/org/repo",
+				Digest: slsacommon.DigestSet{"sha1": "abcdef"},
+			}},
+			source: sprovenance.Source{
+				URI:    "git+https://github.com/org/repo",
+				Commit: "abcdef",
+			},
+		},
+		{
+			name: "not a git URI",
+			materials: []slsacommon.ProvenanceMaterial{{
+				URI:    "https://github.com/org/repo@refs/tags/v1.0.0",
+				Digest: slsacommon.DigestSet{"sha1": "abcdef"},
+			}},
+			source: sprovenance.Source{
+				Commit: "abcdef",
+			},
+		},
+		{
+			name: "no source",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			payload, err := json.Marshal(&intoto.ProvenanceStatementSLSA02{
+				StatementHeader: intoto.StatementHeader{
+					Type:          intoto.StatementInTotoV01,
+					PredicateType: slsa02.PredicateSLSAProvenance,
+					Subject:       []intoto.Subject{{Name: "artifact", Digest: slsacommon.DigestSet{"sha256": "abc"}}},
+				},
+				Predicate: slsa02.ProvenancePredicate{
+					Builder:   slsacommon.ProvenanceBuilder{ID: builderID},
+					BuildType: "https://github.com/slsa-framework/slsa-github-generator/generic@v1",
+					Materials: tt.materials,
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			env := &dsselib.Envelope{
+				PayloadType: intoto.PayloadType,
+				Payload:     base64.StdEncoding.EncodeToString(payload),
+			}
+			trustedBuilderID, err := utils.TrustedBuilderIDNew(builderID, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			prov, err := verifiedProvenance(env, trustedBuilderID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.source, prov.Source); diff != "" {
+				t.Errorf("unexpected source (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
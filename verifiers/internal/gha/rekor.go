@@ -7,15 +7,17 @@ import (
 	"crypto/ecdsa"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
 	cjson "github.com/docker/go/canonical/json"
 	"github.com/go-openapi/runtime"
+	httptransport "github.com/go-openapi/runtime/client"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci"
 	"github.com/sigstore/rekor/pkg/generated/client"
 	"github.com/sigstore/rekor/pkg/generated/client/entries"
 	"github.com/sigstore/rekor/pkg/generated/client/index"
@@ -31,16 +33,28 @@ import (
 	"github.com/sigstore/sigstore/pkg/signature"
 	dsseverifier "github.com/sigstore/sigstore/pkg/signature/dsse"
 	"github.com/slsa-framework/slsa-github-generator/signing/envelope"
+	"github.com/transparency-dev/merkle/rfc6962"
 
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/logging"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 )
 
 const (
 	defaultRekorAddr = "https://rekor.sigstore.dev"
 )
 
+// rekorEntryURL returns the URL of an entry in the log served by rClient.
+func rekorEntryURL(rClient *client.Rekor, uuid string) string {
+	addr := defaultRekorAddr
+	if rt, ok := rClient.Transport.(*httptransport.Runtime); ok && rt.Host != "" {
+		addr = "https://" + rt.Host
+	}
+	return fmt.Sprintf("%v/%v/%v", addr, "api/v1/log/entries", uuid)
+}
+
 func verifyTlogEntryByUUID(ctx context.Context, rekorClient *client.Rekor,
-	entryUUID string, trustedRoot *TrustedRoot) (
+	entryUUID string, trustedRoot *TrustedRoot, checkpoints *CheckpointConfig) (
 	*models.LogEntryAnon, error,
 ) {
 	params := entries.NewGetLogEntryByUUIDParamsWithContext(ctx)
@@ -70,7 +84,7 @@ func verifyTlogEntryByUUID(ctx context.Context, rekorClient *client.Rekor,
 			return nil, errors.New("expected matching UUID")
 		}
 		// Validate the entry response.
-		return verifyTlogEntry(ctx, entry, true, trustedRoot.RekorPubKeys)
+		return verifyTlogEntry(ctx, rekorClient, entry, true, trustedRoot.RekorPubKeys, checkpoints)
 	}
 
 	return nil, serrors.ErrorRekorSearch
@@ -78,34 +92,86 @@ func verifyTlogEntryByUUID(ctx context.Context, rekorClient *client.Rekor,
 
 // verifyTlogEntry verifies a Rekor entry content against a trusted Rekor key.
 // Verification includes verifying the SignedEntryTimestamp and, if verifyInclusion
-// is true, the inclusion proof along with the signed tree head.
-func verifyTlogEntry(ctx context.Context, e models.LogEntryAnon,
-	verifyInclusion bool, rekorKeys *cosign.TrustedTransparencyLogPubKeys) (
+// is true, the inclusion proof along with the signed tree head. If checkpoints
+// is set, the signed tree head is also verified against the checkpoint
+// configuration, with rClient for consistency proofs.
+func verifyTlogEntry(ctx context.Context, rClient *client.Rekor, e models.LogEntryAnon,
+	verifyInclusion bool, rekorKeys *cosign.TrustedTransparencyLogPubKeys,
+	checkpoints *CheckpointConfig) (
 	*models.LogEntryAnon, error,
 ) {
 	// Verify the root hash against the current Signed Entry Tree Head
-	verifier, err := signature.LoadECDSAVerifier(rekorKeys.Keys[*e.LogID].PubKey.(*ecdsa.PublicKey),
-		crypto.SHA256)
+	pubKey := rekorKeys.Keys[*e.LogID].PubKey.(*ecdsa.PublicKey)
+	verifier, err := signature.LoadECDSAVerifier(pubKey, crypto.SHA256)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", serrors.ErrorRekorPubKey, err)
 	}
 
-	if verifyInclusion {
-		// This function verifies the inclusion proof, the signature on the root hash of the
-		// inclusion proof, and the SignedEntryTimestamp.
-		err = rverify.VerifyLogEntry(ctx, &e, verifier)
-	} else {
+	if !verifyInclusion {
 		// This function verifies the SignedEntryTimestamp
-		err = rverify.VerifySignedEntryTimestamp(ctx, &e, verifier)
+		if err := rverify.VerifySignedEntryTimestamp(ctx, &e, verifier); err != nil {
+			return nil, fmt.Errorf("%w: %s", serrors.ErrorInvalidRekorEntry, err)
+		}
+		return &e, nil
 	}
 
-	if err != nil {
+	// Witness co-signatures on the checkpoint are verified separately.
+	logEntry := withLogSignatures(e, pubKey)
+	// This function verifies the inclusion proof, the signature on the root hash of the
+	// inclusion proof, and the SignedEntryTimestamp.
+	if err := rverify.VerifyLogEntry(ctx, &logEntry, verifier); err != nil {
 		return nil, fmt.Errorf("%w: %s", serrors.ErrorInvalidRekorEntry, err)
 	}
 
+	if checkpoints != nil {
+		if err := checkpoints.verifyCheckpoint(ctx, rClient, &e); err != nil {
+			return nil, err
+		}
+	}
 	return &e, nil
 }
 
+// rekorEntryFromAttestation returns the transparency log entry in the
+// bundle cosign attaches to an image attestation, if any.
+func rekorEntryFromAttestation(att oci.Signature) *utils.RekorEntry {
+	b, err := att.Bundle()
+	if err != nil || b == nil {
+		return nil
+	}
+	return utils.RekorEntryNew(&models.LogEntryAnon{
+		Body:           b.Payload.Body,
+		IntegratedTime: &b.Payload.IntegratedTime,
+		LogIndex:       &b.Payload.LogIndex,
+		LogID:          &b.Payload.LogID,
+	})
+}
+
+// verifyAttestationCheckpoint verifies the checkpoint of the transparency
+// log entry of an image attestation against the checkpoint configuration.
+// Cosign only attaches the inclusion promise of the entry, so the entry is
+// fetched from the log with its inclusion proof.
+func verifyAttestationCheckpoint(ctx context.Context, rClient *client.Rekor,
+	att oci.Signature, trustedRoot *TrustedRoot, checkpoints *CheckpointConfig,
+) error {
+	b, err := att.Bundle()
+	if err != nil || b == nil {
+		return fmt.Errorf("%w: no transparency log entry for the attestation", serrors.ErrorInvalidCheckpoint)
+	}
+	encoded, ok := b.Payload.Body.(string)
+	if !ok {
+		return fmt.Errorf("%w: unexpected body of the transparency log entry", serrors.ErrorInvalidRekorEntry)
+	}
+	body, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("%w: %v", serrors.ErrorInvalidRekorEntry, err)
+	}
+
+	// The UUID of an entry is its leaf hash.
+	uuid := hex.EncodeToString(rfc6962.DefaultHasher.HashLeaf(body))
+	_, err = verifyTlogEntryByUUID(ctx, rClient, uuid, trustedRoot, checkpoints)
+	return err
+}
+
 func extractCert(e *models.LogEntryAnon) (*x509.Certificate, error) {
 	b, err := base64.StdEncoding.DecodeString(e.Body.(string))
 	if err != nil {
@@ -155,20 +221,20 @@ func extractCert(e *models.LogEntryAnon) (*x509.Certificate, error) {
 	return certs[0], err
 }
 
-func intotoEntry(certPem, provenance []byte) (models.ProposedEntry, error) {
+func intotoEntry(ctx context.Context, certPem, provenance []byte) (models.ProposedEntry, error) {
 	if len(certPem) == 0 {
 		return nil, fmt.Errorf("no signing certificate found in intoto envelope")
 	}
 	var pubKeyBytes [][]byte
 	pubKeyBytes = append(pubKeyBytes, certPem)
 
-	return types.NewProposedEntry(context.Background(), intoto.KIND, intoto_v001.APIVERSION, types.ArtifactProperties{
+	return types.NewProposedEntry(ctx, intoto.KIND, intoto_v001.APIVERSION, types.ArtifactProperties{
 		ArtifactBytes:  provenance,
 		PublicKeyBytes: pubKeyBytes,
 	})
 }
 
-func dsseEntry(certPem, provenance []byte) (models.ProposedEntry, error) {
+func dsseEntry(ctx context.Context, certPem, provenance []byte) (models.ProposedEntry, error) {
 	if len(certPem) == 0 {
 		return nil, fmt.Errorf("no signing certificate found in intoto envelope")
 	}
@@ -176,16 +242,16 @@ func dsseEntry(certPem, provenance []byte) (models.ProposedEntry, error) {
 	var pubKeyBytes [][]byte
 	pubKeyBytes = append(pubKeyBytes, certPem)
 
-	return types.NewProposedEntry(context.Background(), dsse.KIND, dsse_v001.APIVERSION, types.ArtifactProperties{
+	return types.NewProposedEntry(ctx, dsse.KIND, dsse_v001.APIVERSION, types.ArtifactProperties{
 		ArtifactBytes:  provenance,
 		PublicKeyBytes: pubKeyBytes,
 	})
 }
 
 // getUUIDsByArtifactDigest finds all entry UUIDs by the digest of the artifact binary.
-func getUUIDsByArtifactDigest(rClient *client.Rekor, artifactHash string) ([]string, error) {
+func getUUIDsByArtifactDigest(ctx context.Context, rClient *client.Rekor, artifactHash string) ([]string, error) {
 	// Use search index to find rekor entry UUIDs that match Subject Digest.
-	params := index.NewSearchIndexParams()
+	params := index.NewSearchIndexParamsWithContext(ctx)
 	params.Query = &models.SearchIndex{Hash: fmt.Sprintf("sha256:%v", artifactHash)}
 	resp, err := rClient.Index.SearchIndex(params)
 	if err != nil {
@@ -202,22 +268,22 @@ func getUUIDsByArtifactDigest(rClient *client.Rekor, artifactHash string) ([]str
 // GetValidSignedAttestationWithCert finds and validates the matching entry UUIDs with
 // the full intoto attestation.
 // The attestation generated by the slsa-github-generator libraries contain a signing certificate.
-func GetValidSignedAttestationWithCert(rClient *client.Rekor,
-	provenance []byte, trustedRoot *TrustedRoot,
+func GetValidSignedAttestationWithCert(ctx context.Context, rClient *client.Rekor,
+	provenance []byte, trustedRoot *TrustedRoot, checkpoints *CheckpointConfig,
 ) (*SignedAttestation, error) {
 	// Use intoto attestation to find rekor entry UUIDs.
-	params := entries.NewSearchLogQueryParams()
+	params := entries.NewSearchLogQueryParamsWithContext(ctx)
 	searchLogQuery := models.SearchLogQuery{}
 	certPem, err := envelope.GetCertFromEnvelope(provenance)
 	if err != nil {
 		return nil, fmt.Errorf("error getting certificate from provenance: %w", err)
 	}
 
-	intotoEntry, err := intotoEntry(certPem, provenance)
+	intotoEntry, err := intotoEntry(ctx, certPem, provenance)
 	if err != nil {
 		return nil, fmt.Errorf("error creating intoto entry: %w", err)
 	}
-	dsseEntry, err := dsseEntry(certPem, provenance)
+	dsseEntry, err := dsseEntry(ctx, certPem, provenance)
 	if err != nil {
 		return nil, err
 	}
@@ -236,13 +302,14 @@ func GetValidSignedAttestationWithCert(rClient *client.Rekor,
 	logEntry := resp.Payload[0]
 	var rekorEntry models.LogEntryAnon
 	for uuid, e := range logEntry {
-		if _, err := verifyTlogEntry(context.Background(), e, true,
-			trustedRoot.RekorPubKeys); err != nil {
+		if _, err := verifyTlogEntry(ctx, rClient, e, true,
+			trustedRoot.RekorPubKeys, checkpoints); err != nil {
 			return nil, fmt.Errorf("error verifying tlog entry: %w", err)
 		}
 		rekorEntry = e
-		url := fmt.Sprintf("%v/%v/%v", defaultRekorAddr, "api/v1/log/entries", uuid)
-		fmt.Fprintf(os.Stderr, "Verified signature against tlog entry index %d at URL: %s\n", *e.LogIndex, url)
+		url := rekorEntryURL(rClient, uuid)
+		logging.FromContext(ctx).Info(fmt.Sprintf("Verified signature against tlog entry index %d at URL: %s", *e.LogIndex, url),
+			"logIndex", *e.LogIndex, "url", url)
 	}
 
 	certs, err := cryptoutils.UnmarshalCertificatesFromPEM(certPem)
@@ -274,10 +341,10 @@ func GetValidSignedAttestationWithCert(rClient *client.Rekor,
 // SearchValidSignedAttestation searches for a valid signing certificate using the Rekor
 // Redis search index by using the artifact digest.
 func SearchValidSignedAttestation(ctx context.Context, artifactHash string, provenance []byte,
-	rClient *client.Rekor, trustedRoot *TrustedRoot,
+	rClient *client.Rekor, trustedRoot *TrustedRoot, checkpoints *CheckpointConfig,
 ) (*SignedAttestation, error) {
 	// Get Rekor UUIDs by artifact digest.
-	uuids, err := getUUIDsByArtifactDigest(rClient, artifactHash)
+	uuids, err := getUUIDsByArtifactDigest(ctx, rClient, artifactHash)
 	if err != nil {
 		return nil, err
 	}
@@ -295,7 +362,7 @@ func SearchValidSignedAttestation(ctx context.Context, artifactHash string, prov
 	//   * If all succeed, return the signing certificate.
 	var errs []string
 	for _, uuid := range uuids {
-		entry, err := verifyTlogEntryByUUID(ctx, rClient, uuid, trustedRoot)
+		entry, err := verifyTlogEntryByUUID(ctx, rClient, uuid, trustedRoot, checkpoints)
 		if err != nil {
 			// this is unexpected, hold on to this error.
 			errs = append(errs, fmt.Sprintf("%s: verifying tlog entry %s", err, uuid))
@@ -325,8 +392,9 @@ func SearchValidSignedAttestation(ctx context.Context, artifactHash string, prov
 		}
 
 		// success!
-		url := fmt.Sprintf("%v/%v/%v", defaultRekorAddr, "api/v1/log/entries", uuid)
-		fmt.Fprintf(os.Stderr, "Verified signature against tlog entry index %d at URL: %s\n", *entry.LogIndex, url)
+		url := rekorEntryURL(rClient, uuid)
+		logging.FromContext(ctx).Info(fmt.Sprintf("Verified signature against tlog entry index %d at URL: %s", *entry.LogIndex, url),
+			"logIndex", *entry.LogIndex, "url", url)
 		return proposedSignedAtt, nil
 	}
 
@@ -340,24 +408,33 @@ func SearchValidSignedAttestation(ctx context.Context, artifactHash string, prov
 // using the certificate, and the signature generation time is checked
 // to be within the certificate validity period.
 func verifySignedAttestation(signedAtt *SignedAttestation, trustedRoot *TrustedRoot) error {
+	return verifySignedAttestationWithIdentity(signedAtt, trustedRoot, cosign.Identity{
+		Issuer:        certOidcIssuer,
+		SubjectRegExp: certSubjectRegexp,
+	})
+}
+
+// verifySignedAttestationWithIdentity is like verifySignedAttestation but
+// requires the certificate to match the given identity.
+func verifySignedAttestationWithIdentity(signedAtt *SignedAttestation, trustedRoot *TrustedRoot,
+	identity cosign.Identity,
+) error {
 	cert := signedAtt.SigningCert
 	attBytes, err := cjson.MarshalCanonical(signedAtt.Envelope)
 	if err != nil {
 		return err
 	}
-	signatureTimestamp := time.Unix(*signedAtt.RekorEntry.IntegratedTime, 0)
+	signatureTimes := signingTimes(signedAtt)
+	if len(signatureTimes) == 0 {
+		return fmt.Errorf("%w: no signing time", serrors.ErrorMissingTimestamp)
+	}
 
 	// 1. Verify certificate chain.
 	co := &cosign.CheckOpts{
 		RootCerts:         trustedRoot.FulcioRoot,
 		IntermediateCerts: trustedRoot.FulcioIntermediates,
-		Identities: []cosign.Identity{
-			{
-				Issuer:        certOidcIssuer,
-				SubjectRegExp: certSubjectRegexp,
-			},
-		},
-		CTLogPubKeys: trustedRoot.CTPubKeys,
+		Identities:        []cosign.Identity{identity},
+		CTLogPubKeys:      trustedRoot.CTPubKeys,
 	}
 	verifier, err := cosign.ValidateAndUnpackCert(signedAtt.SigningCert, co)
 	if err != nil {
@@ -370,9 +447,22 @@ func verifySignedAttestation(signedAtt *SignedAttestation, trustedRoot *TrustedR
 		return fmt.Errorf("%w: %s", serrors.ErrorInvalidSignature, err)
 	}
 
-	// 3. Verify signature was creating during certificate validity period.
-	if err := cosign.CheckExpiry(cert, signatureTimestamp); err != nil {
-		return fmt.Errorf("%w: %s", serrors.ErrorInvalidSignature, err)
+	// 3. Verify signature was creating during certificate validity period,
+	// at the time of the tlog entry and of each RFC 3161 timestamp.
+	for _, signatureTimestamp := range signatureTimes {
+		if err := cosign.CheckExpiry(cert, signatureTimestamp); err != nil {
+			return fmt.Errorf("%w: %s", serrors.ErrorInvalidSignature, err)
+		}
 	}
 	return nil
 }
+
+// signingTimes returns the verified times of the signature: the integrated
+// time of its tlog entry and the times of its RFC 3161 timestamps.
+func signingTimes(signedAtt *SignedAttestation) []time.Time {
+	var times []time.Time
+	if signedAtt.RekorEntry != nil && signedAtt.RekorEntry.IntegratedTime != nil {
+		times = append(times, time.Unix(*signedAtt.RekorEntry.IntegratedTime, 0))
+	}
+	return append(times, signedAtt.Timestamps...)
+}
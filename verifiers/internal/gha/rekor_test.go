@@ -1,6 +1,7 @@
 package gha
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -81,10 +82,52 @@ func Test_GetRekorEntries(t *testing.T) {
 			var mClient client.Rekor
 			mClient.Index = &MockIndexClient{result: tt.res}
 
-			_, err := getUUIDsByArtifactDigest(&mClient, tt.artifactHash)
+			_, err := getUUIDsByArtifactDigest(context.Background(), &mClient, tt.artifactHash)
 			if !errCmp(err, tt.expected) {
 				t.Errorf(cmp.Diff(err, tt.expected))
 			}
 		})
 	}
 }
+
+func Test_rekorEntryURL(t *testing.T) {
+	t.Parallel()
+
+	uuid := "39d5109436c43dad92897d50f3b271aa456382875a922b28fedef9038b8f683a"
+	tests := []struct {
+		name     string
+		addr     string
+		expected string
+	}{
+		{
+			name:     "public-good instance",
+			addr:     defaultRekorAddr,
+			expected: "https://rekor.sigstore.dev/api/v1/log/entries/" + uuid,
+		},
+		{
+			name:     "private instance",
+			addr:     "https://rekor.example.com",
+			expected: "https://rekor.example.com/api/v1/log/entries/" + uuid,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rClient, err := rekorClientNew(tt.addr, &Config{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := rekorEntryURL(rClient, uuid); got != tt.expected {
+				t.Errorf("unexpected URL %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+
+	// Clients without a runtime use the public-good instance.
+	var mClient client.Rekor
+	if got := rekorEntryURL(&mClient, uuid); got != defaultRekorAddr+"/api/v1/log/entries/"+uuid {
+		t.Errorf("unexpected URL %q", got)
+	}
+}
@@ -232,3 +232,14 @@ func GetAsString(environment map[string]any, field string) (string, error) {
 	}
 	return i, nil
 }
+
+// GetCommitDigest returns the git commit from a material's digest set.
+// SLSA v1.0 builders record it as gitCommit while older ones use sha1.
+func GetCommitDigest(digest map[string]string) (string, error) {
+	for _, alg := range []string{"gitCommit", "sha1"} {
+		if commit, ok := digest[alg]; ok && commit != "" {
+			return commit, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", serrors.ErrorInvalidDssePayload, "no commit digest in source material")
+}
@@ -17,6 +17,9 @@ type Provenance interface {
 	// SourceURI is the full URI (including tag) of the source material.
 	SourceURI() (string, error)
 
+	// SourceCommit is the git commit digest of the source material.
+	SourceCommit() (string, error)
+
 	// TriggerURI is the full URI (including tag) of the configuration / trigger.
 	TriggerURI() (string, error)
 
@@ -54,6 +54,14 @@ func (p *provenanceV02) SourceURI() (string, error) {
 	return uri, nil
 }
 
+// SourceCommit implements Provenance.SourceCommit.
+func (p *provenanceV02) SourceCommit() (string, error) {
+	if len(p.prov.Predicate.Materials) == 0 {
+		return "", fmt.Errorf("%w: %s", serrors.ErrorInvalidDssePayload, "no material")
+	}
+	return common.GetCommitDigest(p.prov.Predicate.Materials[0].Digest)
+}
+
 // TriggerURI implements Provenance.TriggerURI.
 func (p *provenanceV02) TriggerURI() (string, error) {
 	uri := p.prov.Predicate.Invocation.ConfigSource.URI
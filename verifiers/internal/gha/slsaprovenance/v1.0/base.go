@@ -46,6 +46,14 @@ func (p *provenanceV1) SourceURI() (string, error) {
 	return uri, nil
 }
 
+// SourceCommit implements Provenance.SourceCommit.
+func (p *provenanceV1) SourceCommit() (string, error) {
+	if len(p.prov.Predicate.BuildDefinition.ResolvedDependencies) == 0 {
+		return "", fmt.Errorf("%w: empty resovedDependencies", serrors.ErrorInvalidDssePayload)
+	}
+	return common.GetCommitDigest(p.prov.Predicate.BuildDefinition.ResolvedDependencies[0].Digest)
+}
+
 func (p *provenanceV1) builderTriggerInfo() (string, string, string, error) {
 	sysParams, ok := p.prov.Predicate.BuildDefinition.InternalParameters.(map[string]interface{})
 	if !ok {
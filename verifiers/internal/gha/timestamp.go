@@ -0,0 +1,201 @@
+package gha
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	common "github.com/sigstore/protobuf-specs/gen/pb-go/common/v1"
+	trustroot "github.com/sigstore/protobuf-specs/gen/pb-go/trustroot/v1"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	tsaverification "github.com/sigstore/timestamp-authority/pkg/verification"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// TimestampPolicy is the source of signing time that a Sigstore bundle
+// must provide: a transparency log entry, an RFC 3161 timestamp, or both.
+// Whichever are present are verified, and the signing certificate must be
+// valid at each of their times.
+type TimestampPolicy int
+
+const (
+	// TimestampPolicyTlog requires a transparency log entry. It is the
+	// default.
+	TimestampPolicyTlog TimestampPolicy = iota
+
+	// TimestampPolicyTSA requires an RFC 3161 timestamp.
+	TimestampPolicyTSA
+
+	// TimestampPolicyBoth requires a transparency log entry and an
+	// RFC 3161 timestamp.
+	TimestampPolicyBoth
+)
+
+// timestampPolicyNames are the names of the policies, e.g. for flags.
+var timestampPolicyNames = map[string]TimestampPolicy{
+	"tlog": TimestampPolicyTlog,
+	"tsa":  TimestampPolicyTSA,
+	"both": TimestampPolicyBoth,
+}
+
+// TimestampPolicyFromString returns the policy of the given name: tlog,
+// tsa or both.
+func TimestampPolicyFromString(name string) (TimestampPolicy, error) {
+	policy, ok := timestampPolicyNames[name]
+	if !ok {
+		return 0, fmt.Errorf("%w: timestamp policy %q, expected tlog, tsa or both",
+			serrors.ErrorNotSupported, name)
+	}
+	return policy, nil
+}
+
+func (p TimestampPolicy) requiresTlog() bool {
+	return p == TimestampPolicyTlog || p == TimestampPolicyBoth
+}
+
+func (p TimestampPolicy) requiresTSA() bool {
+	return p == TimestampPolicyTSA || p == TimestampPolicyBoth
+}
+
+// TimestampAuthority is the certificate chain of an RFC 3161 timestamp
+// authority (TSA).
+type TimestampAuthority struct {
+	// Leaf is the certificate signing the timestamps. It is optional if
+	// the timestamps embed it.
+	Leaf *x509.Certificate
+
+	// Intermediates are the intermediate certificates of the chain.
+	Intermediates []*x509.Certificate
+
+	// Root is the root certificate of the chain.
+	Root *x509.Certificate
+
+	// ValidFor is the period during which timestamps of the authority are
+	// trusted, if bounded. Zero times are unbounded.
+	ValidFor TimeRange
+}
+
+// TimeRange is a period of time. Zero times are unbounded.
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// contains returns true if t is in the range, inclusive of its ends.
+func (r TimeRange) contains(t time.Time) bool {
+	return (r.Start.IsZero() || !t.Before(r.Start)) && (r.End.IsZero() || !t.After(r.End))
+}
+
+// TimestampAuthorityFromPEM returns the timestamp authority of a PEM
+// certificate chain, ordered from the signing certificate, if any, to the
+// root certificate.
+func TimestampAuthorityFromPEM(content []byte) (*TimestampAuthority, error) {
+	certs, err := cryptoutils.UnmarshalCertificatesFromPEM(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", serrors.ErrorInvalidPEM, err)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("%w: no certificate", serrors.ErrorInvalidPEM)
+	}
+
+	tsa := &TimestampAuthority{Root: certs[len(certs)-1]}
+	if !bytes.Equal(tsa.Root.RawSubject, tsa.Root.RawIssuer) {
+		return nil, fmt.Errorf("%w: the last certificate of the chain is not a root certificate",
+			serrors.ErrorInvalidCertificate)
+	}
+	certs = certs[:len(certs)-1]
+	if len(certs) > 0 && !certs[0].IsCA {
+		tsa.Leaf, certs = certs[0], certs[1:]
+	}
+	tsa.Intermediates = certs
+	return tsa, nil
+}
+
+// timestampAuthoritiesFromTrustedRoot returns the timestamp authorities of
+// a Sigstore trusted root, as in the trusted_root.json target of the Sigstore
+// TUF repository. The last certificate of each chain is its trust anchor.
+func timestampAuthoritiesFromTrustedRoot(content []byte) ([]*TimestampAuthority, error) {
+	var trustedRoot trustroot.TrustedRoot
+	if err := protojson.Unmarshal(content, &trustedRoot); err != nil {
+		return nil, fmt.Errorf("%w: trusted root: %v", serrors.ErrorInvalidFormat, err)
+	}
+
+	var authorities []*TimestampAuthority
+	for i, ca := range trustedRoot.GetTimestampAuthorities() {
+		var certs []*x509.Certificate
+		for _, c := range ca.GetCertChain().GetCertificates() {
+			cert, err := x509.ParseCertificate(c.GetRawBytes())
+			if err != nil {
+				return nil, fmt.Errorf("%w: timestamp authority %d: %v", serrors.ErrorInvalidCertificate, i, err)
+			}
+			certs = append(certs, cert)
+		}
+		if len(certs) == 0 {
+			return nil, fmt.Errorf("%w: timestamp authority %d has no certificate", serrors.ErrorInvalidCertificate, i)
+		}
+
+		tsa := &TimestampAuthority{Root: certs[len(certs)-1]}
+		certs = certs[:len(certs)-1]
+		if len(certs) > 0 && !certs[0].IsCA {
+			tsa.Leaf, certs = certs[0], certs[1:]
+		}
+		tsa.Intermediates = certs
+		if start := ca.GetValidFor().GetStart(); start != nil {
+			tsa.ValidFor.Start = start.AsTime()
+		}
+		if end := ca.GetValidFor().GetEnd(); end != nil {
+			tsa.ValidFor.End = end.AsTime()
+		}
+		authorities = append(authorities, tsa)
+	}
+	return authorities, nil
+}
+
+// verifyRFC3161Timestamps verifies the RFC 3161 timestamps over the
+// signature against the timestamp authorities, and returns their times.
+// Each timestamp must be signed by one of the authorities.
+func verifyRFC3161Timestamps(timestamps []*common.RFC3161SignedTimestamp, sig []byte,
+	authorities []*TimestampAuthority,
+) ([]time.Time, error) {
+	if len(timestamps) == 0 {
+		return nil, fmt.Errorf("%w: no RFC 3161 timestamp", serrors.ErrorMissingTimestamp)
+	}
+	if len(authorities) == 0 {
+		return nil, fmt.Errorf("%w: no timestamp authority in the trusted root", serrors.ErrorMissingTimestamp)
+	}
+
+	var times []time.Time
+	for i, ts := range timestamps {
+		var errs []error
+		verified := false
+		for _, tsa := range authorities {
+			if tsa.Root == nil {
+				continue
+			}
+			t, err := tsaverification.VerifyTimestampResponse(ts.GetSignedTimestamp(),
+				bytes.NewReader(sig), tsaverification.VerifyOpts{
+					TSACertificate: tsa.Leaf,
+					Intermediates:  tsa.Intermediates,
+					Roots:          []*x509.Certificate{tsa.Root},
+				})
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if !tsa.ValidFor.contains(t.Time) {
+				errs = append(errs, fmt.Errorf("timestamp at %v outside the validity of the authority", t.Time))
+				continue
+			}
+			times = append(times, t.Time)
+			verified = true
+			break
+		}
+		if !verified {
+			return nil, fmt.Errorf("%w: timestamp %d: %v", serrors.ErrorInvalidTimestamp, i, errs)
+		}
+	}
+	return times, nil
+}
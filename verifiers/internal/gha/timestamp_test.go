@@ -0,0 +1,405 @@
+package gha
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/digitorus/timestamp"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	common "github.com/sigstore/protobuf-specs/gen/pb-go/common/v1"
+	trustroot "github.com/sigstore/protobuf-specs/gen/pb-go/trustroot/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// testTSA is a local stand-in for an RFC 3161 timestamp authority.
+type testTSA struct {
+	key  *ecdsa.PrivateKey
+	leaf *x509.Certificate
+	root *x509.Certificate
+}
+
+func testTSANew(t *testing.T) *testTSA {
+	t.Helper()
+
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test TSA Root"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, rootKey.Public(), rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := x509.ParseCertificate(rootDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// RFC 3161 requires a critical timestamping EKU, which the standard
+	// library does not mark critical.
+	eku, err := asn1.Marshal([]asn1.ObjectIdentifier{{1, 3, 6, 1, 5, 5, 7, 3, 8}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "Test TSA"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtraExtensions: []pkix.Extension{
+			{Id: asn1.ObjectIdentifier{2, 5, 29, 37}, Critical: true, Value: eku},
+		},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, root, key.Public(), rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(leafDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testTSA{key: key, leaf: leaf, root: root}
+}
+
+func (a *testTSA) authority() *TimestampAuthority {
+	return &TimestampAuthority{Root: a.root}
+}
+
+// testTrustedRootJSON returns the JSON of a Sigstore trusted root with the authorities.
+func testTrustedRootJSON(t *testing.T, start time.Time, authorities ...*testTSA) []byte {
+	t.Helper()
+
+	trustedRoot := &trustroot.TrustedRoot{
+		MediaType: "application/vnd.dev.sigstore.trustedroot+json;version=0.1",
+	}
+	for _, a := range authorities {
+		trustedRoot.TimestampAuthorities = append(trustedRoot.TimestampAuthorities, &trustroot.CertificateAuthority{
+			Subject: &common.DistinguishedName{CommonName: a.root.Subject.CommonName},
+			CertChain: &common.X509CertificateChain{
+				Certificates: []*common.X509Certificate{{RawBytes: a.leaf.Raw}, {RawBytes: a.root.Raw}},
+			},
+			ValidFor: &common.TimeRange{Start: timestamppb.New(start)},
+		})
+	}
+	content, err := protojson.Marshal(trustedRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+// timestamp returns a timestamp of the signature at the given time.
+func (a *testTSA) timestamp(t *testing.T, sig []byte, at time.Time) *common.RFC3161SignedTimestamp {
+	t.Helper()
+
+	digest := sha256.Sum256(sig)
+	ts := timestamp.Timestamp{
+		HashAlgorithm:     crypto.SHA256,
+		HashedMessage:     digest[:],
+		Time:              at,
+		SerialNumber:      big.NewInt(1),
+		Policy:            asn1.ObjectIdentifier{1, 2, 3, 4, 1},
+		AddTSACertificate: true,
+	}
+	resp, err := ts.CreateResponse(a.leaf, a.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &common.RFC3161SignedTimestamp{SignedTimestamp: resp}
+}
+
+func Test_verifyRFC3161Timestamps(t *testing.T) {
+	t.Parallel()
+
+	tsa := testTSANew(t)
+	other := testTSANew(t)
+	sig := []byte("signature")
+	at := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	tests := []struct {
+		name        string
+		timestamps  []*common.RFC3161SignedTimestamp
+		authorities []*TimestampAuthority
+		times       []time.Time
+		expected    error
+	}{
+		{
+			name:        "valid timestamp",
+			timestamps:  []*common.RFC3161SignedTimestamp{tsa.timestamp(t, sig, at)},
+			authorities: []*TimestampAuthority{tsa.authority()},
+			times:       []time.Time{at},
+		},
+		{
+			name:        "timestamp of second authority",
+			timestamps:  []*common.RFC3161SignedTimestamp{tsa.timestamp(t, sig, at)},
+			authorities: []*TimestampAuthority{other.authority(), tsa.authority()},
+			times:       []time.Time{at},
+		},
+		{
+			name: "timestamps of both authorities",
+			timestamps: []*common.RFC3161SignedTimestamp{
+				tsa.timestamp(t, sig, at),
+				other.timestamp(t, sig, at.Add(time.Second)),
+			},
+			authorities: []*TimestampAuthority{other.authority(), tsa.authority()},
+			times:       []time.Time{at, at.Add(time.Second)},
+		},
+		{
+			name:        "no timestamp",
+			authorities: []*TimestampAuthority{tsa.authority()},
+			expected:    serrors.ErrorMissingTimestamp,
+		},
+		{
+			name:       "no authority",
+			timestamps: []*common.RFC3161SignedTimestamp{tsa.timestamp(t, sig, at)},
+			expected:   serrors.ErrorMissingTimestamp,
+		},
+		{
+			name:        "untrusted authority",
+			timestamps:  []*common.RFC3161SignedTimestamp{other.timestamp(t, sig, at)},
+			authorities: []*TimestampAuthority{tsa.authority()},
+			expected:    serrors.ErrorInvalidTimestamp,
+		},
+		{
+			name: "one untrusted timestamp",
+			timestamps: []*common.RFC3161SignedTimestamp{
+				tsa.timestamp(t, sig, at),
+				other.timestamp(t, sig, at),
+			},
+			authorities: []*TimestampAuthority{tsa.authority()},
+			expected:    serrors.ErrorInvalidTimestamp,
+		},
+		{
+			name:        "timestamp of another signature",
+			timestamps:  []*common.RFC3161SignedTimestamp{tsa.timestamp(t, []byte("other"), at)},
+			authorities: []*TimestampAuthority{tsa.authority()},
+			expected:    serrors.ErrorInvalidTimestamp,
+		},
+		{
+			name:       "timestamp outside of the validity of the authority",
+			timestamps: []*common.RFC3161SignedTimestamp{tsa.timestamp(t, sig, at)},
+			authorities: []*TimestampAuthority{
+				{Root: tsa.root, ValidFor: TimeRange{End: at.Add(-time.Second)}},
+			},
+			expected: serrors.ErrorInvalidTimestamp,
+		},
+		{
+			name:       "timestamp within the validity of the authority",
+			timestamps: []*common.RFC3161SignedTimestamp{tsa.timestamp(t, sig, at)},
+			authorities: []*TimestampAuthority{
+				{Root: tsa.root, ValidFor: TimeRange{Start: at, End: at.Add(time.Second)}},
+			},
+			times: []time.Time{at},
+		},
+		{
+			name:        "malformed timestamp",
+			timestamps:  []*common.RFC3161SignedTimestamp{{SignedTimestamp: []byte("timestamp")}},
+			authorities: []*TimestampAuthority{tsa.authority()},
+			expected:    serrors.ErrorInvalidTimestamp,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			times, err := verifyRFC3161Timestamps(tt.timestamps, sig, tt.authorities)
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Fatalf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+			if !cmp.Equal(times, tt.times, cmpopts.EquateApproxTime(0)) {
+				t.Errorf(cmp.Diff(times, tt.times, cmpopts.EquateApproxTime(0)))
+			}
+		})
+	}
+}
+
+func Test_TimestampPolicyFromString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		policy   TimestampPolicy
+		expected error
+	}{
+		{name: "tlog", policy: TimestampPolicyTlog},
+		{name: "tsa", policy: TimestampPolicyTSA},
+		{name: "both", policy: TimestampPolicyBoth},
+		{name: "", expected: serrors.ErrorNotSupported},
+		{name: "rekor", expected: serrors.ErrorNotSupported},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := TimestampPolicyFromString(tt.name)
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Fatalf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+			if policy != tt.policy {
+				t.Errorf("policy %d, expected %d", policy, tt.policy)
+			}
+		})
+	}
+}
+
+func Test_TimestampAuthorityFromPEM(t *testing.T) {
+	t.Parallel()
+
+	tsa := testTSANew(t)
+	leafPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tsa.leaf.Raw})
+	rootPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tsa.root.Raw})
+
+	tests := []struct {
+		name     string
+		content  []byte
+		leaf     *x509.Certificate
+		expected error
+	}{
+		{
+			name:    "chain",
+			content: append(append([]byte{}, leafPEM...), rootPEM...),
+			leaf:    tsa.leaf,
+		},
+		{
+			name:    "root only",
+			content: rootPEM,
+		},
+		{
+			name:     "no root",
+			content:  leafPEM,
+			expected: serrors.ErrorInvalidCertificate,
+		},
+		{
+			name:     "no certificate",
+			content:  []byte("certificate"),
+			expected: serrors.ErrorInvalidPEM,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			authority, err := TimestampAuthorityFromPEM(tt.content)
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Fatalf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+			if err != nil {
+				return
+			}
+			if !authority.Root.Equal(tsa.root) {
+				t.Errorf("unexpected root %v", authority.Root.Subject)
+			}
+			if (authority.Leaf == nil) != (tt.leaf == nil) || (tt.leaf != nil && !authority.Leaf.Equal(tt.leaf)) {
+				t.Errorf("unexpected leaf %v", authority.Leaf)
+			}
+		})
+	}
+}
+
+func Test_timestampAuthoritiesFromTrustedRoot(t *testing.T) {
+	t.Parallel()
+
+	tsa := testTSANew(t)
+	other := testTSANew(t)
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	publicGood, err := os.ReadFile("./testdata/trusted_root.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		content  []byte
+		roots    []*x509.Certificate
+		leaves   []*x509.Certificate
+		expected error
+	}{
+		{
+			name:    "no authority",
+			content: testTrustedRootJSON(t, start),
+		},
+		{
+			name:    "authorities",
+			content: testTrustedRootJSON(t, start, tsa, other),
+			roots:   []*x509.Certificate{tsa.root, other.root},
+			leaves:  []*x509.Certificate{tsa.leaf, other.leaf},
+		},
+		{
+			name:     "invalid trusted root",
+			content:  []byte("trusted root"),
+			expected: serrors.ErrorInvalidFormat,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			authorities, err := timestampAuthoritiesFromTrustedRoot(tt.content)
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Fatalf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+			if len(authorities) != len(tt.roots) {
+				t.Fatalf("%d authorities, expected %d", len(authorities), len(tt.roots))
+			}
+			for i, a := range authorities {
+				if !a.Root.Equal(tt.roots[i]) || !a.Leaf.Equal(tt.leaves[i]) {
+					t.Errorf("unexpected authority %d: %v", i, a.Root.Subject)
+				}
+				if !a.ValidFor.Start.Equal(start) || !a.ValidFor.End.IsZero() {
+					t.Errorf("unexpected validity of authority %d: %v", i, a.ValidFor)
+				}
+			}
+		})
+	}
+
+	t.Run("public good instance", func(t *testing.T) {
+		t.Parallel()
+
+		authorities, err := timestampAuthoritiesFromTrustedRoot(publicGood)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(authorities) == 0 {
+			t.Fatal("no timestamp authority")
+		}
+		for i, a := range authorities {
+			if a.Leaf == nil || a.ValidFor.Start.IsZero() {
+				t.Errorf("unexpected authority %d: %v", i, a.Root.Subject)
+			}
+		}
+	})
+}
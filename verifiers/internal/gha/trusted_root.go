@@ -1,14 +1,22 @@
 package gha
 
 import (
+	"bytes"
 	"context"
 	"crypto/x509"
+	"errors"
 	"fmt"
-	"sync/atomic"
+	"sync"
+	"time"
 
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/fulcio"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/env"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/tuf"
+
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/logging"
 )
 
 // TrustedRoot struct that holds the verification material necessary
@@ -25,6 +33,10 @@ type TrustedRoot struct {
 
 	// Certificate pool for Fulcio intermediates
 	FulcioIntermediates *x509.CertPool
+
+	// TimestampAuthorities are the certificate chains of the RFC 3161
+	// timestamp authorities.
+	TimestampAuthorities []*TimestampAuthority
 }
 
 func getTrustedRoot(ctx context.Context) (*TrustedRoot, error) {
@@ -39,38 +51,294 @@ func getTrustedRoot(ctx context.Context) (*TrustedRoot, error) {
 		return nil, fmt.Errorf("%w: %s", serrors.ErrorInternal, err)
 	}
 
-	roots, err := fulcio.GetRoots()
+	roots, intermediates, err := getFulcioRoots(ctx)
 	if err != nil {
 		// this is unexpected, hold on to this error.
 		return nil, fmt.Errorf("%w: %s", serrors.ErrorInternal, err)
 	}
-	intermediates, err := fulcio.GetIntermediates()
+
+	timestampAuthorities, err := getTimestampAuthorities(ctx)
 	if err != nil {
-		// this is unexpected, hold on to this error.
 		return nil, fmt.Errorf("%w: %s", serrors.ErrorInternal, err)
 	}
 
 	return &TrustedRoot{
-		FulcioRoot:          roots,
-		FulcioIntermediates: intermediates,
-		RekorPubKeys:        rekorPubKeys,
-		CTPubKeys:           ctPubKeys,
+		FulcioRoot:           roots,
+		FulcioIntermediates:  intermediates,
+		RekorPubKeys:         rekorPubKeys,
+		CTPubKeys:            ctPubKeys,
+		TimestampAuthorities: timestampAuthorities,
 	}, nil
 }
 
-// Cache the TUF roots to reduce traffic and read contention on the cached file.
-var manager atomic.Value
+// trustedRootTarget is the Sigstore trusted root in the TUF repository.
+const trustedRootTarget = "trusted_root.json"
 
-func TrustedRootSingleton(ctx context.Context) (*TrustedRoot, error) {
-	root := manager.Load()
-	if root != nil {
-		return root.(*TrustedRoot), nil
+// tufTargets reads the targets of a TUF repository.
+type tufTargets interface {
+	GetTarget(name string) ([]byte, error)
+}
+
+// getTimestampAuthorities returns the timestamp authorities of the Sigstore
+// trusted root in TUF. With custom roots, set with SIGSTORE_ROOT_FILE, there
+// are none: they are passed with the verifier options instead.
+func getTimestampAuthorities(ctx context.Context) ([]*TimestampAuthority, error) {
+	if env.Getenv(env.VariableSigstoreRootFile) != "" {
+		return nil, nil
+	}
+	tufClient, err := tuf.NewFromEnv(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("initializing tuf: %w", err)
+	}
+	return timestampAuthoritiesFromTUF(ctx, tufClient)
+}
+
+// timestampAuthoritiesFromTUF returns the timestamp authorities of the
+// trusted root target. Repositories without one have no timestamp
+// authority.
+func timestampAuthoritiesFromTUF(ctx context.Context, targets tufTargets) ([]*TimestampAuthority, error) {
+	content, err := targets.GetTarget(trustedRootTarget)
+	if err != nil {
+		logging.FromContext(ctx).Warn(fmt.Sprintf("No trusted root in TUF, RFC 3161 timestamps are not trusted: %v", err),
+			"error", err)
+		return nil, nil
+	}
+	return timestampAuthoritiesFromTrustedRoot(content)
+}
+
+// getFulcioRoots returns the Fulcio roots and intermediates. They are read
+// from TUF on each call, as the cosign helpers read them once per process.
+func getFulcioRoots(ctx context.Context) (*x509.CertPool, *x509.CertPool, error) {
+	if env.Getenv(env.VariableSigstoreRootFile) != "" {
+		roots, err := fulcio.GetRoots()
+		if err != nil {
+			return nil, nil, err
+		}
+		intermediates, err := fulcio.GetIntermediates()
+		if err != nil {
+			return nil, nil, err
+		}
+		return roots, intermediates, nil
+	}
+
+	tufClient, err := tuf.NewFromEnv(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("initializing tuf: %w", err)
+	}
+	targets, err := tufClient.GetTargetsByMeta(tuf.Fulcio, []string{
+		"fulcio.crt.pem", "fulcio_v1.crt.pem", "fulcio_intermediate_v1.crt.pem",
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting targets: %w", err)
+	}
+	if len(targets) == 0 {
+		return nil, nil, errors.New("none of the Fulcio roots have been found")
+	}
+
+	roots := x509.NewCertPool()
+	intermediates := x509.NewCertPool()
+	for _, t := range targets {
+		certs, err := cryptoutils.UnmarshalCertificatesFromPEM(t.Target)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error unmarshalling certificates: %w", err)
+		}
+		for _, cert := range certs {
+			// Root certificates are self-signed.
+			if bytes.Equal(cert.RawSubject, cert.RawIssuer) {
+				roots.AddCert(cert)
+			} else {
+				intermediates.AddCert(cert)
+			}
+		}
+	}
+	return roots, intermediates, nil
+}
+
+// Equal returns true if both trusted roots hold the same certificates,
+// transparency log keys and timestamp authorities.
+func (r *TrustedRoot) Equal(other *TrustedRoot) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return certPoolsEqual(r.FulcioRoot, other.FulcioRoot) &&
+		certPoolsEqual(r.FulcioIntermediates, other.FulcioIntermediates) &&
+		logKeysEqual(r.RekorPubKeys, other.RekorPubKeys) &&
+		logKeysEqual(r.CTPubKeys, other.CTPubKeys) &&
+		timestampAuthoritiesEqual(r.TimestampAuthorities, other.TimestampAuthorities)
+}
+
+func certPoolsEqual(a, b *x509.CertPool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(b)
+}
+
+// timestampAuthoritiesEqual compares the root certificates and validity of
+// the timestamp authorities.
+func timestampAuthoritiesEqual(a, b []*TimestampAuthority) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if (a[i].Root == nil) != (b[i].Root == nil) {
+			return false
+		}
+		if a[i].Root != nil && !a[i].Root.Equal(b[i].Root) {
+			return false
+		}
+		if !a[i].ValidFor.Start.Equal(b[i].ValidFor.Start) || !a[i].ValidFor.End.Equal(b[i].ValidFor.End) {
+			return false
+		}
 	}
-	trustedRoot, err := getTrustedRoot(ctx)
+	return true
+}
+
+// logKeysEqual compares the log IDs, which are derived from the keys, and
+// their status.
+func logKeysEqual(a, b *cosign.TrustedTransparencyLogPubKeys) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if len(a.Keys) != len(b.Keys) {
+		return false
+	}
+	for id, key := range a.Keys {
+		other, ok := b.Keys[id]
+		if !ok || key.Status != other.Status {
+			return false
+		}
+	}
+	return true
+}
+
+const (
+	// DefaultTrustedRootTTL is how long a trusted root is used before it is
+	// refreshed.
+	DefaultTrustedRootTTL = 24 * time.Hour
+
+	// trustedRootRetryInterval is how long the last good trusted root is
+	// used after a failed refresh, before the refresh is retried.
+	trustedRootRetryInterval = 5 * time.Minute
+)
+
+// TrustedRootCache caches a trusted root for long-running processes. Once
+// the TTL has passed, the cached root is still returned while a new one is
+// fetched in the background, and it is kept if the fetch fails. Concurrent
+// callers share a single fetch. It is safe for concurrent use.
+type TrustedRootCache struct {
+	fetch    func(context.Context) (*TrustedRoot, error)
+	ttl      time.Duration
+	onChange func(*TrustedRoot)
+	now      func() time.Time
+
+	mu        sync.Mutex
+	root      *TrustedRoot
+	err       error
+	refreshAt time.Time
+	// inflight is closed when the fetch in progress, if any, completes.
+	inflight chan struct{}
+}
+
+// TrustedRootCacheNew returns a cache of the trusted root returned by fetch,
+// or of the public-good Sigstore trusted root if fetch is nil. A TTL of 0
+// uses DefaultTrustedRootTTL. If set, onChange is called with the new root
+// when a refresh changes the trusted root.
+func TrustedRootCacheNew(fetch func(context.Context) (*TrustedRoot, error),
+	ttl time.Duration, onChange func(*TrustedRoot),
+) *TrustedRootCache {
+	if fetch == nil {
+		fetch = getTrustedRoot
+	}
+	if ttl == 0 {
+		ttl = DefaultTrustedRootTTL
+	}
+	return &TrustedRootCache{
+		fetch:    fetch,
+		ttl:      ttl,
+		onChange: onChange,
+		now:      time.Now,
+	}
+}
+
+// Get returns the cached trusted root. It only waits for a fetch if no
+// trusted root was fetched yet.
+func (c *TrustedRootCache) Get(ctx context.Context) (*TrustedRoot, error) {
+	c.mu.Lock()
+	if c.root != nil {
+		root := c.root
+		if c.inflight == nil && !c.now().Before(c.refreshAt) {
+			c.inflight = make(chan struct{})
+			go c.refresh(context.WithoutCancel(ctx), c.inflight)
+		}
+		c.mu.Unlock()
+		return root, nil
+	}
+
+	inflight := c.inflight
+	if inflight == nil {
+		inflight = make(chan struct{})
+		c.inflight = inflight
+		go c.refresh(context.WithoutCancel(ctx), inflight)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-inflight:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.root == nil {
+		return nil, c.err
+	}
+	return c.root, nil
+}
+
+func (c *TrustedRootCache) refresh(ctx context.Context, done chan struct{}) {
+	defer func() {
+		c.mu.Lock()
+		c.inflight = nil
+		c.mu.Unlock()
+		close(done)
+	}()
+
+	root, err := c.fetch(ctx)
+
+	c.mu.Lock()
 	if err != nil {
-		return nil, err
+		c.err = err
+		c.refreshAt = c.now().Add(trustedRootRetryInterval)
+		hasRoot := c.root != nil
+		c.mu.Unlock()
+		if hasRoot {
+			logging.FromContext(ctx).Warn(fmt.Sprintf("Failed to refresh the trusted root, using the last one: %v", err),
+				"error", err)
+		}
+		return
+	}
+	changed := c.root != nil && !c.root.Equal(root)
+	c.root = root
+	c.err = nil
+	c.refreshAt = c.now().Add(c.ttl)
+	c.mu.Unlock()
+
+	if changed {
+		logging.FromContext(ctx).Info("Trusted root updated")
+		if c.onChange != nil {
+			c.onChange(root)
+		}
 	}
+}
 
-	manager.Store(trustedRoot)
-	return trustedRoot, nil
+// defaultTrustedRootCache caches the public-good Sigstore trusted root to
+// reduce traffic and read contention on the cached TUF files.
+var defaultTrustedRootCache = TrustedRootCacheNew(nil, DefaultTrustedRootTTL, nil)
+
+// TrustedRootSingleton returns the public-good Sigstore trusted root,
+// refreshed every DefaultTrustedRootTTL.
+func TrustedRootSingleton(ctx context.Context) (*TrustedRoot, error) {
+	return defaultTrustedRootCache.Get(ctx)
 }
@@ -0,0 +1,279 @@
+package gha
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/sigstore/pkg/tuf"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+func testTrustedRoot(logIDs ...string) *TrustedRoot {
+	keys := &cosign.TrustedTransparencyLogPubKeys{
+		Keys: make(map[string]cosign.TransparencyLogPubKey),
+	}
+	for _, id := range logIDs {
+		keys.Keys[id] = cosign.TransparencyLogPubKey{}
+	}
+	return &TrustedRoot{RekorPubKeys: keys}
+}
+
+// testFetcher returns the trusted roots or errors queued in results.
+type testFetcher struct {
+	mu      sync.Mutex
+	results []any
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (f *testFetcher) fetch(context.Context) (*TrustedRoot, error) {
+	f.calls.Add(1)
+	if f.release != nil {
+		<-f.release
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := f.results[0]
+	if len(f.results) > 1 {
+		f.results = f.results[1:]
+	}
+	if err, ok := result.(error); ok {
+		return nil, err
+	}
+	return result.(*TrustedRoot), nil
+}
+
+// waitRefresh waits for the background refresh in progress, if any.
+func waitRefresh(c *TrustedRootCache) {
+	c.mu.Lock()
+	inflight := c.inflight
+	c.mu.Unlock()
+	if inflight != nil {
+		<-inflight
+	}
+}
+
+func Test_TrustedRootCache(t *testing.T) {
+	t.Parallel()
+
+	errFetch := errors.New("fetch failed")
+	rootA := testTrustedRoot("a")
+	rootB := testTrustedRoot("b")
+
+	t.Run("single fetch", func(t *testing.T) {
+		t.Parallel()
+
+		f := &testFetcher{results: []any{rootA}, release: make(chan struct{})}
+		c := TrustedRootCacheNew(f.fetch, time.Hour, nil)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if root, err := c.Get(context.Background()); err != nil || root != rootA {
+					t.Errorf("unexpected root %v, error %v", root, err)
+				}
+			}()
+		}
+		close(f.release)
+		wg.Wait()
+		if calls := f.calls.Load(); calls != 1 {
+			t.Errorf("unexpected number of fetches %d", calls)
+		}
+	})
+
+	t.Run("refresh after ttl", func(t *testing.T) {
+		t.Parallel()
+
+		var changed []*TrustedRoot
+		f := &testFetcher{results: []any{rootA, errFetch, rootB}}
+		c := TrustedRootCacheNew(f.fetch, time.Hour, func(root *TrustedRoot) {
+			changed = append(changed, root)
+		})
+		now := time.Now()
+		c.now = func() time.Time { return now }
+
+		steps := []struct {
+			elapsed  time.Duration
+			expected *TrustedRoot
+			calls    int32
+		}{
+			{expected: rootA, calls: 1},
+			// Within the TTL.
+			{elapsed: 30 * time.Minute, expected: rootA, calls: 1},
+			// The refresh fails and the last root is kept.
+			{elapsed: time.Hour, expected: rootA, calls: 2},
+			// The refresh is not retried before the retry interval.
+			{elapsed: time.Minute, expected: rootA, calls: 2},
+			// The refresh succeeds in the background.
+			{elapsed: trustedRootRetryInterval, expected: rootA, calls: 3},
+			{expected: rootB, calls: 3},
+		}
+		for i, step := range steps {
+			now = now.Add(step.elapsed)
+			root, err := c.Get(context.Background())
+			if err != nil {
+				t.Fatalf("step %d: unexpected error: %v", i, err)
+			}
+			waitRefresh(c)
+			if root != step.expected {
+				t.Errorf("step %d: unexpected root", i)
+			}
+			if calls := f.calls.Load(); calls != step.calls {
+				t.Errorf("step %d: unexpected number of fetches %d", i, calls)
+			}
+		}
+		if len(changed) != 1 || changed[0] != rootB {
+			t.Errorf("unexpected changes %v", changed)
+		}
+	})
+
+	t.Run("initial fetch error", func(t *testing.T) {
+		t.Parallel()
+
+		f := &testFetcher{results: []any{errFetch, rootA}}
+		c := TrustedRootCacheNew(f.fetch, time.Hour, nil)
+		if _, err := c.Get(context.Background()); !errors.Is(err, errFetch) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// Without a root, the fetch is retried on the next call.
+		if root, err := c.Get(context.Background()); err != nil || root != rootA {
+			t.Fatalf("unexpected root %v, error %v", root, err)
+		}
+	})
+}
+
+func Test_TrustedRoot_Equal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		a, b     *TrustedRoot
+		expected bool
+	}{
+		{
+			name:     "same log keys",
+			a:        testTrustedRoot("a", "b"),
+			b:        testTrustedRoot("b", "a"),
+			expected: true,
+		},
+		{
+			name: "different log keys",
+			a:    testTrustedRoot("a"),
+			b:    testTrustedRoot("a", "b"),
+		},
+		{
+			name: "nil",
+			a:    testTrustedRoot("a"),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.a.Equal(tt.b); got != tt.expected {
+				t.Errorf("unexpected result %t", got)
+			}
+		})
+	}
+}
+
+// testTargets serves the targets of a TUF repository.
+type testTargets map[string][]byte
+
+func (t testTargets) GetTarget(name string) ([]byte, error) {
+	content, ok := t[name]
+	if !ok {
+		return nil, fmt.Errorf("target %s not found", name)
+	}
+	return content, nil
+}
+
+func Test_timestampAuthoritiesFromTUF(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	tsa := testTSANew(t)
+	at := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	tests := []struct {
+		name        string
+		targets     testTargets
+		authorities int
+		expected    error
+		// verifyErr is the error verifying a bundle of a tile-based log,
+		// with an RFC 3161 timestamp of the authority.
+		verifyErr error
+	}{
+		{
+			name:        "trusted root with the authority",
+			targets:     testTargets{trustedRootTarget: testTrustedRootJSON(t, at.Add(-time.Hour), tsa)},
+			authorities: 1,
+		},
+		{
+			name:        "trusted root with another authority",
+			targets:     testTargets{trustedRootTarget: testTrustedRootJSON(t, at.Add(-time.Hour), testTSANew(t))},
+			authorities: 1,
+			verifyErr:   serrors.ErrorInvalidTimestamp,
+		},
+		{
+			name:        "authority not valid at the timestamp",
+			targets:     testTargets{trustedRootTarget: testTrustedRootJSON(t, at.Add(time.Second), tsa)},
+			authorities: 1,
+			verifyErr:   serrors.ErrorInvalidTimestamp,
+		},
+		{
+			name:      "no trusted root",
+			targets:   testTargets{},
+			verifyErr: serrors.ErrorMissingTimestamp,
+		},
+		{
+			name:     "invalid trusted root",
+			targets:  testTargets{trustedRootTarget: []byte("trusted root")},
+			expected: serrors.ErrorInvalidFormat,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			authorities, err := timestampAuthoritiesFromTUF(ctx, tt.targets)
+			if !errCmp(err, tt.expected) {
+				t.Fatalf(cmp.Diff(err, tt.expected))
+			}
+			if err != nil {
+				return
+			}
+			if len(authorities) != tt.authorities {
+				t.Fatalf("%d authorities, expected %d", len(authorities), tt.authorities)
+			}
+
+			log := testLogNew(t, 5, "entry")
+			bundle := testBundleWithInclusionProof(t, log, tsa, at)
+			trustedRoot := &TrustedRoot{
+				RekorPubKeys: &cosign.TrustedTransparencyLogPubKeys{
+					Keys: map[string]cosign.TransparencyLogPubKey{
+						hex.EncodeToString(testLogID(t, log.pubKey)): {PubKey: log.pubKey, Status: tuf.Active},
+					},
+				},
+				TimestampAuthorities: authorities,
+			}
+			_, err = verifyBundleAndEntry(ctx, bundle, trustedRoot, nil, nil, false, TimestampPolicyTlog)
+			if !errCmp(err, tt.verifyErr) {
+				t.Errorf(cmp.Diff(err, tt.verifyErr))
+			}
+		})
+	}
+}
@@ -6,21 +6,25 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
-	"os"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/secure-systems-lab/go-securesystemslib/dsse"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
-	"github.com/sigstore/rekor/pkg/client"
+	"github.com/sigstore/rekor/pkg/generated/client"
+	"golang.org/x/exp/slices"
 
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/logging"
 	"github.com/slsa-framework/slsa-verifier/v2/options"
 	"github.com/slsa-framework/slsa-verifier/v2/register"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils/container"
 
+	"github.com/sigstore/cosign/v2/pkg/oci"
 	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
 )
 
@@ -31,12 +35,121 @@ func init() {
 	register.RegisterVerifier(VerifierName, GHAVerifierNew())
 }
 
-type GHAVerifier struct{}
+// Config is the trust configuration of a GHAVerifier. Unset fields use the
+// public-good Sigstore instance and the default trusted builders.
+type Config struct {
+	// TrustedRoot is the Sigstore trusted root.
+	TrustedRoot *TrustedRoot
+
+	// TrustedRootCache caches the Sigstore trusted root, if TrustedRoot is
+	// not set.
+	TrustedRootCache *TrustedRootCache
+
+	// RekorClient is the client of the Rekor transparency log.
+	RekorClient *client.Rekor
+
+	// TrustedBuilders are the reusable workflows trusted when no builder ID
+	// is expected. They replace the default trusted builders for all
+	// artifact types.
+	TrustedBuilders map[string]bool
+
+	// HTTPClient is the client for requests to Rekor, if RekorClient is not
+	// set, and to OCI registries.
+	HTTPClient *http.Client
+
+	// RequestTimeout is the timeout of each request to Rekor, if
+	// RekorClient is not set, and to OCI registries. Zero means no timeout.
+	RequestTimeout time.Duration
+
+	// Retry is the retry policy of requests to Rekor, if RekorClient is not
+	// set, and to OCI registries. If nil, DefaultRetryPolicy is used for
+	// Rekor and the default policy of the registry client for registries.
+	Retry *RetryPolicy
+
+	// Checkpoints configures the verification of the checkpoints of
+	// transparency log entries, e.g. their consistency with the last
+	// verified checkpoints. If nil, only their signature is verified.
+	Checkpoints *CheckpointConfig
+
+	// TimestampPolicy is the source of signing time required in Sigstore
+	// bundles. The default requires a transparency log entry.
+	TimestampPolicy TimestampPolicy
+
+	// TimestampAuthorities are trusted in addition to the timestamp
+	// authorities of the trusted root.
+	TimestampAuthorities []*TimestampAuthority
+}
+
+// GHAVerifier verifies provenance generated on GitHub Actions. It is safe
+// for concurrent use.
+type GHAVerifier struct {
+	cfg Config
+}
 
 func GHAVerifierNew() *GHAVerifier {
 	return &GHAVerifier{}
 }
 
+// GHAVerifierWithConfig returns a verifier with its own trust
+// configuration.
+func GHAVerifierWithConfig(cfg Config) *GHAVerifier {
+	return &GHAVerifier{cfg: cfg}
+}
+
+// TrustedRoot returns the Sigstore trusted root of the verifier, with the
+// additional timestamp authorities.
+func (v *GHAVerifier) TrustedRoot(ctx context.Context) (*TrustedRoot, error) {
+	trustedRoot, err := v.trustedRoot(ctx)
+	if err != nil || len(v.cfg.TimestampAuthorities) == 0 {
+		return trustedRoot, err
+	}
+
+	// The trusted root may be shared, e.g. by a cache: extend a copy.
+	extended := *trustedRoot
+	extended.TimestampAuthorities = append(
+		append([]*TimestampAuthority(nil), trustedRoot.TimestampAuthorities...),
+		v.cfg.TimestampAuthorities...)
+	return &extended, nil
+}
+
+func (v *GHAVerifier) trustedRoot(ctx context.Context) (*TrustedRoot, error) {
+	if v.cfg.TrustedRoot != nil {
+		return v.cfg.TrustedRoot, nil
+	}
+	if v.cfg.TrustedRootCache != nil {
+		return v.cfg.TrustedRootCache.Get(ctx)
+	}
+	return TrustedRootSingleton(ctx)
+}
+
+// TimestampPolicy returns the source of signing time required in Sigstore
+// bundles.
+func (v *GHAVerifier) TimestampPolicy() TimestampPolicy {
+	return v.cfg.TimestampPolicy
+}
+
+// Checkpoints returns the configuration of the verification of the
+// checkpoints of transparency log entries, if any.
+func (v *GHAVerifier) Checkpoints() *CheckpointConfig {
+	return v.cfg.Checkpoints
+}
+
+func (v *GHAVerifier) rekorClient() (*client.Rekor, error) {
+	if v.cfg.RekorClient != nil {
+		return v.cfg.RekorClient, nil
+	}
+	return rekorClientNew(defaultRekorAddr, &v.cfg)
+}
+
+// trustedBuilders returns the configured trusted builders, or the default
+// ones for the artifact type.
+func (v *GHAVerifier) trustedBuilders(defaultBuilders map[string]bool) map[string]bool {
+	if v.cfg.TrustedBuilders != nil {
+		return v.cfg.TrustedBuilders
+	}
+	return defaultBuilders
+}
+
 // IsAuthoritativeFor returns true of the verifier can verify provenance
 // generated by the builderID.
 func (v *GHAVerifier) IsAuthoritativeFor(builderID string) bool {
@@ -44,28 +157,62 @@ func (v *GHAVerifier) IsAuthoritativeFor(builderID string) bool {
 	return strings.HasPrefix(builderID, httpsGithubCom)
 }
 
-func verifyEnvAndCert(env *dsse.Envelope,
+// verifyEnvAndCert verifies the signing identity and the provenance in
+// an envelope whose signature and transparency log entry are verified.
+func verifyEnvAndCert(ctx context.Context, env *dsse.Envelope,
 	cert *x509.Certificate,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
 	defaultBuilders map[string]bool,
-) ([]byte, *utils.TrustedBuilderID, error) {
+) (*utils.VerificationResult, error) {
+	if err := utils.RejectUnsupportedOptions(provenanceOpts,
+		utils.OptionWorkflowInputs, utils.OptionBuildTriggers, utils.OptionHostedRunner); err != nil {
+		return nil, err
+	}
+	checks := []utils.Check{utils.CheckSignature, utils.CheckTransparencyLog}
+
 	/* Verify properties of the signing identity. */
 	// Get the workflow info given the certificate information.
 	workflowInfo, err := GetWorkflowInfoFromCertificate(cert)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	// Verify the builder identity.
 	verifiedBuilderID, byob, err := VerifyBuilderIdentity(workflowInfo, builderOpts, defaultBuilders)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
+	checks = append(checks, utils.CheckBuilderID)
 
 	// Verify the source repository from the certificate.
 	if err := VerifyCertficateSourceRepository(workflowInfo, provenanceOpts.ExpectedSourceURI); err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	checks = append(checks, utils.CheckSourceURI)
+
+	// Verify the source commit from the certificate.
+	if err := VerifyCertificateSourceCommit(workflowInfo, provenanceOpts.ExpectedSourceCommit); err != nil {
+		return nil, err
+	}
+	if provenanceOpts.ExpectedSourceCommit != nil {
+		checks = append(checks, utils.CheckSourceCommit)
+	}
+
+	// Verify the trigger event from the certificate.
+	if err := VerifyCertificateBuildTrigger(workflowInfo, provenanceOpts.ExpectedBuildTriggers); err != nil {
+		return nil, err
+	}
+	if len(provenanceOpts.ExpectedBuildTriggers) > 0 {
+		checks = append(checks, utils.CheckBuildTrigger)
+	}
+
+	// Verify the runner environment from the certificate.
+	if err := VerifyCertificateRunnerEnvironment(workflowInfo, provenanceOpts.RequireHostedRunner); err != nil {
+		return nil, err
+	}
+	if provenanceOpts.RequireHostedRunner {
+		checks = append(checks, utils.CheckRunnerEnvironment)
 	}
 
 	// Verify properties of the SLSA provenance.
@@ -75,31 +222,53 @@ func verifyEnvAndCert(env *dsse.Envelope,
 	// is a delegator builder, the user MUST provide an expected builder ID
 	// and we MUST match it against the content of the provenance.
 	if err := VerifyProvenance(env, provenanceOpts, verifiedBuilderID, byob, builderOpts.ExpectedID); err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	checks = appendChecks(checks, provenanceOptionsChecks(provenanceOpts)...)
+	prov, err := verifiedProvenance(env, verifiedBuilderID)
+	if err != nil {
+		return nil, err
 	}
 
 	if byob {
 		// Overwrite the builderID to match the one in the provenance.
 		verifiedBuilderID, err = builderID(env, verifiedBuilderID)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 	}
 
-	fmt.Fprintf(os.Stderr, "Verified build using builder %q at commit %s\n",
-		verifiedBuilderID.String(),
-		workflowInfo.SourceSha1)
+	logging.FromContext(ctx).Info(fmt.Sprintf("Verified build using builder %q at commit %s",
+		verifiedBuilderID.String(), workflowInfo.SourceSha1),
+		"builderID", verifiedBuilderID.String(), "commit", workflowInfo.SourceSha1)
 
 	// Return verified provenance.
 	r, err := base64.StdEncoding.DecodeString(env.Payload)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	return r, verifiedBuilderID, nil
+	return &utils.VerificationResult{
+		Statement:        r,
+		Provenance:       prov,
+		BuilderID:        verifiedBuilderID,
+		Certificate:      cert,
+		WorkflowIdentity: workflowInfo,
+		Checks:           checks,
+	}, nil
+}
+
+// appendChecks appends the checks that are not already in checks.
+func appendChecks(checks []utils.Check, more ...utils.Check) []utils.Check {
+	for _, c := range more {
+		if !slices.Contains(checks, c) {
+			checks = append(checks, c)
+		}
+	}
+	return checks
 }
 
-func verifyNpmEnvAndCert(env *dsse.Envelope,
+func verifyNpmEnvAndCert(ctx context.Context, env *dsse.Envelope,
 	cert *x509.Certificate,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
@@ -132,6 +301,11 @@ func verifyNpmEnvAndCert(env *dsse.Envelope,
 		return nil, err
 	}
 
+	// Verify the source commit from the certificate.
+	if err := VerifyCertificateSourceCommit(workflowInfo, provenanceOpts.ExpectedSourceCommit); err != nil {
+		return nil, err
+	}
+
 	// Users must always provide the builder ID.
 	if builderOpts == nil || builderOpts.ExpectedID == nil {
 		return nil, fmt.Errorf("%w: no expected builder ID", serrors.ErrorInvalidBuilderID)
@@ -201,9 +375,9 @@ func verifyNpmEnvAndCert(env *dsse.Envelope,
 		return nil, err
 	}
 
-	fmt.Fprintf(os.Stderr, "Verified build using builder %s at commit %s\n",
-		trustedBuilderID.String(),
-		workflowInfo.SourceSha1)
+	logging.FromContext(ctx).Info(fmt.Sprintf("Verified build using builder %s at commit %s",
+		trustedBuilderID.String(), workflowInfo.SourceSha1),
+		"builderID", trustedBuilderID.String(), "commit", workflowInfo.SourceSha1)
 
 	return trustedBuilderID, nil
 }
@@ -214,34 +388,73 @@ func (v *GHAVerifier) VerifyArtifact(ctx context.Context,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
 ) ([]byte, *utils.TrustedBuilderID, error) {
-	isSigstoreBundle := IsSigstoreBundle(provenance)
-
-	// This includes a default retry count of 3.
-	rClient, err := client.GetRekorClient(defaultRekorAddr)
+	result, err := v.VerifyArtifactResult(ctx, provenance, artifactHash, provenanceOpts, builderOpts)
 	if err != nil {
 		return nil, nil, err
 	}
+	return result.Statement, result.BuilderID, nil
+}
+
+// VerifyArtifactResult verifies provenance for an artifact and returns the
+// verification result. The provenance is an envelope or a Sigstore bundle.
+func (v *GHAVerifier) VerifyArtifactResult(ctx context.Context,
+	provenance []byte, artifactHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	rClient, err := v.rekorClient()
+	if err != nil {
+		return nil, err
+	}
 
-	trustedRoot, err := TrustedRootSingleton(ctx)
+	trustedRoot, err := v.TrustedRoot(ctx)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
+	return v.verifyArtifactAttestation(ctx, rClient, trustedRoot, provenance, artifactHash,
+		provenanceOpts, builderOpts)
+}
+
+// verifyArtifactAttestation verifies an envelope or a Sigstore bundle for
+// an artifact.
+func (v *GHAVerifier) verifyArtifactAttestation(ctx context.Context,
+	rClient *client.Rekor, trustedRoot *TrustedRoot,
+	provenance []byte, artifactHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	isSigstoreBundle := IsSigstoreBundle(provenance)
+
 	var signedAtt *SignedAttestation
+	var err error
 	/* Verify signature on the intoto attestation. */
 	if isSigstoreBundle {
-		signedAtt, err = VerifyProvenanceBundle(ctx, provenance, trustedRoot)
+		signedAtt, err = VerifyProvenanceBundle(ctx, provenance, trustedRoot, rClient, v.cfg.Checkpoints,
+			v.cfg.TimestampPolicy)
+	} else if v.cfg.TimestampPolicy.requiresTSA() {
+		// Only Sigstore bundles carry RFC 3161 timestamps.
+		err = fmt.Errorf("%w: RFC 3161 timestamps require a Sigstore bundle", serrors.ErrorMissingTimestamp)
 	} else {
-		signedAtt, err = VerifyProvenanceSignature(ctx, trustedRoot, rClient,
+		signedAtt, err = VerifyProvenanceSignature(ctx, trustedRoot, rClient, v.cfg.Checkpoints,
 			provenance, artifactHash)
 	}
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	return verifyEnvAndCert(signedAtt.Envelope, signedAtt.SigningCert,
+	result, err := verifyEnvAndCert(ctx, signedAtt.Envelope, signedAtt.SigningCert,
 		provenanceOpts, builderOpts,
-		utils.MergeMaps(defaultArtifactTrustedReusableWorkflows, defaultBYOBReusableWorkflows))
+		v.trustedBuilders(utils.MergeMaps(defaultArtifactTrustedReusableWorkflows, defaultBYOBReusableWorkflows)))
+	if err != nil {
+		return nil, err
+	}
+	result.RekorEntry = utils.RekorEntryNew(signedAtt.RekorEntry)
+	result.Timestamps = signedAtt.Timestamps
+	if isSigstoreBundle {
+		result.Bundle = provenance
+	}
+	return result, nil
 }
 
 // VerifyImage verifies provenance for an OCI image.
@@ -249,18 +462,31 @@ func (v *GHAVerifier) VerifyImage(ctx context.Context,
 	provenance []byte, artifactImage string, provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
 ) ([]byte, *utils.TrustedBuilderID, error) {
-	/* Retrieve any valid signed attestations that chain up to Fulcio root CA. */
-	trustedRoot, err := TrustedRootSingleton(ctx)
+	result, err := v.VerifyImageResult(ctx, provenance, artifactImage, provenanceOpts, builderOpts)
 	if err != nil {
 		return nil, nil, err
 	}
+	return result.Statement, result.BuilderID, nil
+}
+
+// VerifyImageResult verifies provenance for an OCI image and returns the
+// verification result.
+func (v *GHAVerifier) VerifyImageResult(ctx context.Context,
+	provenance []byte, artifactImage string, provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	/* Retrieve any valid signed attestations that chain up to Fulcio root CA. */
+	trustedRoot, err := v.TrustedRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	var provenanceTargetRepository name.Repository
 	// Consume input for --provenance-repository when set
 	if provenanceOpts.ExpectedProvenanceRepository != nil {
 		provenanceTargetRepository, err = name.NewRepository(*provenanceOpts.ExpectedProvenanceRepository)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 	}
 
@@ -271,6 +497,7 @@ func (v *GHAVerifier) VerifyImage(ctx context.Context,
 	if provenanceTargetRepository.Name() != "" {
 		registryClientOpts = append(registryClientOpts, ociremote.WithTargetRepository(provenanceTargetRepository))
 	}
+	registryClientOpts = append(registryClientOpts, registryOptions(ctx, &v.cfg))
 
 	opts := &cosign.CheckOpts{
 		RegistryClientOpts: registryClientOpts,
@@ -283,34 +510,39 @@ func (v *GHAVerifier) VerifyImage(ctx context.Context,
 	atts, _, err := container.RunCosignImageVerification(ctx,
 		artifactImage, opts)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	/* Now verify properties of the attestations */
 	var errs []error
-	var builderID *utils.TrustedBuilderID
-	var verifiedProvenance []byte
 	for _, att := range atts {
 		pyld, err := att.Payload()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "unexpected error getting payload from OCI registry %s", err)
+			logging.FromContext(ctx).Warn(fmt.Sprintf("unexpected error getting payload from OCI registry %s", err),
+				"error", err)
 			continue
 		}
 		env, err := EnvelopeFromBytes(pyld)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "unexpected error parsing envelope from OCI registry %s", err)
+			logging.FromContext(ctx).Warn(fmt.Sprintf("unexpected error parsing envelope from OCI registry %s", err),
+				"error", err)
 			continue
 		}
 		cert, err := att.Cert()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "unexpected error getting certificate from OCI registry %s", err)
+			logging.FromContext(ctx).Warn(fmt.Sprintf("unexpected error getting certificate from OCI registry %s", err),
+				"error", err)
 			continue
 		}
-		verifiedProvenance, builderID, err = verifyEnvAndCert(env,
+		result, err := verifyEnvAndCert(ctx, env,
 			cert, provenanceOpts, builderOpts,
-			defaultContainerTrustedReusableWorkflows)
+			v.trustedBuilders(defaultContainerTrustedReusableWorkflows))
+		if err == nil && v.cfg.Checkpoints != nil {
+			err = v.verifyAttestationCheckpoint(ctx, att, trustedRoot)
+		}
 		if err == nil {
-			return verifiedProvenance, builderID, nil
+			result.RekorEntry = rekorEntryFromAttestation(att)
+			return result, nil
 		}
 		errs = append(errs, err)
 	}
@@ -321,9 +553,21 @@ func (v *GHAVerifier) VerifyImage(ctx context.Context,
 		if len(errs) > 1 {
 			s = fmt.Sprintf(": %v", errs[1:])
 		}
-		return nil, nil, fmt.Errorf("%w%s", errs[0], s)
+		return nil, fmt.Errorf("%w%s", errs[0], s)
 	}
-	return nil, nil, fmt.Errorf("%w", serrors.ErrorNoValidSignature)
+	return nil, fmt.Errorf("%w", serrors.ErrorNoValidSignature)
+}
+
+// verifyAttestationCheckpoint verifies the checkpoint of the transparency
+// log entry of an image attestation.
+func (v *GHAVerifier) verifyAttestationCheckpoint(ctx context.Context, att oci.Signature,
+	trustedRoot *TrustedRoot,
+) error {
+	rClient, err := v.rekorClient()
+	if err != nil {
+		return err
+	}
+	return verifyAttestationCheckpoint(ctx, rClient, att, trustedRoot, v.cfg.Checkpoints)
 }
 
 // VerifyNpmPackage verifies an npm package tarball.
@@ -332,59 +576,118 @@ func (v *GHAVerifier) VerifyNpmPackage(ctx context.Context,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
 ) ([]byte, *utils.TrustedBuilderID, error) {
-	trustedRoot, err := TrustedRootSingleton(ctx)
+	result, err := v.VerifyNpmPackageResult(ctx, attestations, tarballHash, provenanceOpts, builderOpts)
 	if err != nil {
 		return nil, nil, err
 	}
+	return result.Statement, result.BuilderID, nil
+}
+
+// VerifyNpmPackageResult verifies an npm package tarball and returns the
+// verification result.
+func (v *GHAVerifier) VerifyNpmPackageResult(ctx context.Context,
+	attestations []byte, tarballHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	if err := utils.RejectUnsupportedOptions(provenanceOpts, utils.OptionWorkflowInputs); err != nil {
+		return nil, err
+	}
+	// The checkpoints of npm attestations are not verified.
+	if v.cfg.Checkpoints != nil {
+		return nil, fmt.Errorf("%w: transparency log checkpoints of npm attestations", serrors.ErrorNotSupported)
+	}
+
+	trustedRoot, err := v.TrustedRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	npm, err := NpmNew(ctx, trustedRoot, attestations)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
+	npm.timestampPolicy = v.cfg.TimestampPolicy
 
 	// Verify provenance signature.
 	if err := npm.verifyProvenanceAttestationSignature(); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
+	checks := []utils.Check{utils.CheckSignature, utils.CheckTransparencyLog}
 
 	// Verify provenance builder information.
 	builder, err := npm.verifyBuilderID(
 		provenanceOpts, builderOpts,
-		defaultBYOBReusableWorkflows)
+		v.trustedBuilders(defaultBYOBReusableWorkflows))
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
+	checks = append(checks, utils.CheckBuilderID, utils.CheckSourceURI)
+	checks = appendChecks(checks, provenanceOptionsChecks(provenanceOpts)...)
 
 	// Verify publish attesttation signature.
 	if err := npm.verifyPublishAttestationSignature(); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
+	checks = append(checks, utils.CheckPublishAttestation)
 
 	// Verify publish subject digest.
 	if err := npm.verifyPublishAttestationSubjectDigest(provenanceOpts.ExpectedDigest); err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+
+	// Verify publish subject name.
+	if provenanceOpts.ExpectedSubjectName != nil {
+		if err := npm.verifyPublishAttestationSubjectName(provenanceOpts.ExpectedDigest,
+			*provenanceOpts.ExpectedSubjectName); err != nil {
+			return nil, err
+		}
 	}
 
 	// Verify attestation headers.
 	if err := npm.verifyIntotoHeaders(); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	// Verify package names match.
 	if provenanceOpts != nil {
 		if err := npm.verifyPackageName(provenanceOpts.ExpectedPackageName); err != nil {
-			return nil, nil, err
+			return nil, err
+		}
+		if provenanceOpts.ExpectedPackageName != nil {
+			checks = append(checks, utils.CheckPackageName)
 		}
 
 		if err := npm.verifyPackageVersion(provenanceOpts.ExpectedPackageVersion); err != nil {
-			return nil, nil, err
+			return nil, err
+		}
+		if provenanceOpts.ExpectedPackageVersion != nil {
+			checks = append(checks, utils.CheckPackageVersion)
 		}
 	}
 
-	prov, err := npm.verifiedProvenanceBytes()
+	content, err := npm.verifiedProvenanceBytes()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	prov, err := verifiedProvenance(npm.ProvenanceEnvelope(), builder)
+	if err != nil {
+		return nil, err
+	}
+	workflowInfo, err := GetWorkflowInfoFromCertificate(npm.ProvenanceLeafCertificate())
+	if err != nil {
+		return nil, err
 	}
 
-	return prov, builder, nil
+	return &utils.VerificationResult{
+		Statement:        content,
+		Provenance:       prov,
+		BuilderID:        builder,
+		Certificate:      npm.ProvenanceLeafCertificate(),
+		WorkflowIdentity: workflowInfo,
+		RekorEntry:       utils.RekorEntryNew(npm.verifiedProvenanceAtt.RekorEntry),
+		Timestamps:       npm.verifiedProvenanceAtt.Timestamps,
+		Bundle:           npm.provenanceAttestation.BundleBytes,
+		Checks:           checks,
+	}, nil
 }
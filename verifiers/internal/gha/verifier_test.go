@@ -0,0 +1,55 @@
+package gha
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+func Test_UnsupportedOptions(t *testing.T) {
+	t.Parallel()
+
+	trigger := "release"
+	tests := []struct {
+		name string
+		opts options.ProvenanceOpts
+	}{
+		{
+			name: "build substitutions",
+			opts: options.ProvenanceOpts{
+				ExpectedBuildSubstitutions: map[string]string{"_RELEASE": "true"},
+			},
+		},
+		{
+			name: "gcb trigger",
+			opts: options.ProvenanceOpts{
+				ExpectedGCBTrigger: &trigger,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// The options are rejected before the envelope and the
+			// certificate are looked at.
+			opts := tt.opts
+			_, err := verifyEnvAndCert(context.Background(), nil, nil, &opts, &options.BuilderOpts{}, nil)
+			if !cmp.Equal(err, serrors.ErrorNotSupported, cmpopts.EquateErrors()) {
+				t.Errorf(cmp.Diff(err, serrors.ErrorNotSupported, cmpopts.EquateErrors()))
+			}
+
+			opts = tt.opts
+			_, err = GHAVerifierNew().VerifyNpmPackageResult(context.Background(), nil, "", &opts, &options.BuilderOpts{})
+			if !cmp.Equal(err, serrors.ErrorNotSupported, cmpopts.EquateErrors()) {
+				t.Errorf(cmp.Diff(err, serrors.ErrorNotSupported, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
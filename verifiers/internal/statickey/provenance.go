@@ -0,0 +1,228 @@
+package statickey
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/provenance"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+// Provenance is SLSA provenance signed with static keys.
+type Provenance struct {
+	*utils.SignedEnvelopes
+	verifiedProvenance *provenance.Provenance
+}
+
+// ProvenanceFromBytes parses the DSSE envelopes in the payload, one per line.
+func ProvenanceFromBytes(payload []byte) (*Provenance, error) {
+	envelopes, err := utils.SignedEnvelopesFromBytes(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Provenance{
+		SignedEnvelopes: envelopes,
+	}, nil
+}
+
+// GetVerifiedProvenance returns the verified provenance.
+func (p *Provenance) GetVerifiedProvenance() (*provenance.Provenance, error) {
+	if err := p.IsVerified(); err != nil {
+		return nil, err
+	}
+	return p.verifiedProvenance, nil
+}
+
+// VerifySignature verifies that one of the envelopes is signed by at least
+// threshold of the PEM-encoded public keys.
+func (p *Provenance) VerifySignature(ctx context.Context, publicKeys [][]byte, threshold int) error {
+	return p.SignedEnvelopes.VerifySignature(ctx, publicKeys, threshold, func(payload []byte) error {
+		prov, err := provenance.FromStatement(payload)
+		if err != nil {
+			return err
+		}
+		p.verifiedProvenance = prov
+		return nil
+	})
+}
+
+// VerifyBuilder verifies the builder ID against the expected builder ID.
+func (p *Provenance) VerifyBuilder(builderOpts *options.BuilderOpts) (*utils.TrustedBuilderID, error) {
+	if err := p.IsVerified(); err != nil {
+		return nil, err
+	}
+
+	builderID, err := utils.TrustedBuilderIDNew(p.verifiedProvenance.BuilderID, false)
+	if err != nil {
+		return nil, err
+	}
+	if builderOpts == nil || builderOpts.ExpectedID == nil {
+		return nil, fmt.Errorf("%w: a builder ID is required for provenance signed with public keys",
+			serrors.ErrorInvalidBuilderID)
+	}
+	if err := builderID.MatchesLoose(*builderOpts.ExpectedID, false); err != nil {
+		return nil, err
+	}
+	return builderID, nil
+}
+
+// VerifySubjectDigest verifies that a subject matches the expected digests.
+func (p *Provenance) VerifySubjectDigest(expectedDigests map[string]string) error {
+	if err := p.IsVerified(); err != nil {
+		return err
+	}
+	return utils.VerifySubjectDigests(p.verifiedProvenance.Subjects, expectedDigests)
+}
+
+// VerifySubjectName verifies that the subject matching the expected digests
+// is named expectedName, which may be a glob pattern.
+func (p *Provenance) VerifySubjectName(expectedDigests map[string]string, expectedName string) error {
+	if err := p.IsVerified(); err != nil {
+		return err
+	}
+	return utils.VerifySubjectName(p.verifiedProvenance.Subjects, expectedDigests, expectedName)
+}
+
+// VerifySourceURI verifies the source repository, e.g.
+// git+https://github.com/org/repo.git@refs/heads/main.
+func (p *Provenance) VerifySourceURI(expectedSourceURI string) error {
+	if err := p.IsVerified(); err != nil {
+		return err
+	}
+
+	src, err := p.source()
+	if err != nil {
+		return fmt.Errorf("%w: %v", serrors.ErrorMismatchSource, err)
+	}
+	uri := strings.TrimSuffix(utils.NormalizeGitURI(src.URI), ".git")
+	if uri != utils.NormalizeGitURI(expectedSourceURI) {
+		return fmt.Errorf("%w: expected %q, got %q", serrors.ErrorMismatchSource, expectedSourceURI, src.URI)
+	}
+	return nil
+}
+
+// VerifySourceCommit verifies that the source commit in the provenance
+// matches the expected commit, which may be abbreviated.
+func (p *Provenance) VerifySourceCommit(expectedCommit string) error {
+	if err := p.IsVerified(); err != nil {
+		return err
+	}
+
+	src, err := p.source()
+	if err != nil {
+		return fmt.Errorf("%w: %v", serrors.ErrorMismatchSourceCommit, err)
+	}
+	return utils.VerifySourceCommit(src.Commit, expectedCommit)
+}
+
+// VerifySourceCheckout verifies that the source commit and tag in the
+// provenance are consistent with a local git checkout, and that the commit
+// is an ancestor of the expected branch, or of the default branch if nil.
+func (p *Provenance) VerifySourceCheckout(path string, expectedBranch *string) error {
+	if err := p.IsVerified(); err != nil {
+		return err
+	}
+
+	src, err := p.source()
+	if err != nil {
+		return fmt.Errorf("%w: %v", serrors.ErrorMismatchSourceCheckout, err)
+	}
+
+	// The ref is not recorded by all builders.
+	var tag string
+	if src.Ref != "" {
+		tag, _ = utils.TagFromGitRef(src.Ref)
+	}
+
+	var branch string
+	if expectedBranch != nil {
+		branch = *expectedBranch
+	}
+	return utils.VerifySourceCheckout(path, src.Commit, tag, branch)
+}
+
+// VerifyBranch verifies the branch in the git ref of the source.
+func (p *Provenance) VerifyBranch(expectedBranch string) error {
+	if err := p.IsVerified(); err != nil {
+		return err
+	}
+
+	ref, err := p.sourceRef()
+	if err != nil {
+		return fmt.Errorf("%w: %v", serrors.ErrorMismatchBranch, err)
+	}
+	branch, err := utils.BranchFromGitRef(ref)
+	if err != nil {
+		return fmt.Errorf("%w: %v", serrors.ErrorMismatchBranch, err)
+	}
+	if branch != expectedBranch {
+		return fmt.Errorf("%w: expected branch '%s', got '%s'", serrors.ErrorMismatchBranch, expectedBranch, branch)
+	}
+	return nil
+}
+
+// VerifyTag verifies the tag in the git ref of the source.
+func (p *Provenance) VerifyTag(expectedTag string) error {
+	if err := p.IsVerified(); err != nil {
+		return err
+	}
+
+	tag, err := p.sourceTag()
+	if err != nil {
+		return err
+	}
+	if tag != expectedTag {
+		return fmt.Errorf("%w: expected tag '%s', got '%s'", serrors.ErrorMismatchTag, expectedTag, tag)
+	}
+	return nil
+}
+
+// VerifyVersionedTag verifies the tag in the git ref of the source using
+// semantic versioning.
+func (p *Provenance) VerifyVersionedTag(expectedTag string) error {
+	if err := p.IsVerified(); err != nil {
+		return err
+	}
+
+	tag, err := p.sourceTag()
+	if err != nil {
+		return err
+	}
+	return utils.VerifyVersionedTag(tag, expectedTag)
+}
+
+// source returns the source recorded in the predicate: the configSource or
+// first git material for SLSA v0.2, or the first git resolved dependency.
+func (p *Provenance) source() (*provenance.Source, error) {
+	src := p.verifiedProvenance.Source
+	if src.URI == "" {
+		return nil, fmt.Errorf("%w: no source in the provenance", serrors.ErrorNotPresent)
+	}
+	return &src, nil
+}
+
+func (p *Provenance) sourceRef() (string, error) {
+	src, err := p.source()
+	if err != nil {
+		return "", err
+	}
+	if src.Ref == "" {
+		return "", fmt.Errorf("%w: no git ref in source %q", serrors.ErrorNotPresent, src.URI)
+	}
+	return src.Ref, nil
+}
+
+func (p *Provenance) sourceTag() (string, error) {
+	ref, err := p.sourceRef()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", serrors.ErrorMismatchTag, err)
+	}
+	tag, err := utils.TagFromGitRef(ref)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", serrors.ErrorMismatchTag, err)
+	}
+	return tag, nil
+}
@@ -0,0 +1,206 @@
+package statickey
+
+import (
+	"context"
+	"fmt"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	register "github.com/slsa-framework/slsa-verifier/v2/register"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+const VerifierName = "StaticKey"
+
+//nolint:gochecknoinits
+func init() {
+	register.RegisterVerifier(VerifierName, StaticKeyVerifierNew())
+}
+
+// StaticKeyVerifier verifies SLSA provenance signed with long-lived keys
+// trusted by the user, for any builder. Only the information in the
+// predicate is verified.
+type StaticKeyVerifier struct{}
+
+func StaticKeyVerifierNew() *StaticKeyVerifier {
+	return &StaticKeyVerifier{}
+}
+
+// IsAuthoritativeFor returns true of the verifier can verify provenance
+// generated by the builderID. The verifier is never selected by builder
+// ID, only when public keys are provided.
+func (v *StaticKeyVerifier) IsAuthoritativeFor(builderIDName string) bool {
+	return false
+}
+
+// VerifyArtifact verifies provenance for an artifact.
+func (v *StaticKeyVerifier) VerifyArtifact(ctx context.Context,
+	provenance []byte, artifactHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	result, err := v.VerifyArtifactResult(ctx, provenance, artifactHash, provenanceOpts, builderOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.Statement, result.BuilderID, nil
+}
+
+// VerifyArtifactResult verifies provenance for an artifact and returns the
+// verification result.
+func (v *StaticKeyVerifier) VerifyArtifactResult(ctx context.Context,
+	provenance []byte, artifactHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	return verifyProvenance(ctx, provenance, provenanceOpts, builderOpts)
+}
+
+// VerifyNpmPackage verifies an npm package tarball.
+func (v *StaticKeyVerifier) VerifyNpmPackage(ctx context.Context,
+	attestations []byte, tarballHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	return nil, nil, serrors.ErrorNotSupported
+}
+
+// VerifyNpmPackageResult verifies an npm package tarball.
+func (v *StaticKeyVerifier) VerifyNpmPackageResult(ctx context.Context,
+	attestations []byte, tarballHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	return nil, serrors.ErrorNotSupported
+}
+
+// VerifyImage verifies provenance for an OCI image.
+func (v *StaticKeyVerifier) VerifyImage(ctx context.Context,
+	provenance []byte, artifactImage string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	result, err := v.VerifyImageResult(ctx, provenance, artifactImage, provenanceOpts, builderOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.Statement, result.BuilderID, nil
+}
+
+// VerifyImageResult verifies provenance for an OCI image and returns the
+// verification result.
+func (v *StaticKeyVerifier) VerifyImageResult(ctx context.Context,
+	provenance []byte, artifactImage string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	if len(provenance) == 0 {
+		return nil, fmt.Errorf("%w: provenance must be provided for images verified with public keys",
+			serrors.ErrorNotSupported)
+	}
+
+	return verifyProvenance(ctx, provenance, provenanceOpts, builderOpts)
+}
+
+func verifyProvenance(ctx context.Context, provenance []byte,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	if err := utils.RejectUnsupportedOptions(provenanceOpts); err != nil {
+		return nil, err
+	}
+
+	prov, err := ProvenanceFromBytes(provenance)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify signature on the intoto attestation.
+	if err := prov.VerifySignature(ctx, builderOpts.PublicKeys, builderOpts.SignatureThreshold); err != nil {
+		return nil, err
+	}
+	checks := []utils.Check{utils.CheckSignature}
+
+	// Verify the builder.
+	builderID, err := prov.VerifyBuilder(builderOpts)
+	if err != nil {
+		return nil, err
+	}
+	checks = append(checks, utils.CheckBuilderID)
+
+	// Verify subject digest.
+	expectedDigests := utils.ExpectedDigests(provenanceOpts)
+	if err := prov.VerifySubjectDigest(expectedDigests); err != nil {
+		return nil, err
+	}
+	checks = append(checks, utils.CheckSubjectDigest)
+
+	// Verify subject name.
+	if provenanceOpts.ExpectedSubjectName != nil {
+		if err := prov.VerifySubjectName(expectedDigests, *provenanceOpts.ExpectedSubjectName); err != nil {
+			return nil, err
+		}
+		checks = append(checks, utils.CheckSubjectName)
+	}
+
+	// Verify source.
+	if err := prov.VerifySourceURI(provenanceOpts.ExpectedSourceURI); err != nil {
+		return nil, err
+	}
+	checks = append(checks, utils.CheckSourceURI)
+
+	// Verify the source commit.
+	if provenanceOpts.ExpectedSourceCommit != nil {
+		if err := prov.VerifySourceCommit(*provenanceOpts.ExpectedSourceCommit); err != nil {
+			return nil, err
+		}
+		checks = append(checks, utils.CheckSourceCommit)
+	}
+
+	// Verify the provenance against the local source checkout.
+	if provenanceOpts.SourceCheckout != nil {
+		if err := prov.VerifySourceCheckout(*provenanceOpts.SourceCheckout, provenanceOpts.ExpectedBranch); err != nil {
+			return nil, err
+		}
+		checks = append(checks, utils.CheckSourceCheckout)
+	}
+
+	// Verify branch.
+	if provenanceOpts.ExpectedBranch != nil {
+		if err := prov.VerifyBranch(*provenanceOpts.ExpectedBranch); err != nil {
+			return nil, err
+		}
+		checks = append(checks, utils.CheckBranch)
+	}
+
+	// Verify the tag.
+	if provenanceOpts.ExpectedTag != nil {
+		if err := prov.VerifyTag(*provenanceOpts.ExpectedTag); err != nil {
+			return nil, err
+		}
+		checks = append(checks, utils.CheckTag)
+	}
+
+	// Verify the versioned tag.
+	if provenanceOpts.ExpectedVersionedTag != nil {
+		if err := prov.VerifyVersionedTag(*provenanceOpts.ExpectedVersionedTag); err != nil {
+			return nil, err
+		}
+		checks = append(checks, utils.CheckVersionedTag)
+	}
+
+	content, err := prov.GetVerifiedIntotoStatement()
+	if err != nil {
+		return nil, err
+	}
+	verifiedProvenance, err := prov.GetVerifiedProvenance()
+	if err != nil {
+		return nil, err
+	}
+	return &utils.VerificationResult{
+		Statement:  content,
+		Provenance: verifiedProvenance,
+		BuilderID:  builderID,
+		Checks:     checks,
+	}, nil
+}
@@ -0,0 +1,399 @@
+package statickey
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+const (
+	testDigest = "8fb8a7c1a2b5a8d3d8f0cd9a35a0e1b1d3b0b8a3c7a0d96d9e7ab2a42e2c6ad2"
+	// testDigestSHA512 is the sha512 of the v1.0 provenance subject.
+	testDigestSHA512 = "f43f799324a27fbdf95f67fae0bc55b3358e7595a0497518abae0b3998a6261aeffce29af846a62741b1e17e04666d681d31fc43ca39383ae4450e59969e541e"
+	testSourceURI    = "github.com/org/app"
+	testCommit       = "6f3a9c3d4b2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a"
+	testBuilderID    = "https://ci.example.com/builder"
+)
+
+type testKey struct {
+	private []byte
+	public  []byte
+}
+
+func newTestKey(t *testing.T, key crypto.Signer) testKey {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDer, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKey{
+		private: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+		public:  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDer}),
+	}
+}
+
+// signTestProvenance signs the statement in testdata into a DSSE envelope
+// with a signature by each key.
+func signTestProvenance(t *testing.T, path string, keys ...testKey) []byte {
+	t.Helper()
+	payload, err := os.ReadFile(filepath.Join("testdata", path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var env *dsselib.Envelope
+	for _, key := range keys {
+		signer, err := utils.DsseSignerNew(key.private, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed, err := signer.SignPayload(context.Background(), intoto.PayloadType, payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if env == nil {
+			env = signed
+			continue
+		}
+		env.Signatures = append(env.Signatures, signed.Signatures...)
+	}
+
+	content, err := json.Marshal(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func Test_verifyProvenance(t *testing.T) {
+	t.Parallel()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec := newTestKey(t, ecKey)
+	ed := newTestKey(t, edKey)
+	other := newTestKey(t, otherKey)
+
+	abbreviatedCommit := testCommit[:12]
+	mismatchCommit := "0000000000000000000000000000000000000000"
+	main := "main"
+	release := "release"
+	tag := "v1.2.3"
+	versionedTag := "v1.2"
+	mismatchTag := "v1.2.4"
+	builderVersion := testBuilderID + "@v1"
+	mismatchBuilderID := "https://ci.example.com/other-builder"
+	gcbTrigger := "release"
+
+	tests := []struct {
+		name         string
+		path         string
+		signers      []testKey
+		keys         [][]byte
+		threshold    int
+		builderID    *string
+		noBuilderID  bool
+		sourceURI    string
+		digest       string
+		commit       *string
+		branch       *string
+		tag          *string
+		versionedTag *string
+		// inputs, triggers and hostedRunner are GitHub Actions options,
+		// substitutions and gcbTrigger Google Cloud Build options.
+		inputs        map[string]string
+		triggers      []string
+		hostedRunner  bool
+		substitutions map[string]string
+		gcbTrigger    *string
+		checks        []utils.Check
+		expected      error
+	}{
+		{
+			name: "v0.2 config source",
+			path: "v0.2-tag.json",
+		},
+		{
+			name:      "v0.2 builder version",
+			path:      "v0.2-tag.json",
+			builderID: &builderVersion,
+		},
+		{
+			name:   "v0.2 commit",
+			path:   "v0.2-tag.json",
+			commit: &abbreviatedCommit,
+		},
+		{
+			name: "v0.2 tag",
+			path: "v0.2-tag.json",
+			tag:  &tag,
+			checks: []utils.Check{
+				utils.CheckSignature, utils.CheckBuilderID, utils.CheckSubjectDigest,
+				utils.CheckSourceURI, utils.CheckTag,
+			},
+		},
+		{
+			name:         "v0.2 versioned tag",
+			path:         "v0.2-tag.json",
+			versionedTag: &versionedTag,
+		},
+		{
+			name:     "v0.2 mismatch tag",
+			path:     "v0.2-tag.json",
+			tag:      &mismatchTag,
+			expected: serrors.ErrorMismatchTag,
+		},
+		{
+			name:     "v0.2 branch of tag",
+			path:     "v0.2-tag.json",
+			branch:   &main,
+			expected: serrors.ErrorMismatchBranch,
+		},
+		{
+			name:   "v0.2 source from materials",
+			path:   "v0.2-materials.json",
+			commit: &abbreviatedCommit,
+		},
+		{
+			name:      "v0.2 source from materials with https",
+			path:      "v0.2-materials.json",
+			sourceURI: "https://github.com/org/app",
+		},
+		{
+			name:     "v0.2 no ref in materials",
+			path:     "v0.2-materials.json",
+			tag:      &tag,
+			expected: serrors.ErrorMismatchTag,
+		},
+		{
+			name:   "v1.0 branch",
+			path:   "v1.0-branch.json",
+			branch: &main,
+			commit: &abbreviatedCommit,
+			checks: []utils.Check{
+				utils.CheckSignature, utils.CheckBuilderID, utils.CheckSubjectDigest,
+				utils.CheckSourceURI, utils.CheckSourceCommit, utils.CheckBranch,
+			},
+		},
+		{
+			name:     "v1.0 mismatch branch",
+			path:     "v1.0-branch.json",
+			branch:   &release,
+			expected: serrors.ErrorMismatchBranch,
+		},
+		{
+			name:     "v1.0 mismatch commit",
+			path:     "v1.0-branch.json",
+			commit:   &mismatchCommit,
+			expected: serrors.ErrorMismatchSourceCommit,
+		},
+		{
+			name:      "mismatch source",
+			path:      "v1.0-branch.json",
+			sourceURI: "github.com/org/tools",
+			expected:  serrors.ErrorMismatchSource,
+		},
+		{
+			name:     "mismatch digest",
+			path:     "v1.0-branch.json",
+			digest:   "0000000000000000000000000000000000000000000000000000000000000000",
+			expected: serrors.ErrorMismatchHash,
+		},
+		{
+			name:   "sha512 digest",
+			path:   "v1.0-branch.json",
+			digest: testDigestSHA512,
+		},
+		{
+			name:     "mismatch sha512 digest",
+			path:     "v1.0-branch.json",
+			digest:   strings.Repeat("0", 128),
+			expected: serrors.ErrorMismatchHash,
+		},
+		{
+			name:      "mismatch builder id",
+			path:      "v1.0-branch.json",
+			builderID: &mismatchBuilderID,
+			expected:  serrors.ErrorMismatchBuilderID,
+		},
+		{
+			name:        "no builder id",
+			path:        "v1.0-branch.json",
+			noBuilderID: true,
+			expected:    serrors.ErrorInvalidBuilderID,
+		},
+		{
+			name:    "ed25519",
+			path:    "v1.0-branch.json",
+			signers: []testKey{ed},
+			keys:    [][]byte{ec.public, ed.public},
+		},
+		{
+			name:      "threshold met",
+			path:      "v1.0-branch.json",
+			signers:   []testKey{other, ed, ec},
+			keys:      [][]byte{ec.public, ed.public},
+			threshold: 2,
+		},
+		{
+			name:      "threshold not met",
+			path:      "v1.0-branch.json",
+			signers:   []testKey{other, ec},
+			keys:      [][]byte{ec.public, ed.public},
+			threshold: 2,
+			expected:  serrors.ErrorNoValidSignature,
+		},
+		{
+			name:      "threshold above number of keys",
+			path:      "v1.0-branch.json",
+			threshold: 2,
+			expected:  serrors.ErrorInvalidSignatureThreshold,
+		},
+		{
+			name:     "untrusted key",
+			path:     "v1.0-branch.json",
+			signers:  []testKey{other},
+			expected: serrors.ErrorNoValidSignature,
+		},
+		{
+			name:     "workflow inputs",
+			path:     "v1.0-branch.json",
+			inputs:   map[string]string{"release": "true"},
+			expected: serrors.ErrorNotSupported,
+		},
+		{
+			name:     "build triggers",
+			path:     "v1.0-branch.json",
+			triggers: []string{"push"},
+			expected: serrors.ErrorNotSupported,
+		},
+		{
+			name:         "hosted runner",
+			path:         "v1.0-branch.json",
+			hostedRunner: true,
+			expected:     serrors.ErrorNotSupported,
+		},
+		{
+			name:          "build substitutions",
+			path:          "v1.0-branch.json",
+			substitutions: map[string]string{"_RELEASE": "true"},
+			expected:      serrors.ErrorNotSupported,
+		},
+		{
+			name:       "gcb trigger",
+			path:       "v1.0-branch.json",
+			gcbTrigger: &gcbTrigger,
+			expected:   serrors.ErrorNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			signers := tt.signers
+			if signers == nil {
+				signers = []testKey{ec}
+			}
+			keys := tt.keys
+			if keys == nil {
+				keys = [][]byte{ec.public}
+			}
+			builderID := tt.builderID
+			if builderID == nil && !tt.noBuilderID {
+				id := testBuilderID
+				builderID = &id
+			}
+			sourceURI := testSourceURI
+			if tt.sourceURI != "" {
+				sourceURI = tt.sourceURI
+			}
+			digest := testDigest
+			if tt.digest != "" {
+				digest = tt.digest
+			}
+
+			provenance := signTestProvenance(t, tt.path, signers...)
+			provenanceOpts := &options.ProvenanceOpts{
+				ExpectedSourceURI:          sourceURI,
+				ExpectedDigest:             digest,
+				ExpectedSourceCommit:       tt.commit,
+				ExpectedBranch:             tt.branch,
+				ExpectedTag:                tt.tag,
+				ExpectedVersionedTag:       tt.versionedTag,
+				ExpectedWorkflowInputs:     tt.inputs,
+				ExpectedBuildTriggers:      tt.triggers,
+				RequireHostedRunner:        tt.hostedRunner,
+				ExpectedBuildSubstitutions: tt.substitutions,
+				ExpectedGCBTrigger:         tt.gcbTrigger,
+			}
+			builderOpts := &options.BuilderOpts{
+				ExpectedID:         builderID,
+				PublicKeys:         keys,
+				SignatureThreshold: tt.threshold,
+			}
+
+			result, err := verifyProvenance(context.Background(), provenance, provenanceOpts, builderOpts)
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Fatalf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+			if err != nil {
+				return
+			}
+			content, prov, outBuilderID := result.Statement, result.Provenance, result.BuilderID
+
+			if outBuilderID.Name() != testBuilderID {
+				t.Errorf("unexpected builder ID %q", outBuilderID.String())
+			}
+			if prov.BuilderID != outBuilderID.String() {
+				t.Errorf("unexpected provenance builder ID %q", prov.BuilderID)
+			}
+			if prov.Source.Commit != testCommit {
+				t.Errorf("unexpected provenance source commit %q", prov.Source.Commit)
+			}
+			if tt.checks != nil {
+				if diff := cmp.Diff(tt.checks, result.Checks); diff != "" {
+					t.Errorf("unexpected checks (-want +got):\n%s", diff)
+				}
+			}
+			var statement intoto.StatementHeader
+			if err := json.Unmarshal(content, &statement); err != nil {
+				t.Fatalf("unexpected verified statement: %v", err)
+			}
+		})
+	}
+}
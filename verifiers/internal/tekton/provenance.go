@@ -0,0 +1,207 @@
+package tekton
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/provenance"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/tekton/slsaprovenance/iface"
+	v02 "github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/tekton/slsaprovenance/v0.2"
+	v10 "github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/tekton/slsaprovenance/v1.0"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+type provenanceConstructor func(payload []byte) (iface.Provenance, error)
+
+// predicateTypeMap maps the supported predicate types to their constructor.
+var predicateTypeMap = map[string]provenanceConstructor{
+	v02.PredicateSLSAProvenance: v02.New,
+	v10.PredicateSLSAProvenance: v10.New,
+}
+
+// Provenance is Tekton Chains provenance, signed with static keys.
+type Provenance struct {
+	*utils.SignedEnvelopes
+	verifiedStatement iface.Provenance
+}
+
+// ProvenanceFromBytes parses the DSSE envelopes in the payload, one per line
+// as downloaded with `cosign download attestation`.
+func ProvenanceFromBytes(payload []byte) (*Provenance, error) {
+	envelopes, err := utils.SignedEnvelopesFromBytes(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Provenance{
+		SignedEnvelopes: envelopes,
+	}, nil
+}
+
+// GetVerifiedProvenance returns the verified provenance, with the source
+// Chains recorded in the parameters or materials.
+func (p *Provenance) GetVerifiedProvenance() (*provenance.Provenance, error) {
+	payload, err := p.GetVerifiedIntotoStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	prov, err := provenance.FromStatement(payload)
+	if err != nil {
+		return nil, err
+	}
+	uri, err := p.verifiedStatement.SourceURI()
+	if err != nil {
+		return nil, err
+	}
+	commit, err := p.verifiedStatement.SourceCommit()
+	if err != nil {
+		return nil, err
+	}
+	prov.Source = provenance.SourceNew(uri, map[string]string{"sha1": commit})
+	return prov, nil
+}
+
+// VerifySignature verifies that one of the envelopes is signed by at least
+// threshold of the PEM-encoded public keys.
+func (p *Provenance) VerifySignature(ctx context.Context, publicKeys [][]byte, threshold int) error {
+	if len(publicKeys) == 0 {
+		return fmt.Errorf("%w: a public key is required for Tekton Chains provenance",
+			serrors.ErrorInvalidPublicKey)
+	}
+
+	return p.SignedEnvelopes.VerifySignature(ctx, publicKeys, threshold, func(payload []byte) error {
+		statement, err := provenanceFromPayload(payload)
+		if err != nil {
+			return err
+		}
+		p.verifiedStatement = statement
+		return nil
+	})
+}
+
+func provenanceFromPayload(payload []byte) (iface.Provenance, error) {
+	var header intoto.StatementHeader
+	if err := json.Unmarshal(payload, &header); err != nil {
+		return nil, fmt.Errorf("%w: %v", serrors.ErrorInvalidDssePayload, err)
+	}
+	newProv, ok := predicateTypeMap[header.PredicateType]
+	if !ok {
+		return nil, fmt.Errorf("%w: unexpected predicate type %q", serrors.ErrorInvalidDssePayload, header.PredicateType)
+	}
+	return newProv(payload)
+}
+
+// VerifyBuilder verifies the builder ID and the buildType.
+func (p *Provenance) VerifyBuilder(builderOpts *options.BuilderOpts) (*utils.TrustedBuilderID, error) {
+	if err := p.IsVerified(); err != nil {
+		return nil, err
+	}
+
+	id, err := p.verifiedStatement.BuilderID()
+	if err != nil {
+		return nil, err
+	}
+	// The builder ID is set by the Chains configuration and has no version.
+	builderID, err := utils.TrustedBuilderIDNew(id, false)
+	if err != nil {
+		return nil, err
+	}
+	if builderOpts != nil && builderOpts.ExpectedID != nil {
+		if err := builderID.MatchesLoose(*builderOpts.ExpectedID, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := p.verifiedStatement.BuildType(); err != nil {
+		return nil, err
+	}
+	return builderID, nil
+}
+
+// VerifySubjectDigest verifies that a subject matches the expected digests.
+func (p *Provenance) VerifySubjectDigest(expectedDigests map[string]string) error {
+	if err := p.IsVerified(); err != nil {
+		return err
+	}
+
+	subjects, err := p.verifiedStatement.Subjects()
+	if err != nil {
+		return err
+	}
+	return utils.VerifySubjectDigests(subjects, expectedDigests)
+}
+
+// VerifySubjectName verifies that the subject matching the expected digests
+// is named expectedName, which may be a glob pattern.
+func (p *Provenance) VerifySubjectName(expectedDigests map[string]string, expectedName string) error {
+	if err := p.IsVerified(); err != nil {
+		return err
+	}
+
+	subjects, err := p.verifiedStatement.Subjects()
+	if err != nil {
+		return err
+	}
+	return utils.VerifySubjectName(subjects, expectedDigests, expectedName)
+}
+
+// VerifySourceURI verifies the source repository, recorded by Chains as
+// e.g. git+https://github.com/org/repo.git.
+func (p *Provenance) VerifySourceURI(expectedSourceURI string) error {
+	if err := p.IsVerified(); err != nil {
+		return err
+	}
+
+	uri, err := p.verifiedStatement.SourceURI()
+	if err != nil {
+		return fmt.Errorf("%w: %v", serrors.ErrorMismatchSource, err)
+	}
+	uri = strings.TrimSuffix(strings.TrimPrefix(uri, "git+"), ".git")
+	if strings.HasPrefix(uri, "https://") && !strings.HasPrefix(expectedSourceURI, "https://") {
+		expectedSourceURI = "https://" + expectedSourceURI
+	}
+	if uri != expectedSourceURI {
+		return fmt.Errorf("%w: expected %q, got %q", serrors.ErrorMismatchSource, expectedSourceURI, uri)
+	}
+	return nil
+}
+
+// VerifySourceCommit verifies that the source commit in the provenance
+// matches the expected commit, which may be abbreviated.
+func (p *Provenance) VerifySourceCommit(expectedCommit string) error {
+	if err := p.IsVerified(); err != nil {
+		return err
+	}
+
+	commit, err := p.verifiedStatement.SourceCommit()
+	if err != nil {
+		return fmt.Errorf("%w: %v", serrors.ErrorMismatchSourceCommit, err)
+	}
+	return utils.VerifySourceCommit(commit, expectedCommit)
+}
+
+// VerifySourceCheckout verifies that the source commit in the provenance
+// is in a local git checkout, and that it is an ancestor of the expected
+// branch, or of the default branch if nil.
+func (p *Provenance) VerifySourceCheckout(path string, expectedBranch *string) error {
+	if err := p.IsVerified(); err != nil {
+		return err
+	}
+
+	commit, err := p.verifiedStatement.SourceCommit()
+	if err != nil {
+		return fmt.Errorf("%w: %v", serrors.ErrorMismatchSourceCheckout, err)
+	}
+
+	var branch string
+	if expectedBranch != nil {
+		branch = *expectedBranch
+	}
+	return utils.VerifySourceCheckout(path, commit, "", branch)
+}
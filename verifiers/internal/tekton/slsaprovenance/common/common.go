@@ -0,0 +1,109 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+const (
+	// GitURLParam and GitCommitParam are the suffixes of the Tekton Chains
+	// type hinting parameters for the source repository and commit,
+	// e.g. CHAINS-GIT_URL or source-CHAINS-GIT_URL.
+	GitURLParam    = "CHAINS-GIT_URL"
+	GitCommitParam = "CHAINS-GIT_COMMIT"
+
+	gitPrefix = "git+"
+)
+
+var intotoStatements = map[string]bool{
+	intoto.StatementInTotoV01:         true,
+	"https://in-toto.io/Statement/v1": true,
+}
+
+// Material is a material or resolved dependency of a build.
+type Material struct {
+	Name   string
+	URI    string
+	Digest map[string]string
+}
+
+// ValidateStatementTypes validates the statement and predicate types.
+func ValidateStatementTypes(statementType, predicateType, expectedPredicateType string) error {
+	if _, exists := intotoStatements[statementType]; !exists {
+		return fmt.Errorf("%w: unexpected statement type %q", serrors.ErrorInvalidDssePayload, statementType)
+	}
+	if predicateType != expectedPredicateType {
+		return fmt.Errorf("%w: expected predicate type %q, got %q",
+			serrors.ErrorInvalidDssePayload, expectedPredicateType, predicateType)
+	}
+	return nil
+}
+
+// FindSource returns the source repository URI and commit of a build.
+// The type hinting parameters are preferred. Otherwise, the first git
+// material accepted by isSource is used.
+func FindSource(params map[string]any, materials []Material,
+	isSource func(Material) bool,
+) (string, string, error) {
+	uri, commit, err := findSourceParams(params)
+	if err != nil {
+		return "", "", err
+	}
+	if uri != "" {
+		return uri, commit, nil
+	}
+
+	for _, m := range materials {
+		if !strings.HasPrefix(m.URI, gitPrefix) || !isSource(m) {
+			continue
+		}
+		for _, alg := range []string{"sha1", "gitCommit"} {
+			if commit, ok := m.Digest[alg]; ok && commit != "" {
+				return m.URI, commit, nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("%w: source repository", serrors.ErrorNotPresent)
+}
+
+// findSourceParams returns the source repository URI and commit of the
+// type hinting parameters. The URL and commit parameters are paired by the
+// prefix of their name, e.g. source-CHAINS-GIT_URL and
+// source-CHAINS-GIT_COMMIT. Pairs of different sources are ambiguous.
+func findSourceParams(params map[string]any) (string, string, error) {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var uri, commit, prefix string
+	for _, name := range names {
+		p, ok := strings.CutSuffix(name, GitURLParam)
+		if !ok {
+			continue
+		}
+		u, okURI := stringParam(params, p+GitURLParam)
+		c, okCommit := stringParam(params, p+GitCommitParam)
+		if !okURI || !okCommit {
+			continue
+		}
+		if uri != "" && (u != uri || c != commit) {
+			return "", "", fmt.Errorf("%w: sources %q and %q in type hinting parameters",
+				serrors.ErrorInvalidFormat, prefix+GitURLParam, name)
+		}
+		uri, commit, prefix = u, c, p
+	}
+	return uri, commit, nil
+}
+
+// stringParam returns the value of the string parameter.
+func stringParam(params map[string]any, name string) (string, bool) {
+	s, ok := params[name].(string)
+	return s, ok && s != ""
+}
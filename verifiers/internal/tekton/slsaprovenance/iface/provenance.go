@@ -0,0 +1,27 @@
+package iface
+
+import (
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+)
+
+// Provenance represents Tekton Chains provenance for a predicate type.
+type Provenance interface {
+	// PredicateType returns the predicate type.
+	PredicateType() string
+
+	// BuilderID returns the builder id in the predicate.
+	BuilderID() (string, error)
+
+	// BuildType returns the buildType.
+	BuildType() (string, error)
+
+	// SourceURI is the URI of the source repository, as recorded by Chains,
+	// e.g. git+https://github.com/org/repo.git.
+	SourceURI() (string, error)
+
+	// SourceCommit is the git commit of the source.
+	SourceCommit() (string, error)
+
+	// Subject is the list of intoto subjects in the provenance.
+	Subjects() ([]intoto.Subject, error)
+}
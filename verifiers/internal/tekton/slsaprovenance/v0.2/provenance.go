@@ -0,0 +1,97 @@
+package v02
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/tekton/slsaprovenance/common"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/tekton/slsaprovenance/iface"
+)
+
+// PredicateSLSAProvenance is the SLSA v0.2 provenance predicate type.
+const PredicateSLSAProvenance = slsa02.PredicateSLSAProvenance
+
+// buildTypeRegex matches the buildTypes of the slsa/v1 format, e.g.
+// tekton.dev/v1beta1/TaskRun, and of the slsa/v2alpha2 format, e.g.
+// https://chains.tekton.dev/format/slsa/v2alpha2/type/tekton.dev/v1beta1/PipelineRun.
+var buildTypeRegex = regexp.MustCompile(
+	`^(https://chains\.tekton\.dev/format/slsa/v2alpha[0-9]+/type/)?tekton\.dev/v1(beta1)?/(TaskRun|PipelineRun)$`)
+
+// Provenance is Tekton Chains SLSA v0.2 provenance.
+type Provenance struct {
+	intoto.StatementHeader
+	Predicate slsa02.ProvenancePredicate `json:"predicate"`
+}
+
+// New returns the provenance in the payload.
+func New(payload []byte) (iface.Provenance, error) {
+	var provenance Provenance
+	if err := json.Unmarshal(payload, &provenance); err != nil {
+		return nil, fmt.Errorf("%w: %v", serrors.ErrorInvalidDssePayload, err)
+	}
+	if err := common.ValidateStatementTypes(provenance.Type, provenance.StatementHeader.PredicateType,
+		PredicateSLSAProvenance); err != nil {
+		return nil, err
+	}
+	return &provenance, nil
+}
+
+// PredicateType implements Provenance.PredicateType.
+func (p *Provenance) PredicateType() string {
+	return PredicateSLSAProvenance
+}
+
+// BuilderID implements Provenance.BuilderID.
+func (p *Provenance) BuilderID() (string, error) {
+	return p.Predicate.Builder.ID, nil
+}
+
+// BuildType implements Provenance.BuildType.
+func (p *Provenance) BuildType() (string, error) {
+	if !buildTypeRegex.MatchString(p.Predicate.BuildType) {
+		return "", fmt.Errorf("%w: %q", serrors.ErrorInvalidBuildType, p.Predicate.BuildType)
+	}
+	return p.Predicate.BuildType, nil
+}
+
+// SourceURI implements Provenance.SourceURI.
+func (p *Provenance) SourceURI() (string, error) {
+	uri, _, err := p.source()
+	return uri, err
+}
+
+// SourceCommit implements Provenance.SourceCommit.
+func (p *Provenance) SourceCommit() (string, error) {
+	_, commit, err := p.source()
+	return commit, err
+}
+
+// Subjects implements Provenance.Subjects.
+func (p *Provenance) Subjects() ([]intoto.Subject, error) {
+	if len(p.Subject) == 0 {
+		return nil, fmt.Errorf("%w: no subjects", serrors.ErrorInvalidDssePayload)
+	}
+	return p.Subject, nil
+}
+
+func (p *Provenance) source() (string, string, error) {
+	// The parameters are a map from name to string, array or object values.
+	params, _ := p.Predicate.Invocation.Parameters.(map[string]any)
+
+	materials := make([]common.Material, 0, len(p.Predicate.Materials))
+	for _, m := range p.Predicate.Materials {
+		materials = append(materials, common.Material{URI: m.URI, Digest: m.Digest})
+	}
+
+	// A remotely resolved task or pipeline is also recorded as a git
+	// material, but it is the config source rather than the source.
+	configSource := p.Predicate.Invocation.ConfigSource.URI
+	return common.FindSource(params, materials, func(m common.Material) bool {
+		return configSource == "" || m.URI != configSource
+	})
+}
@@ -0,0 +1,127 @@
+package v10
+
+import (
+	"encoding/json"
+	"fmt"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	slsa1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+	"golang.org/x/exp/slices"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/tekton/slsaprovenance/common"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/tekton/slsaprovenance/iface"
+)
+
+// PredicateSLSAProvenance is the SLSA v1.0 provenance predicate type.
+const PredicateSLSAProvenance = slsa1.PredicateSLSAProvenance
+
+// BuildTypes are the buildTypes of the slsa/v2alpha3 and later formats.
+var BuildTypes = []string{
+	"https://tekton.dev/chains/v2/slsa",
+	"https://tekton.dev/chains/v2/slsa-tekton",
+}
+
+// configDependencies are the names of the resolved dependencies that hold
+// remotely resolved task and pipeline definitions.
+var configDependencies = []string{"task", "pipeline", "pipelineTask"}
+
+// Provenance is Tekton Chains SLSA v1.0 provenance.
+type Provenance struct {
+	intoto.StatementHeader
+	Predicate slsa1.ProvenancePredicate `json:"predicate"`
+}
+
+// New returns the provenance in the payload.
+func New(payload []byte) (iface.Provenance, error) {
+	var provenance Provenance
+	if err := json.Unmarshal(payload, &provenance); err != nil {
+		return nil, fmt.Errorf("%w: %v", serrors.ErrorInvalidDssePayload, err)
+	}
+	if err := common.ValidateStatementTypes(provenance.Type, provenance.StatementHeader.PredicateType,
+		PredicateSLSAProvenance); err != nil {
+		return nil, err
+	}
+	return &provenance, nil
+}
+
+// PredicateType implements Provenance.PredicateType.
+func (p *Provenance) PredicateType() string {
+	return PredicateSLSAProvenance
+}
+
+// BuilderID implements Provenance.BuilderID.
+func (p *Provenance) BuilderID() (string, error) {
+	return p.Predicate.RunDetails.Builder.ID, nil
+}
+
+// BuildType implements Provenance.BuildType.
+func (p *Provenance) BuildType() (string, error) {
+	buildType := p.Predicate.BuildDefinition.BuildType
+	if !slices.Contains(BuildTypes, buildType) {
+		return "", fmt.Errorf("%w: %q", serrors.ErrorInvalidBuildType, buildType)
+	}
+	return buildType, nil
+}
+
+// SourceURI implements Provenance.SourceURI.
+func (p *Provenance) SourceURI() (string, error) {
+	uri, _, err := p.source()
+	return uri, err
+}
+
+// SourceCommit implements Provenance.SourceCommit.
+func (p *Provenance) SourceCommit() (string, error) {
+	_, commit, err := p.source()
+	return commit, err
+}
+
+// Subjects implements Provenance.Subjects.
+func (p *Provenance) Subjects() ([]intoto.Subject, error) {
+	if len(p.Subject) == 0 {
+		return nil, fmt.Errorf("%w: no subjects", serrors.ErrorInvalidDssePayload)
+	}
+	return p.Subject, nil
+}
+
+func (p *Provenance) source() (string, string, error) {
+	materials := make([]common.Material, 0, len(p.Predicate.BuildDefinition.ResolvedDependencies))
+	for _, d := range p.Predicate.BuildDefinition.ResolvedDependencies {
+		materials = append(materials, common.Material{Name: d.Name, URI: d.URI, Digest: d.Digest})
+	}
+
+	return common.FindSource(p.params(), materials, func(m common.Material) bool {
+		return !slices.Contains(configDependencies, m.Name)
+	})
+}
+
+// params returns the parameters of the run, found in the external
+// parameters as runSpec.params: [{"name": ..., "value": ...}].
+func (p *Provenance) params() map[string]any {
+	extParams, ok := p.Predicate.BuildDefinition.ExternalParameters.(map[string]any)
+	if !ok {
+		return nil
+	}
+	runSpec, ok := extParams["runSpec"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	list, ok := runSpec["params"].([]any)
+	if !ok {
+		return nil
+	}
+
+	params := make(map[string]any, len(list))
+	for _, item := range list {
+		param, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, ok := param["name"].(string)
+		if !ok {
+			continue
+		}
+		params[name] = param["value"]
+	}
+	return params
+}
@@ -0,0 +1,194 @@
+package tekton
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	register "github.com/slsa-framework/slsa-verifier/v2/register"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+const VerifierName = "Tekton"
+
+// builderIDPrefix is the prefix of the Tekton Chains builder IDs,
+// e.g. the default https://tekton.dev/chains/v2.
+const builderIDPrefix = "https://tekton.dev/chains/"
+
+//nolint:gochecknoinits
+func init() {
+	register.RegisterVerifier(VerifierName, TektonVerifierNew())
+}
+
+type TektonVerifier struct{}
+
+func TektonVerifierNew() *TektonVerifier {
+	return &TektonVerifier{}
+}
+
+// IsAuthoritativeFor returns true of the verifier can verify provenance
+// generated by the builderID.
+func (v *TektonVerifier) IsAuthoritativeFor(builderIDName string) bool {
+	return strings.HasPrefix(builderIDName, builderIDPrefix)
+}
+
+// VerifyArtifact verifies provenance for an artifact.
+func (v *TektonVerifier) VerifyArtifact(ctx context.Context,
+	provenance []byte, artifactHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	result, err := v.VerifyArtifactResult(ctx, provenance, artifactHash, provenanceOpts, builderOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.Statement, result.BuilderID, nil
+}
+
+// VerifyArtifactResult verifies provenance for an artifact and returns the
+// verification result.
+func (v *TektonVerifier) VerifyArtifactResult(ctx context.Context,
+	provenance []byte, artifactHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	return verifyProvenance(ctx, provenance, provenanceOpts, builderOpts)
+}
+
+// VerifyNpmPackage verifies an npm package tarball.
+func (v *TektonVerifier) VerifyNpmPackage(ctx context.Context,
+	attestations []byte, tarballHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	return nil, nil, serrors.ErrorNotSupported
+}
+
+// VerifyNpmPackageResult verifies an npm package tarball.
+func (v *TektonVerifier) VerifyNpmPackageResult(ctx context.Context,
+	attestations []byte, tarballHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	return nil, serrors.ErrorNotSupported
+}
+
+// VerifyImage verifies provenance for an OCI image.
+func (v *TektonVerifier) VerifyImage(ctx context.Context,
+	provenance []byte, artifactImage string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	result, err := v.VerifyImageResult(ctx, provenance, artifactImage, provenanceOpts, builderOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.Statement, result.BuilderID, nil
+}
+
+// VerifyImageResult verifies provenance for an OCI image and returns the
+// verification result.
+func (v *TektonVerifier) VerifyImageResult(ctx context.Context,
+	provenance []byte, artifactImage string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	if len(provenance) == 0 {
+		// Attestations stored in the registry by Chains are not fetched.
+		return nil, fmt.Errorf("%w: provenance must be provided for Tekton Chains images",
+			serrors.ErrorNotSupported)
+	}
+
+	return verifyProvenance(ctx, provenance, provenanceOpts, builderOpts)
+}
+
+func verifyProvenance(ctx context.Context, provenance []byte,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	if err := utils.RejectUnsupportedOptions(provenanceOpts); err != nil {
+		return nil, err
+	}
+
+	prov, err := ProvenanceFromBytes(provenance)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify signature on the intoto attestation.
+	if err := prov.VerifySignature(ctx, builderOpts.PublicKeys, builderOpts.SignatureThreshold); err != nil {
+		return nil, err
+	}
+	checks := []utils.Check{utils.CheckSignature}
+
+	// Verify the builder.
+	builderID, err := prov.VerifyBuilder(builderOpts)
+	if err != nil {
+		return nil, err
+	}
+	checks = append(checks, utils.CheckBuilderID)
+
+	// Verify subject digest.
+	expectedDigests := utils.ExpectedDigests(provenanceOpts)
+	if err := prov.VerifySubjectDigest(expectedDigests); err != nil {
+		return nil, err
+	}
+	checks = append(checks, utils.CheckSubjectDigest)
+
+	// Verify subject name.
+	if provenanceOpts.ExpectedSubjectName != nil {
+		if err := prov.VerifySubjectName(expectedDigests, *provenanceOpts.ExpectedSubjectName); err != nil {
+			return nil, err
+		}
+		checks = append(checks, utils.CheckSubjectName)
+	}
+
+	// Verify source.
+	if err := prov.VerifySourceURI(provenanceOpts.ExpectedSourceURI); err != nil {
+		return nil, err
+	}
+	checks = append(checks, utils.CheckSourceURI)
+
+	// Verify the source commit.
+	if provenanceOpts.ExpectedSourceCommit != nil {
+		if err := prov.VerifySourceCommit(*provenanceOpts.ExpectedSourceCommit); err != nil {
+			return nil, err
+		}
+		checks = append(checks, utils.CheckSourceCommit)
+	}
+
+	// Chains does not record the git ref, so the branch can only be
+	// verified against a local source checkout.
+	if provenanceOpts.SourceCheckout != nil {
+		if err := prov.VerifySourceCheckout(*provenanceOpts.SourceCheckout, provenanceOpts.ExpectedBranch); err != nil {
+			return nil, err
+		}
+		checks = append(checks, utils.CheckSourceCheckout)
+		if provenanceOpts.ExpectedBranch != nil {
+			checks = append(checks, utils.CheckBranch)
+		}
+	} else if provenanceOpts.ExpectedBranch != nil {
+		return nil, fmt.Errorf("%w: branch verification without a source checkout", serrors.ErrorNotSupported)
+	}
+
+	if provenanceOpts.ExpectedTag != nil || provenanceOpts.ExpectedVersionedTag != nil {
+		return nil, fmt.Errorf("%w: tag verification", serrors.ErrorNotSupported)
+	}
+
+	content, err := prov.GetVerifiedIntotoStatement()
+	if err != nil {
+		return nil, err
+	}
+	verifiedProvenance, err := prov.GetVerifiedProvenance()
+	if err != nil {
+		return nil, err
+	}
+	return &utils.VerificationResult{
+		Statement:  content,
+		Provenance: verifiedProvenance,
+		BuilderID:  builderID,
+		Checks:     checks,
+	}, nil
+}
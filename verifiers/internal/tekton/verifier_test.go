@@ -0,0 +1,353 @@
+package tekton
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+const (
+	testDigest = "8fb8a7c1a2b5a8d3d8f0cd9a35a0e1b1d3b0b8a3c7a0d96d9e7ab2a42e2c6ad2"
+	// testDigestSHA512 is the sha512 of the v1.0 pipelinerun subject.
+	testDigestSHA512 = "f43f799324a27fbdf95f67fae0bc55b3358e7595a0497518abae0b3998a6261aeffce29af846a62741b1e17e04666d681d31fc43ca39383ae4450e59969e541e"
+	testSourceURI    = "github.com/org/app"
+	testCommit       = "6f3a9c3d4b2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a"
+	testBuilderID    = "https://tekton.dev/chains/v2"
+)
+
+type testKey struct {
+	private []byte
+	public  []byte
+}
+
+func newTestKey(t *testing.T) testKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDer, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKey{
+		private: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}),
+		public:  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDer}),
+	}
+}
+
+// signTestProvenance signs the statements in testdata into DSSE
+// envelopes, one per line.
+func signTestProvenance(t *testing.T, key testKey, paths ...string) []byte {
+	t.Helper()
+	signer, err := utils.DsseSignerNew(key.private, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out []byte
+	for _, path := range paths {
+		payload, err := os.ReadFile(filepath.Join("testdata", path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		env, err := signer.SignPayload(context.Background(), intoto.PayloadType, payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		line, err := json.Marshal(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(append(out, line...), '\n')
+	}
+	return out
+}
+
+func Test_verifyProvenance(t *testing.T) {
+	t.Parallel()
+
+	key := newTestKey(t)
+	otherKey := newTestKey(t)
+	abbreviatedCommit := testCommit[:12]
+	mismatchCommit := "0000000000000000000000000000000000000000"
+	branch := "main"
+	tag := "v1.0.0"
+	subjectName := "registry.example.com/org/*"
+	gcbTrigger := "release"
+
+	tests := []struct {
+		name        string
+		paths       []string
+		keys        [][]byte
+		builderID   string
+		sourceURI   string
+		digest      string
+		subjectName *string
+		commit      *string
+		branch      *string
+		tag         *string
+		// inputs, triggers and hostedRunner are GitHub Actions options,
+		// substitutions and gcbTrigger Google Cloud Build options.
+		inputs        map[string]string
+		triggers      []string
+		hostedRunner  bool
+		substitutions map[string]string
+		gcbTrigger    *string
+		expected      error
+	}{
+		{
+			name:  "v0.2 taskrun",
+			paths: []string{"v0.2-taskrun.json"},
+		},
+		{
+			name:      "v0.2 with https source uri",
+			paths:     []string{"v0.2-taskrun.json"},
+			sourceURI: "https://github.com/org/app",
+		},
+		{
+			name:   "v0.2 commit",
+			paths:  []string{"v0.2-taskrun.json"},
+			commit: &abbreviatedCommit,
+		},
+		{
+			name:        "v0.2 subject name",
+			paths:       []string{"v0.2-taskrun.json"},
+			subjectName: &subjectName,
+		},
+		{
+			name:   "v0.2 source from materials",
+			paths:  []string{"v0.2-pipelinerun-materials.json"},
+			commit: &abbreviatedCommit,
+		},
+		{
+			name:     "v0.2 invalid build type",
+			paths:    []string{"v0.2-invalid-buildtype.json"},
+			expected: serrors.ErrorInvalidBuildType,
+		},
+		{
+			name:  "v1.0 pipelinerun",
+			paths: []string{"v1.0-pipelinerun.json"},
+		},
+		{
+			name:   "v1.0 source from resolved dependencies",
+			paths:  []string{"v1.0-pipelinerun-materials.json"},
+			commit: &abbreviatedCommit,
+		},
+		{
+			name:   "v1.0 source params paired by prefix",
+			paths:  []string{"v1.0-pipelinerun-params.json"},
+			commit: &abbreviatedCommit,
+		},
+		{
+			name:     "v1.0 ambiguous source params",
+			paths:    []string{"v1.0-pipelinerun-ambiguous.json"},
+			expected: serrors.ErrorMismatchSource,
+		},
+		{
+			name:  "first verified envelope",
+			paths: []string{"v0.2-invalid-buildtype.json", "v1.0-pipelinerun.json"},
+			// The first verified envelope is the one checked against the options.
+			expected: serrors.ErrorInvalidBuildType,
+		},
+		{
+			name:  "second key",
+			paths: []string{"v1.0-pipelinerun.json"},
+			keys:  [][]byte{otherKey.public, key.public},
+		},
+		{
+			name:     "untrusted key",
+			paths:    []string{"v1.0-pipelinerun.json"},
+			keys:     [][]byte{otherKey.public},
+			expected: serrors.ErrorNoValidSignature,
+		},
+		{
+			name:     "no key",
+			paths:    []string{"v1.0-pipelinerun.json"},
+			keys:     [][]byte{},
+			expected: serrors.ErrorInvalidPublicKey,
+		},
+		{
+			name:      "mismatch builder id",
+			paths:     []string{"v1.0-pipelinerun.json"},
+			builderID: "https://tekton.dev/chains/v3",
+			expected:  serrors.ErrorMismatchBuilderID,
+		},
+		{
+			name:     "mismatch digest",
+			paths:    []string{"v1.0-pipelinerun.json"},
+			digest:   "0000000000000000000000000000000000000000000000000000000000000000",
+			expected: serrors.ErrorMismatchHash,
+		},
+		{
+			name:   "sha512 digest",
+			paths:  []string{"v1.0-pipelinerun.json"},
+			digest: testDigestSHA512,
+		},
+		{
+			name:     "mismatch sha512 digest",
+			paths:    []string{"v1.0-pipelinerun.json"},
+			digest:   strings.Repeat("0", 128),
+			expected: serrors.ErrorMismatchHash,
+		},
+		{
+			name:      "mismatch source",
+			paths:     []string{"v1.0-pipelinerun.json"},
+			sourceURI: "github.com/org/catalog",
+			expected:  serrors.ErrorMismatchSource,
+		},
+		{
+			name:      "config source is not the source",
+			paths:     []string{"v1.0-pipelinerun-materials.json"},
+			sourceURI: "github.com/org/catalog",
+			expected:  serrors.ErrorMismatchSource,
+		},
+		{
+			name:     "mismatch commit",
+			paths:    []string{"v1.0-pipelinerun.json"},
+			commit:   &mismatchCommit,
+			expected: serrors.ErrorMismatchSourceCommit,
+		},
+		{
+			name:     "branch",
+			paths:    []string{"v1.0-pipelinerun.json"},
+			branch:   &branch,
+			expected: serrors.ErrorNotSupported,
+		},
+		{
+			name:     "tag",
+			paths:    []string{"v1.0-pipelinerun.json"},
+			tag:      &tag,
+			expected: serrors.ErrorNotSupported,
+		},
+		{
+			name:     "workflow inputs",
+			paths:    []string{"v1.0-pipelinerun.json"},
+			inputs:   map[string]string{"release": "true"},
+			expected: serrors.ErrorNotSupported,
+		},
+		{
+			name:     "build triggers",
+			paths:    []string{"v1.0-pipelinerun.json"},
+			triggers: []string{"push"},
+			expected: serrors.ErrorNotSupported,
+		},
+		{
+			name:         "hosted runner",
+			paths:        []string{"v1.0-pipelinerun.json"},
+			hostedRunner: true,
+			expected:     serrors.ErrorNotSupported,
+		},
+		{
+			name:          "build substitutions",
+			paths:         []string{"v1.0-pipelinerun.json"},
+			substitutions: map[string]string{"_RELEASE": "true"},
+			expected:      serrors.ErrorNotSupported,
+		},
+		{
+			name:       "gcb trigger",
+			paths:      []string{"v1.0-pipelinerun.json"},
+			gcbTrigger: &gcbTrigger,
+			expected:   serrors.ErrorNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provenance := signTestProvenance(t, key, tt.paths...)
+
+			keys := tt.keys
+			if keys == nil {
+				keys = [][]byte{key.public}
+			}
+			builderID := testBuilderID
+			if tt.builderID != "" {
+				builderID = tt.builderID
+			}
+			sourceURI := testSourceURI
+			if tt.sourceURI != "" {
+				sourceURI = tt.sourceURI
+			}
+			digest := testDigest
+			if tt.digest != "" {
+				digest = tt.digest
+			}
+
+			provenanceOpts := &options.ProvenanceOpts{
+				ExpectedSourceURI:          sourceURI,
+				ExpectedDigest:             digest,
+				ExpectedSubjectName:        tt.subjectName,
+				ExpectedSourceCommit:       tt.commit,
+				ExpectedBranch:             tt.branch,
+				ExpectedTag:                tt.tag,
+				ExpectedWorkflowInputs:     tt.inputs,
+				ExpectedBuildTriggers:      tt.triggers,
+				RequireHostedRunner:        tt.hostedRunner,
+				ExpectedBuildSubstitutions: tt.substitutions,
+				ExpectedGCBTrigger:         tt.gcbTrigger,
+			}
+			builderOpts := &options.BuilderOpts{
+				ExpectedID: &builderID,
+				PublicKeys: keys,
+			}
+
+			result, err := verifyProvenance(context.Background(), provenance, provenanceOpts, builderOpts)
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Fatalf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+			if err != nil {
+				return
+			}
+			content, prov, outBuilderID := result.Statement, result.Provenance, result.BuilderID
+
+			if outBuilderID.String() != testBuilderID {
+				t.Errorf("unexpected builder ID %q", outBuilderID.String())
+			}
+			if prov.Source.Commit != testCommit {
+				t.Errorf("unexpected provenance source commit %q", prov.Source.Commit)
+			}
+			var statement intoto.StatementHeader
+			if err := json.Unmarshal(content, &statement); err != nil {
+				t.Fatalf("unexpected verified statement: %v", err)
+			}
+		})
+	}
+}
+
+func Test_IsAuthoritativeFor(t *testing.T) {
+	t.Parallel()
+
+	v := TektonVerifierNew()
+	for id, expected := range map[string]bool{
+		"https://tekton.dev/chains/v2":                         true,
+		"https://cloudbuild.googleapis.com/GoogleHostedWorker": false,
+		"https://tekton.dev/pipelines":                         false,
+	} {
+		if got := v.IsAuthoritativeFor(id); got != expected {
+			t.Errorf("IsAuthoritativeFor(%q): expected %v, got %v", id, expected, got)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// VerifySourceCheckout cross-checks provenance information against a local
+// git checkout of the source repository. It verifies that the commit exists,
+// that the tag, if not empty, resolves to the commit and that the commit is
+// an ancestor of the branch. If the branch is empty, the remote's default
+// branch is used, or the checked out branch if there is no remote.
+// No network access is performed: the checkout must be up-to-date.
+func VerifySourceCheckout(path, commit, tag, branch string) error {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", serrors.ErrorInvalidSourceCheckout, path, err)
+	}
+
+	// Verify the commit exists.
+	if len(commit) != 40 {
+		return fmt.Errorf("%w: commit %q is not a full SHA-1", serrors.ErrorInvalidFormat, commit)
+	}
+	c, err := repo.CommitObject(plumbing.NewHash(commit))
+	if err != nil {
+		return fmt.Errorf("%w: commit %s: %v", serrors.ErrorMismatchSourceCheckout, commit, err)
+	}
+
+	// Verify the tag resolves to the commit.
+	if tag != "" {
+		tagCommit, err := resolveTag(repo, tag)
+		if err != nil {
+			return fmt.Errorf("%w: tag %q: %v", serrors.ErrorMismatchSourceCheckout, tag, err)
+		}
+		if tagCommit.Hash != c.Hash {
+			return fmt.Errorf("%w: tag %q resolves to %s, expected %s", serrors.ErrorMismatchSourceCheckout,
+				tag, tagCommit.Hash, c.Hash)
+		}
+	}
+
+	// Verify the commit is reachable from the branch. This rejects commits
+	// that were force-pushed away or never merged.
+	branchRef, err := resolveBranch(repo, branch)
+	if err != nil {
+		return fmt.Errorf("%w: branch %q: %v", serrors.ErrorMismatchSourceCheckout, branch, err)
+	}
+	branchCommit, err := repo.CommitObject(branchRef.Hash())
+	if err != nil {
+		return fmt.Errorf("%w: branch %q: %v", serrors.ErrorMismatchSourceCheckout, branchRef.Name(), err)
+	}
+	ok, err := c.IsAncestor(branchCommit)
+	if err != nil {
+		return fmt.Errorf("%w: %v", serrors.ErrorMismatchSourceCheckout, err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: commit %s is not an ancestor of %q", serrors.ErrorMismatchSourceCheckout,
+			c.Hash, branchRef.Name())
+	}
+
+	return nil
+}
+
+// resolveTag returns the commit a tag points to, peeling annotated tags,
+// including annotated tags of other tags.
+func resolveTag(repo *git.Repository, tag string) (*object.Commit, error) {
+	ref, err := repo.Reference(plumbing.NewTagReferenceName(tag), true)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := ref.Hash()
+	for {
+		tagObj, err := repo.TagObject(hash)
+		switch {
+		case err == nil:
+			// Annotated tag, which may point to another tag.
+			hash = tagObj.Target
+		case errors.Is(err, plumbing.ErrObjectNotFound):
+			// Lightweight tag, or the end of a chain of annotated tags.
+			return repo.CommitObject(hash)
+		default:
+			return nil, err
+		}
+	}
+}
+
+// resolveBranch returns the reference for a branch. The remote-tracking
+// branch is preferred because it reflects the state of the remote.
+func resolveBranch(repo *git.Repository, branch string) (*plumbing.Reference, error) {
+	if branch == "" {
+		if ref, err := repo.Reference(plumbing.NewRemoteHEADReferenceName(git.DefaultRemoteName), true); err == nil {
+			return ref, nil
+		}
+		head, err := repo.Reference(plumbing.HEAD, false)
+		if err != nil {
+			return nil, err
+		}
+		if head.Type() != plumbing.SymbolicReference {
+			return nil, errors.New("HEAD is detached, a branch must be provided")
+		}
+		return repo.Reference(head.Target(), true)
+	}
+
+	for _, name := range []plumbing.ReferenceName{
+		plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branch),
+		plumbing.NewBranchReferenceName(branch),
+	} {
+		if ref, err := repo.Reference(name, true); err == nil {
+			return ref, nil
+		}
+	}
+	return nil, plumbing.ErrReferenceNotFound
+}
@@ -0,0 +1,212 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+var testSignature = &object.Signature{
+	Name:  "slsa",
+	Email: "slsa@example.com",
+	When:  time.Unix(1700000000, 0),
+}
+
+func commitFile(t *testing.T, wt *git.Worktree, msg string) plumbing.Hash {
+	t.Helper()
+	h, err := wt.Commit(msg, &git.CommitOptions{
+		Author:            testSignature,
+		AllowEmptyCommits: true,
+	})
+	if err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	return h
+}
+
+// newTestCheckout creates a repository with the following history:
+//
+//	main:   first - second
+//	orphan:       \ third
+//
+// first has a lightweight tag v1.0.0 and second an annotated tag v2.0.0,
+// itself tagged by the annotated tag v2.0.0-nested.
+func newTestCheckout(t *testing.T) (string, map[string]plumbing.Hash) {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("PlainInit: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Worktree: %v", err)
+	}
+
+	commits := map[string]plumbing.Hash{}
+	commits["first"] = commitFile(t, wt, "first")
+	if _, err := repo.CreateTag("v1.0.0", commits["first"], nil); err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+
+	if err := wt.Checkout(&git.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName("orphan"),
+		Create: true,
+	}); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	commits["third"] = commitFile(t, wt, "third")
+
+	if err := wt.Checkout(&git.CheckoutOptions{
+		Branch: plumbing.Master,
+	}); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	commits["second"] = commitFile(t, wt, "second")
+	if _, err := repo.CreateTag("v2.0.0", commits["second"], &git.CreateTagOptions{
+		Tagger:  testSignature,
+		Message: "v2.0.0",
+	}); err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	ref, err := repo.Tag("v2.0.0")
+	if err != nil {
+		t.Fatalf("Tag: %v", err)
+	}
+	if _, err := repo.CreateTag("v2.0.0-nested", ref.Hash(), &git.CreateTagOptions{
+		Tagger:  testSignature,
+		Message: "v2.0.0-nested",
+	}); err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+
+	return dir, commits
+}
+
+func Test_VerifySourceCheckout(t *testing.T) {
+	t.Parallel()
+
+	dir, commits := newTestCheckout(t)
+	testCases := []struct {
+		name   string
+		path   string
+		commit string
+		tag    string
+		branch string
+		err    error
+	}{
+		{
+			name:   "commit on default branch",
+			commit: commits["first"].String(),
+		},
+		{
+			name:   "head of default branch",
+			commit: commits["second"].String(),
+		},
+		{
+			name:   "commit on expected branch",
+			commit: commits["first"].String(),
+			branch: "master",
+		},
+		{
+			name:   "commit not an ancestor",
+			commit: commits["third"].String(),
+			err:    serrors.ErrorMismatchSourceCheckout,
+		},
+		{
+			name:   "commit not an ancestor of expected branch",
+			commit: commits["third"].String(),
+			branch: "master",
+			err:    serrors.ErrorMismatchSourceCheckout,
+		},
+		{
+			name:   "commit on other branch",
+			commit: commits["third"].String(),
+			branch: "orphan",
+		},
+		{
+			name:   "branch not present",
+			commit: commits["first"].String(),
+			branch: "release",
+			err:    serrors.ErrorMismatchSourceCheckout,
+		},
+		{
+			name:   "commit not present",
+			commit: "2ce3f90facdb51aeb950d5bc641e981be61fdf48",
+			err:    serrors.ErrorMismatchSourceCheckout,
+		},
+		{
+			name:   "abbreviated commit",
+			commit: commits["first"].String()[:12],
+			err:    serrors.ErrorInvalidFormat,
+		},
+		{
+			name:   "lightweight tag",
+			commit: commits["first"].String(),
+			tag:    "v1.0.0",
+		},
+		{
+			name:   "annotated tag",
+			commit: commits["second"].String(),
+			tag:    "v2.0.0",
+		},
+		{
+			name:   "nested annotated tag",
+			commit: commits["second"].String(),
+			tag:    "v2.0.0-nested",
+		},
+		{
+			name:   "nested annotated tag mismatch",
+			commit: commits["first"].String(),
+			tag:    "v2.0.0-nested",
+			err:    serrors.ErrorMismatchSourceCheckout,
+		},
+		{
+			name:   "tag mismatch",
+			commit: commits["second"].String(),
+			tag:    "v1.0.0",
+			err:    serrors.ErrorMismatchSourceCheckout,
+		},
+		{
+			name:   "annotated tag mismatch",
+			commit: commits["first"].String(),
+			tag:    "v2.0.0",
+			err:    serrors.ErrorMismatchSourceCheckout,
+		},
+		{
+			name:   "tag not present",
+			commit: commits["first"].String(),
+			tag:    "v3.0.0",
+			err:    serrors.ErrorMismatchSourceCheckout,
+		},
+		{
+			name:   "not a repository",
+			path:   t.TempDir(),
+			commit: commits["first"].String(),
+			err:    serrors.ErrorInvalidSourceCheckout,
+		},
+	}
+
+	for i := range testCases {
+		tt := testCases[i]
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := tt.path
+			if path == "" {
+				path = dir
+			}
+			err := VerifySourceCheckout(path, tt.commit, tt.tag, tt.branch)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
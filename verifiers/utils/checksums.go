@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// Checksums are the digests of the files listed in a checksums file, by
+// file name. The digests of a file map algorithm names, as in in-toto digest
+// sets, to hex-encoded digests.
+type Checksums map[string]map[string]string
+
+// bsdChecksumLine is a line in the BSD format, e.g. "SHA256 (name) = digest",
+// as written by `sha256sum --tag`.
+var bsdChecksumLine = regexp.MustCompile(`^([A-Za-z0-9-]+) \((.+)\) = ([0-9A-Fa-f]+)$`)
+
+// checksumLengths maps the hex digest lengths of lines in the GNU format to
+// the algorithm of the sha256sum, sha384sum and sha512sum tools.
+var checksumLengths = map[int]string{
+	64:  "sha256",
+	96:  "sha384",
+	128: "sha512",
+}
+
+// checksumNameUnescaper unescapes the file names of lines starting with a
+// backslash, as written by GNU tools for names with a backslash or newline.
+var checksumNameUnescaper = strings.NewReplacer(`\\`, `\`, `\n`, "\n")
+
+// ParseChecksums parses a checksums file, such as SHA256SUMS. Each line is
+// either in the GNU format of sha256sum and sha512sum, "digest  name" or
+// "digest *name" in binary mode, or in the BSD format,
+// "SHA256 (name) = digest". Blank lines and comments starting with '#' are
+// ignored.
+func ParseChecksums(content []byte) (Checksums, error) {
+	checksums := make(Checksums)
+	for i, line := range bytes.Split(content, []byte("\n")) {
+		text := strings.TrimSuffix(string(line), "\r")
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, algo, digest, err := parseChecksumLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", serrors.ErrorInvalidChecksums, i+1, err)
+		}
+
+		digests, ok := checksums[name]
+		if !ok {
+			digests = make(map[string]string)
+			checksums[name] = digests
+		}
+		if prev, ok := digests[algo]; ok && prev != digest {
+			return nil, fmt.Errorf("%w: line %d: conflicting %s digests for %q",
+				serrors.ErrorInvalidChecksums, i+1, algo, name)
+		}
+		digests[algo] = digest
+	}
+	if len(checksums) == 0 {
+		return nil, fmt.Errorf("%w: no checksum", serrors.ErrorInvalidChecksums)
+	}
+	return checksums, nil
+}
+
+func parseChecksumLine(line string) (name, algo, digest string, err error) {
+	escaped := strings.HasPrefix(line, `\`)
+	if escaped {
+		line = line[1:]
+	}
+
+	if m := bsdChecksumLine.FindStringSubmatch(line); m != nil {
+		algo, err = NormalizeDigestAlgorithm(m[1])
+		if err != nil {
+			return "", "", "", err
+		}
+		name, digest = m[2], m[3]
+	} else {
+		var rest string
+		var ok bool
+		digest, rest, ok = strings.Cut(line, " ")
+		if !ok || rest == "" {
+			return "", "", "", fmt.Errorf("expected 'digest  name' or 'ALGORITHM (name) = digest'")
+		}
+		// The second separator is a space in text mode or '*' in binary
+		// mode. Some tools only write one space.
+		name = rest
+		if rest[0] == ' ' || rest[0] == '*' {
+			name = rest[1:]
+		}
+		algo, ok = checksumLengths[len(digest)]
+		if !ok {
+			return "", "", "", fmt.Errorf("unexpected digest length %d", len(digest))
+		}
+	}
+
+	if _, err := hex.DecodeString(digest); err != nil {
+		return "", "", "", fmt.Errorf("invalid digest: %v", err)
+	}
+	if escaped {
+		name = checksumNameUnescaper.Replace(name)
+	}
+	if name == "" {
+		return "", "", "", fmt.Errorf("empty file name")
+	}
+	return path.Clean(name), algo, strings.ToLower(digest), nil
+}
+
+// Digests returns the digests of the file at the given path, listed by path
+// or, if the path is not listed, by base name. Only entries that list a bare
+// file name match by base name: "build/dist/app" does not match "dist/app".
+func (c Checksums) Digests(filePath string) (map[string]string, error) {
+	name := path.Clean(filepath.ToSlash(filePath))
+	if digests, ok := c[name]; ok {
+		return digests, nil
+	}
+	if digests, ok := c[path.Base(name)]; ok {
+		return digests, nil
+	}
+	return nil, fmt.Errorf("%w: no checksum for %q", serrors.ErrorMismatchChecksum, filePath)
+}
+
+// Verify verifies that the digests of the file at the given path match its
+// checksums, as defined by MatchDigests.
+func (c Checksums) Verify(filePath string, digests map[string]string) error {
+	expected, err := c.Digests(filePath)
+	if err != nil {
+		return err
+	}
+	if !MatchDigests(digests, expected) {
+		return fmt.Errorf("%w: expected digests '%v' for %q", serrors.ErrorMismatchChecksum,
+			expected, filePath)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+func Test_ParseChecksums(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		content   string
+		checksums Checksums
+		err       error
+	}{
+		{
+			name:      "sha256sum",
+			content:   abcSha256 + "  abc\n",
+			checksums: Checksums{"abc": {"sha256": abcSha256}},
+		},
+		{
+			name:      "sha256sum binary mode",
+			content:   abcSha256 + " *abc\n",
+			checksums: Checksums{"abc": {"sha256": abcSha256}},
+		},
+		{
+			name:      "single space",
+			content:   abcSha256 + " abc\n",
+			checksums: Checksums{"abc": {"sha256": abcSha256}},
+		},
+		{
+			name:      "sha512sum",
+			content:   abcSha512 + "  dist/abc\n",
+			checksums: Checksums{"dist/abc": {"sha512": abcSha512}},
+		},
+		{
+			name:      "BSD format",
+			content:   "SHA256 (abc) = " + abcSha256 + "\nSHA512 (abc) = " + abcSha512 + "\n",
+			checksums: Checksums{"abc": {"sha256": abcSha256, "sha512": abcSha512}},
+		},
+		{
+			name:      "BSD format with other algorithm",
+			content:   "SHA3-256 (abc) = " + abcSha3256,
+			checksums: Checksums{"abc": {"sha3_256": abcSha3256}},
+		},
+		{
+			name:      "name with spaces and parentheses",
+			content:   "SHA256 (a (b) = c) = " + abcSha256 + "\n" + abcSha512 + "  a b\n",
+			checksums: Checksums{"a (b) = c": {"sha256": abcSha256}, "a b": {"sha512": abcSha512}},
+		},
+		{
+			name:      "escaped name",
+			content:   `\` + abcSha256 + `  a\\b\nc`,
+			checksums: Checksums{"a\\b\nc": {"sha256": abcSha256}},
+		},
+		{
+			name:      "upper case digest",
+			content:   strings.ToUpper(abcSha256) + "  abc\r\n",
+			checksums: Checksums{"abc": {"sha256": abcSha256}},
+		},
+		{
+			name:      "relative path",
+			content:   abcSha256 + "  ./abc\n",
+			checksums: Checksums{"abc": {"sha256": abcSha256}},
+		},
+		{
+			name:      "comments and blank lines",
+			content:   "# checksums\n\n" + abcSha256 + "  abc\n\n",
+			checksums: Checksums{"abc": {"sha256": abcSha256}},
+		},
+		{
+			name:      "same digest twice",
+			content:   abcSha256 + "  abc\nSHA256 (abc) = " + abcSha256,
+			checksums: Checksums{"abc": {"sha256": abcSha256}},
+		},
+		{
+			name:    "conflicting digests",
+			content: abcSha256 + "  abc\n" + abcSha3256 + "  abc\n",
+			err:     serrors.ErrorInvalidChecksums,
+		},
+		{
+			name:    "unknown digest length",
+			content: "ba7816bf  abc\n",
+			err:     serrors.ErrorInvalidChecksums,
+		},
+		{
+			name:    "weak algorithm",
+			content: "MD5 (abc) = 900150983cd24fb0d6963f7d28e17f72\n",
+			err:     serrors.ErrorInvalidChecksums,
+		},
+		{
+			name:    "invalid digest",
+			content: strings.Repeat("z", 64) + "  abc\n",
+			err:     serrors.ErrorInvalidChecksums,
+		},
+		{
+			name:    "no file name",
+			content: abcSha256 + "\n",
+			err:     serrors.ErrorInvalidChecksums,
+		},
+		{
+			name:    "empty",
+			content: "# no checksum\n",
+			err:     serrors.ErrorInvalidChecksums,
+		},
+	}
+	for _, tt := range testCases {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			checksums, err := ParseChecksums([]byte(tt.content))
+			if !cmp.Equal(err, tt.err, cmpopts.EquateErrors()) {
+				t.Fatalf(cmp.Diff(err, tt.err, cmpopts.EquateErrors()))
+			}
+			if diff := cmp.Diff(tt.checksums, checksums); diff != "" {
+				t.Errorf("unexpected checksums (-want +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_ChecksumsVerify(t *testing.T) {
+	t.Parallel()
+
+	checksums := Checksums{
+		"abc":         {"sha256": abcSha256, "sha512": abcSha512},
+		"dist/sha512": {"sha512": abcSha512},
+		"a/dup":       {"sha256": abcSha256},
+		"b/dup":       {"sha256": abcSha256},
+	}
+	testCases := []struct {
+		name    string
+		path    string
+		digests map[string]string
+		err     error
+	}{
+		{
+			name:    "matching digest",
+			path:    "abc",
+			digests: map[string]string{"sha256": abcSha256},
+		},
+		{
+			name:    "matching digests",
+			path:    "abc",
+			digests: map[string]string{"sha256": abcSha256, "sha512": abcSha512},
+		},
+		{
+			name:    "listed by base name",
+			path:    "/tmp/release/abc",
+			digests: map[string]string{"sha256": abcSha256},
+		},
+		{
+			name:    "listed by path",
+			path:    "./dist/sha512",
+			digests: map[string]string{"sha512": abcSha512},
+		},
+		{
+			name:    "mismatched digest",
+			path:    "abc",
+			digests: map[string]string{"sha256": abcSha3256, "sha512": abcSha512},
+			err:     serrors.ErrorMismatchChecksum,
+		},
+		{
+			name:    "no common algorithm",
+			path:    "dist/sha512",
+			digests: map[string]string{"sha256": abcSha256},
+			err:     serrors.ErrorMismatchChecksum,
+		},
+		{
+			name:    "not listed",
+			path:    "def",
+			digests: map[string]string{"sha256": abcSha256},
+			err:     serrors.ErrorMismatchChecksum,
+		},
+		{
+			name:    "base name of entries with directories",
+			path:    "dup",
+			digests: map[string]string{"sha256": abcSha256},
+			err:     serrors.ErrorMismatchChecksum,
+		},
+		{
+			name:    "entry with directory in another directory",
+			path:    "build/a/dup",
+			digests: map[string]string{"sha256": abcSha256},
+			err:     serrors.ErrorMismatchChecksum,
+		},
+		{
+			name:    "listed by path despite base name",
+			path:    "a/dup",
+			digests: map[string]string{"sha256": abcSha256},
+		},
+	}
+	for _, tt := range testCases {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := checksums.Verify(tt.path, tt.digests)
+			if !cmp.Equal(err, tt.err, cmpopts.EquateErrors()) {
+				t.Errorf(cmp.Diff(err, tt.err, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"strings"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	"golang.org/x/crypto/sha3"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+// digestAlgorithms maps the supported algorithm names, as used in in-toto
+// digest sets, to their hash constructor. Weak algorithms are not supported.
+var digestAlgorithms = map[string]func() hash.Hash{
+	"sha256":     sha256.New,
+	"sha384":     sha512.New384,
+	"sha512":     sha512.New,
+	"sha512_256": sha512.New512_256,
+	"sha3_256":   sha3.New256,
+	"sha3_384":   sha3.New384,
+	"sha3_512":   sha3.New512,
+}
+
+// DigestAlgorithms returns the sorted names of the supported digest algorithms.
+func DigestAlgorithms() []string {
+	names := make([]string, 0, len(digestAlgorithms))
+	for name := range digestAlgorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NormalizeDigestAlgorithm returns the in-toto digest set name for a
+// supported algorithm. Hyphens are accepted in place of underscores,
+// e.g. sha3-256 for sha3_256.
+func NormalizeDigestAlgorithm(name string) (string, error) {
+	algo := strings.ReplaceAll(strings.ToLower(name), "-", "_")
+	if _, ok := digestAlgorithms[algo]; !ok {
+		return "", fmt.Errorf("%w: unsupported digest algorithm %q, expected one of %v",
+			serrors.ErrorInvalidHash, name, DigestAlgorithms())
+	}
+	return algo, nil
+}
+
+// ExpectedDigests returns the expected digests set in the options. For a
+// single ExpectedDigest, the algorithm is inferred from the hash length.
+func ExpectedDigests(provenanceOpts *options.ProvenanceOpts) map[string]string {
+	if len(provenanceOpts.ExpectedDigests) > 0 {
+		return provenanceOpts.ExpectedDigests
+	}
+	// 8 bit represented in hex, so 8/2=4.
+	expectedAlgo := fmt.Sprintf("sha%v", len(provenanceOpts.ExpectedDigest)*4)
+	return map[string]string{expectedAlgo: provenanceOpts.ExpectedDigest}
+}
+
+// ComputeDigests hashes the content of r with each of the given algorithms
+// in a single pass. The result maps each algorithm to its hex-encoded digest.
+func ComputeDigests(r io.Reader, algos []string) (map[string]string, error) {
+	hashes := make(map[string]hash.Hash, len(algos))
+	writers := make([]io.Writer, 0, len(algos))
+	for _, name := range algos {
+		algo, err := NormalizeDigestAlgorithm(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := hashes[algo]; ok {
+			continue
+		}
+		h := digestAlgorithms[algo]()
+		hashes[algo] = h
+		writers = append(writers, h)
+	}
+	if len(writers) == 0 {
+		return nil, fmt.Errorf("%w: no digest algorithm", serrors.ErrorInvalidHash)
+	}
+
+	if _, err := io.Copy(io.MultiWriter(writers...), r); err != nil {
+		return nil, err
+	}
+
+	digests := make(map[string]string, len(hashes))
+	for algo, h := range hashes {
+		digests[algo] = hex.EncodeToString(h.Sum(nil))
+	}
+	return digests, nil
+}
+
+// MatchDigests reports whether a subject's digest set matches the expected
+// digests: at least one algorithm must be present in both, and every
+// algorithm present in both must match.
+func MatchDigests(digest, expected map[string]string) bool {
+	common := 0
+	for algo, hash := range expected {
+		value, ok := digest[algo]
+		if !ok {
+			continue
+		}
+		if value != hash {
+			return false
+		}
+		common++
+	}
+	return common > 0
+}
+
+// VerifySubjectDigests verifies that one of the subjects matches the
+// expected digests, as defined by MatchDigests.
+func VerifySubjectDigests(subjects []intoto.Subject, expected map[string]string) error {
+	if len(expected) == 0 {
+		return fmt.Errorf("%w: no expected digest", serrors.ErrorInvalidHash)
+	}
+	for algo := range expected {
+		if _, ok := digestAlgorithms[algo]; !ok {
+			return fmt.Errorf("%w: unsupported digest algorithm %q", serrors.ErrorInvalidHash, algo)
+		}
+	}
+
+	for _, subject := range subjects {
+		if MatchDigests(subject.Digest, expected) {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected digests '%v' not found: %w", expected, serrors.ErrorMismatchHash)
+}
@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+const (
+	abcSha256  = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	abcSha512  = "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"
+	abcSha3512 = "b751850b1a57168a5693cd924b6b096e08f621827444f70d884f5d0240d2712e10e116e9192af3c91a7ec57647e3934057340b4cf408d5a56592f8274eec53f0"
+	abcSha3256 = "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"
+)
+
+func Test_ComputeDigests(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		algos    []string
+		expected map[string]string
+		err      error
+	}{
+		{
+			name:     "sha256",
+			algos:    []string{"sha256"},
+			expected: map[string]string{"sha256": abcSha256},
+		},
+		{
+			name:  "multiple algorithms",
+			algos: []string{"sha256", "sha512", "sha3-256", "SHA3_512"},
+			expected: map[string]string{
+				"sha256":   abcSha256,
+				"sha512":   abcSha512,
+				"sha3_256": abcSha3256,
+				"sha3_512": abcSha3512,
+			},
+		},
+		{
+			name:     "duplicate algorithm",
+			algos:    []string{"sha512", "SHA512"},
+			expected: map[string]string{"sha512": abcSha512},
+		},
+		{
+			name:  "weak algorithm",
+			algos: []string{"sha256", "md5"},
+			err:   serrors.ErrorInvalidHash,
+		},
+		{
+			name: "no algorithm",
+			err:  serrors.ErrorInvalidHash,
+		},
+	}
+
+	for i := range testCases {
+		tt := testCases[i]
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			digests, err := ComputeDigests(strings.NewReader("abc"), tt.algos)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, digests); diff != "" {
+				t.Fatalf("unexpected digests (-want +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_VerifySubjectDigests(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		subjects []intoto.Subject
+		expected map[string]string
+		err      error
+	}{
+		{
+			name: "single common digest",
+			subjects: []intoto.Subject{
+				{Digest: map[string]string{"sha256": abcSha256}},
+			},
+			expected: map[string]string{"sha256": abcSha256, "sha512": abcSha512},
+		},
+		{
+			name: "all common digests match",
+			subjects: []intoto.Subject{
+				{Digest: map[string]string{"sha256": abcSha256, "sha512": abcSha512}},
+			},
+			expected: map[string]string{"sha256": abcSha256, "sha512": abcSha512},
+		},
+		{
+			name: "one common digest mismatch",
+			subjects: []intoto.Subject{
+				{Digest: map[string]string{"sha256": abcSha256, "sha512": abcSha3512}},
+			},
+			expected: map[string]string{"sha256": abcSha256, "sha512": abcSha512},
+			err:      serrors.ErrorMismatchHash,
+		},
+		{
+			name: "sha3-512 not confused with sha512",
+			subjects: []intoto.Subject{
+				{Digest: map[string]string{"sha512": abcSha3512}},
+			},
+			expected: map[string]string{"sha3_512": abcSha3512},
+			err:      serrors.ErrorMismatchHash,
+		},
+		{
+			name: "no common digest",
+			subjects: []intoto.Subject{
+				{Digest: map[string]string{"sha256": abcSha256}},
+			},
+			expected: map[string]string{"sha512": abcSha512},
+			err:      serrors.ErrorMismatchHash,
+		},
+		{
+			name: "second subject matches",
+			subjects: []intoto.Subject{
+				{Digest: map[string]string{"sha512": abcSha3512}},
+				{Digest: map[string]string{"sha512": abcSha512}},
+			},
+			expected: map[string]string{"sha512": abcSha512},
+		},
+		{
+			name: "unsupported algorithm",
+			subjects: []intoto.Subject{
+				{Digest: map[string]string{"md5": "900150983cd24fb0d6963f7d28e17f72"}},
+			},
+			expected: map[string]string{"md5": "900150983cd24fb0d6963f7d28e17f72"},
+			err:      serrors.ErrorInvalidHash,
+		},
+		{
+			name: "no expected digest",
+			subjects: []intoto.Subject{
+				{Digest: map[string]string{"sha256": abcSha256}},
+			},
+			err: serrors.ErrorInvalidHash,
+		},
+	}
+
+	for i := range testCases {
+		tt := testCases[i]
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := VerifySubjectDigests(tt.subjects, tt.expected)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -4,9 +4,15 @@ import (
 	"context"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
@@ -113,7 +119,6 @@ type publicKey struct {
 }
 
 func (p *publicKey) Verify(ctx context.Context, data, sig []byte) error {
-	digest := sha256.Sum256(data)
 	if p.pubKey == nil {
 		return fmt.Errorf("%w: key is empty", serrors.ErrorInternal)
 	}
@@ -121,28 +126,65 @@ func (p *publicKey) Verify(ctx context.Context, data, sig []byte) error {
 	default:
 		return fmt.Errorf("unknown key type: %T", v)
 	case *ecdsa.PublicKey:
+		digest := ecdsaDigest(v.Curve, data)
 		switch p.sigEncoding {
 		case SignatureEncodingDER:
-			if !ecdsa.VerifyASN1(v, digest[:], sig) {
+			if !ecdsa.VerifyASN1(v, digest, sig) {
 				return fmt.Errorf("%w: cannot verify signature",
 					serrors.ErrorInvalidSignature)
 			}
 		case SignatureEncodingIEEEP1363:
+			// r and s are padded to the size of the order of the curve.
+			size := (v.Curve.Params().N.BitLen() + 7) / 8
+			if len(sig) != 2*size {
+				return fmt.Errorf("%w: unexpected signature length %d",
+					serrors.ErrorInvalidSignature, len(sig))
+			}
 			r := new(big.Int)
-			r.SetBytes(sig[:32])
+			r.SetBytes(sig[:size])
 			s := new(big.Int)
-			s.SetBytes(sig[32:])
-			if !ecdsa.Verify(v, digest[:], r, s) {
+			s.SetBytes(sig[size:])
+			if !ecdsa.Verify(v, digest, r, s) {
 				return fmt.Errorf("%w: cannot verify signature",
 					serrors.ErrorInvalidSignature)
 			}
 		default:
 			return fmt.Errorf("unsupported encoding: %v", p.sigEncoding)
 		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(v, data, sig) {
+			return fmt.Errorf("%w: cannot verify signature",
+				serrors.ErrorInvalidSignature)
+		}
+	case *rsa.PublicKey:
+		// Only RSA-PSS signatures are accepted, as in the DSSE
+		// rsassa-pss-sha256 scheme.
+		digest := sha256.Sum256(data)
+		if err := rsa.VerifyPSS(v, crypto.SHA256, digest[:], sig,
+			&rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto}); err != nil {
+			return fmt.Errorf("%w: cannot verify signature: %v",
+				serrors.ErrorInvalidSignature, err)
+		}
 	}
 	return nil
 }
 
+// ecdsaDigest hashes the data with the hash function matching the size of
+// the curve, e.g. SHA-384 for P-384.
+func ecdsaDigest(curve elliptic.Curve, data []byte) []byte {
+	switch curve.Params().BitSize {
+	case 384:
+		digest := sha512.Sum384(data)
+		return digest[:]
+	case 521:
+		digest := sha512.Sum512(data)
+		return digest[:]
+	default:
+		digest := sha256.Sum256(data)
+		return digest[:]
+	}
+}
+
 // KeyID implements dsse.Verifier.KeyID.
 func (p *publicKey) KeyID() (string, error) {
 	return p.keyID, nil
@@ -161,6 +203,28 @@ const (
 )
 
 func DsseVerifierNew(content []byte, format KeyFormat, keyID string, sigEncoding *SignatureEncoding) (*dsselib.EnvelopeVerifier, error) {
+	pubKey, err := parsePublicKey(content, format)
+	if err != nil {
+		return nil, err
+	}
+
+	dssePubKey := publicKey{
+		pubKey: &pubKey,
+		keyID:  keyID,
+	}
+	if sigEncoding != nil {
+		dssePubKey.sigEncoding = *sigEncoding
+	}
+
+	verifier, err := dsselib.NewEnvelopeVerifier(&dssePubKey)
+	if err != nil {
+		return nil, fmt.Errorf("creating verifier: %w", err)
+	}
+
+	return verifier, nil
+}
+
+func parsePublicKey(content []byte, format KeyFormat) (crypto.PublicKey, error) {
 	if format == KeyFormatPEM {
 		block, rest := pem.Decode(content)
 		if len(rest) != 0 {
@@ -181,19 +245,166 @@ func DsseVerifierNew(content []byte, format KeyFormat, keyID string, sigEncoding
 	if !ok {
 		return nil, fmt.Errorf("%w: not a public key", serrors.ErrorInvalidPublicKey)
 	}
+	return pubKey, nil
+}
 
-	dssePubKey := publicKey{
-		pubKey: &pubKey,
-		keyID:  keyID,
+// PublicKeyID returns the ID of a PEM-encoded public key: the hex-encoded
+// SHA-256 digest of its DER-encoded SubjectPublicKeyInfo.
+func PublicKeyID(content []byte) (string, error) {
+	pubKey, err := parsePublicKey(content, KeyFormatPEM)
+	if err != nil {
+		return "", err
 	}
-	if sigEncoding != nil {
-		dssePubKey.sigEncoding = *sigEncoding
+	return publicKeyID(pubKey)
+}
+
+func publicKeyID(pubKey crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", serrors.ErrorInvalidPublicKey, err)
+	}
+	digest := sha256.Sum256(der)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// VerifyEnvelopeSignatures verifies that the envelope has valid signatures
+// from at least threshold distinct keys among the PEM-encoded public keys,
+// and returns the IDs of the keys that signed it, as in PublicKeyID.
+// A threshold of zero requires a single signature.
+// A signature whose keyid matches the ID of a trusted key is only verified
+// against that key. Other signatures are verified against all keys.
+func VerifyEnvelopeSignatures(ctx context.Context, env *dsselib.Envelope,
+	publicKeys [][]byte, threshold int,
+) ([]string, error) {
+	if len(publicKeys) == 0 {
+		return nil, fmt.Errorf("%w: no public key", serrors.ErrorInvalidPublicKey)
+	}
+	if threshold == 0 {
+		threshold = 1
+	}
+	if threshold < 0 || threshold > len(publicKeys) {
+		return nil, fmt.Errorf("%w: %d for %d public keys",
+			serrors.ErrorInvalidSignatureThreshold, threshold, len(publicKeys))
 	}
 
-	verifier, err := dsselib.NewEnvelopeVerifier(&dssePubKey)
+	keys := make([]*publicKey, 0, len(publicKeys))
+	for _, content := range publicKeys {
+		pubKey, err := parsePublicKey(content, KeyFormatPEM)
+		if err != nil {
+			return nil, err
+		}
+		keyID, err := publicKeyID(pubKey)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, &publicKey{pubKey: &pubKey, keyID: keyID})
+	}
+
+	payload, err := PayloadFromEnvelope(env)
 	if err != nil {
-		return nil, fmt.Errorf("creating verifier: %w", err)
+		return nil, err
 	}
+	pae := dsselib.PAE(env.PayloadType, payload)
 
-	return verifier, nil
+	verified := make(map[string]bool)
+	var keyIDs []string
+	for _, s := range env.Signatures {
+		sig, err := DecodeSignature(s.Sig)
+		if err != nil {
+			continue
+		}
+
+		candidates := keys
+		for _, key := range keys {
+			if s.KeyID != "" && s.KeyID == key.keyID {
+				candidates = []*publicKey{key}
+				break
+			}
+		}
+
+		for _, key := range candidates {
+			if verified[key.keyID] {
+				continue
+			}
+			if err := key.Verify(ctx, pae, sig); err != nil {
+				continue
+			}
+			verified[key.keyID] = true
+			keyIDs = append(keyIDs, key.keyID)
+			break
+		}
+	}
+
+	if len(keyIDs) < threshold {
+		return nil, fmt.Errorf("%w: %d of %d required signatures verified",
+			serrors.ErrorNoValidSignature, len(keyIDs), threshold)
+	}
+	return keyIDs, nil
+}
+
+type privateKey struct {
+	publicKey
+	signer crypto.Signer
+}
+
+// Sign implements dsse.Signer.Sign.
+func (p *privateKey) Sign(ctx context.Context, data []byte) ([]byte, error) {
+	switch v := p.signer.(type) {
+	case *ecdsa.PrivateKey:
+		return ecdsa.SignASN1(rand.Reader, v, ecdsaDigest(v.Curve, data))
+	case ed25519.PrivateKey:
+		return ed25519.Sign(v, data), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported key type: %T", serrors.ErrorInvalidPrivateKey, v)
+	}
+}
+
+// DsseSignerNew creates a DSSE signer from a PEM-encoded ECDSA or Ed25519
+// private key, in PKCS #8 or, for ECDSA, SEC 1 format.
+func DsseSignerNew(content []byte, keyID string) (*dsselib.EnvelopeSigner, error) {
+	block, rest := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("%w: unable to decode PEM format", serrors.ErrorInvalidPEM)
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%w: additional data found", serrors.ErrorInvalidPEM)
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidPrivateKey, err)
+	}
+
+	var signer crypto.Signer
+	switch v := key.(type) {
+	case *ecdsa.PrivateKey:
+		signer = v
+	case ed25519.PrivateKey:
+		signer = v
+	default:
+		return nil, fmt.Errorf("%w: unsupported key type: %T", serrors.ErrorInvalidPrivateKey, v)
+	}
+
+	pubKey := signer.Public()
+	dssePrivKey := privateKey{
+		publicKey: publicKey{
+			pubKey: &pubKey,
+			keyID:  keyID,
+		},
+		signer: signer,
+	}
+
+	envSigner, err := dsselib.NewEnvelopeSigner(&dssePrivKey)
+	if err != nil {
+		return nil, fmt.Errorf("creating signer: %w", err)
+	}
+
+	return envSigner, nil
 }
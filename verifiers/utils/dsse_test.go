@@ -1,10 +1,22 @@
 package utils
 
 import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
 
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
 )
@@ -53,3 +65,354 @@ func Test_DecodeSignature(t *testing.T) {
 		})
 	}
 }
+
+func Test_DsseSignerNew(t *testing.T) {
+	t.Parallel()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8 := func(key crypto.PrivateKey) []byte {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	}
+
+	tests := []struct {
+		name     string
+		key      []byte
+		pub      crypto.PublicKey
+		expected error
+	}{
+		{
+			name: "ecdsa sec1",
+			key:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}),
+			pub:  ecKey.Public(),
+		},
+		{
+			name: "ecdsa pkcs8",
+			key:  pkcs8(ecKey),
+			pub:  ecKey.Public(),
+		},
+		{
+			name: "ed25519 pkcs8",
+			key:  pkcs8(edKey),
+			pub:  edKey.Public(),
+		},
+		{
+			name:     "rsa pkcs8",
+			key:      pkcs8(rsaKey),
+			expected: serrors.ErrorInvalidPrivateKey,
+		},
+		{
+			name:     "malformed key",
+			key:      pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("not a key")}),
+			expected: serrors.ErrorInvalidPrivateKey,
+		},
+		{
+			name:     "not pem",
+			key:      []byte("not a key"),
+			expected: serrors.ErrorInvalidPEM,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			signer, err := DsseSignerNew(tt.key, "")
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Fatalf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+			if err != nil {
+				return
+			}
+
+			env, err := signer.SignPayload(context.Background(), "application/vnd.in-toto+json", []byte("{}"))
+			if err != nil {
+				t.Fatalf("SignPayload: %v", err)
+			}
+
+			der, err := x509.MarshalPKIXPublicKey(tt.pub)
+			if err != nil {
+				t.Fatal(err)
+			}
+			verifier, err := DsseVerifierNew(der, KeyFormatDER, "", nil)
+			if err != nil {
+				t.Fatalf("DsseVerifierNew: %v", err)
+			}
+			if _, err := verifier.Verify(context.Background(), env); err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+		})
+	}
+}
+
+func Test_VerifyEnvelopeSignatures(t *testing.T) {
+	t.Parallel()
+
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	untrustedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicPEM := func(key crypto.Signer) []byte {
+		der, err := x509.MarshalPKIXPublicKey(key.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	}
+	keyID := func(key crypto.Signer) string {
+		id, err := PublicKeyID(publicPEM(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+
+	payloadType := "application/vnd.in-toto+json"
+	payload := []byte("{}")
+	pae := dsselib.PAE(payloadType, payload)
+	sign := func(key crypto.Signer, keyID string) dsselib.Signature {
+		var sig []byte
+		var err error
+		switch k := key.(type) {
+		case *ecdsa.PrivateKey:
+			sig, err = ecdsa.SignASN1(rand.Reader, k, ecdsaDigest(k.Curve, pae))
+		case ed25519.PrivateKey:
+			sig = ed25519.Sign(k, pae)
+		case *rsa.PrivateKey:
+			digest := sha256.Sum256(pae)
+			sig, err = rsa.SignPSS(rand.Reader, k, crypto.SHA256, digest[:], nil)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return dsselib.Signature{KeyID: keyID, Sig: base64.StdEncoding.EncodeToString(sig)}
+	}
+
+	trusted := [][]byte{publicPEM(p256Key), publicPEM(p384Key), publicPEM(edKey), publicPEM(rsaKey)}
+
+	tests := []struct {
+		name       string
+		signatures []dsselib.Signature
+		keys       [][]byte
+		threshold  int
+		expected   error
+		keyIDs     []string
+	}{
+		{
+			name:       "ecdsa p256",
+			signatures: []dsselib.Signature{sign(p256Key, "")},
+			threshold:  1,
+			keyIDs:     []string{keyID(p256Key)},
+		},
+		{
+			name:       "ecdsa p384",
+			signatures: []dsselib.Signature{sign(p384Key, "")},
+			threshold:  1,
+			keyIDs:     []string{keyID(p384Key)},
+		},
+		{
+			name:       "ed25519",
+			signatures: []dsselib.Signature{sign(edKey, "")},
+			threshold:  1,
+			keyIDs:     []string{keyID(edKey)},
+		},
+		{
+			name:       "rsa-pss",
+			signatures: []dsselib.Signature{sign(rsaKey, "")},
+			threshold:  1,
+			keyIDs:     []string{keyID(rsaKey)},
+		},
+		{
+			name:       "matching keyid",
+			signatures: []dsselib.Signature{sign(edKey, keyID(edKey))},
+			threshold:  1,
+			keyIDs:     []string{keyID(edKey)},
+		},
+		{
+			name:       "unknown keyid",
+			signatures: []dsselib.Signature{sign(edKey, "vendor-key-1")},
+			threshold:  1,
+			keyIDs:     []string{keyID(edKey)},
+		},
+		{
+			name:       "keyid of another trusted key",
+			signatures: []dsselib.Signature{sign(edKey, keyID(p256Key))},
+			threshold:  1,
+			expected:   serrors.ErrorNoValidSignature,
+		},
+		{
+			name: "threshold met",
+			signatures: []dsselib.Signature{
+				sign(untrustedKey, ""), sign(rsaKey, keyID(rsaKey)), sign(p256Key, ""),
+			},
+			threshold: 2,
+			keyIDs:    []string{keyID(rsaKey), keyID(p256Key)},
+		},
+		{
+			name:       "threshold not met",
+			signatures: []dsselib.Signature{sign(untrustedKey, ""), sign(rsaKey, "")},
+			threshold:  2,
+			expected:   serrors.ErrorNoValidSignature,
+		},
+		{
+			name:       "same key counted once",
+			signatures: []dsselib.Signature{sign(p256Key, ""), sign(p256Key, "")},
+			threshold:  2,
+			expected:   serrors.ErrorNoValidSignature,
+		},
+		{
+			name:       "untrusted key",
+			signatures: []dsselib.Signature{sign(untrustedKey, "")},
+			threshold:  1,
+			expected:   serrors.ErrorNoValidSignature,
+		},
+		{
+			name:       "threshold above number of keys",
+			signatures: []dsselib.Signature{sign(p256Key, "")},
+			keys:       [][]byte{publicPEM(p256Key)},
+			threshold:  2,
+			expected:   serrors.ErrorInvalidSignatureThreshold,
+		},
+		{
+			name:       "zero threshold",
+			signatures: []dsselib.Signature{sign(p256Key, "")},
+			threshold:  0,
+			keyIDs:     []string{keyID(p256Key)},
+		},
+		{
+			name:       "negative threshold",
+			signatures: []dsselib.Signature{sign(p256Key, "")},
+			threshold:  -1,
+			expected:   serrors.ErrorInvalidSignatureThreshold,
+		},
+		{
+			name:       "no key",
+			signatures: []dsselib.Signature{sign(p256Key, "")},
+			keys:       [][]byte{},
+			threshold:  1,
+			expected:   serrors.ErrorInvalidPublicKey,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			keys := tt.keys
+			if keys == nil {
+				keys = trusted
+			}
+			env := &dsselib.Envelope{
+				PayloadType: payloadType,
+				Payload:     base64.StdEncoding.EncodeToString(payload),
+				Signatures:  tt.signatures,
+			}
+
+			keyIDs, err := VerifyEnvelopeSignatures(context.Background(), env, keys, tt.threshold)
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Fatalf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+			if diff := cmp.Diff(tt.keyIDs, keyIDs); diff != "" {
+				t.Errorf("unexpected key IDs (-want +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_publicKey_VerifyIEEEP1363(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("data")
+	tests := []struct {
+		name     string
+		curve    elliptic.Curve
+		truncate bool
+		expected error
+	}{
+		{
+			name:  "p256",
+			curve: elliptic.P256(),
+		},
+		{
+			name:  "p384",
+			curve: elliptic.P384(),
+		},
+		{
+			name:  "p521",
+			curve: elliptic.P521(),
+		},
+		{
+			name:     "wrong length",
+			curve:    elliptic.P384(),
+			truncate: true,
+			expected: serrors.ErrorInvalidSignature,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			key, err := ecdsa.GenerateKey(tt.curve, rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, s, err := ecdsa.Sign(rand.Reader, key, ecdsaDigest(tt.curve, data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			size := (tt.curve.Params().N.BitLen() + 7) / 8
+			sig := make([]byte, 2*size)
+			r.FillBytes(sig[:size])
+			s.FillBytes(sig[size:])
+			if tt.truncate {
+				sig = sig[:64]
+			}
+
+			var pubKey crypto.PublicKey = &key.PublicKey
+			p := &publicKey{pubKey: &pubKey, sigEncoding: SignatureEncodingIEEEP1363}
+			err = p.Verify(context.Background(), data, sig)
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Errorf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
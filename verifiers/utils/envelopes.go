@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/logging"
+)
+
+// SignedEnvelopes are DSSE envelopes signed with static public keys, one of
+// which is verified by VerifySignature.
+type SignedEnvelopes struct {
+	envelopes       []*dsselib.Envelope
+	verifiedPayload []byte
+}
+
+// SignedEnvelopesFromBytes parses the DSSE envelopes in the payload, one per
+// line as downloaded with `cosign download attestation`.
+func SignedEnvelopesFromBytes(payload []byte) (*SignedEnvelopes, error) {
+	var envelopes []*dsselib.Envelope
+	for _, line := range bytes.Split(payload, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		env, err := EnvelopeFromBytes(line)
+		if err != nil {
+			return nil, err
+		}
+		envelopes = append(envelopes, env)
+	}
+	if len(envelopes) == 0 {
+		return nil, fmt.Errorf("%w: no envelope found", serrors.ErrorInvalidDssePayload)
+	}
+
+	return &SignedEnvelopes{
+		envelopes: envelopes,
+	}, nil
+}
+
+// IsVerified returns an error if no envelope was verified.
+func (e *SignedEnvelopes) IsVerified() error {
+	// Check that the signature is verified.
+	if e.verifiedPayload == nil {
+		return serrors.ErrorNoValidSignature
+	}
+	return nil
+}
+
+// GetVerifiedIntotoStatement returns the payload of the verified envelope.
+func (e *SignedEnvelopes) GetVerifiedIntotoStatement() ([]byte, error) {
+	if err := e.IsVerified(); err != nil {
+		return nil, err
+	}
+	return e.verifiedPayload, nil
+}
+
+// VerifySignature verifies that one of the envelopes is signed by at least
+// threshold of the PEM-encoded public keys, and that parse accepts its
+// payload. Envelopes whose payload parse rejects are skipped.
+func (e *SignedEnvelopes) VerifySignature(ctx context.Context, publicKeys [][]byte, threshold int,
+	parse func(payload []byte) error,
+) error {
+	var errs []error
+	for _, env := range e.envelopes {
+		keyIDs, err := VerifyEnvelopeSignatures(ctx, env, publicKeys, threshold)
+		if err != nil {
+			if !errors.Is(err, serrors.ErrorNoValidSignature) {
+				// Invalid keys or threshold.
+				return err
+			}
+			errs = append(errs, err)
+			continue
+		}
+
+		payload, err := PayloadFromEnvelope(env)
+		if err != nil {
+			return err
+		}
+		if err := parse(payload); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		e.verifiedPayload = payload
+		logging.FromContext(ctx).Info(fmt.Sprintf("Verified signature with public keys %s", strings.Join(keyIDs, ", ")),
+			"keyIDs", keyIDs)
+		return nil
+	}
+
+	return fmt.Errorf("%w: %v", serrors.ErrorNoValidSignature, errs)
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+func Test_SignedEnvelopes(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := DsseSignerNew(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err = x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeys := [][]byte{pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})}
+
+	sign := func(payload string) []byte {
+		env, err := signer.SignPayload(context.Background(), intoto.PayloadType, []byte(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := json.Marshal(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return content
+	}
+	errRejected := errors.New("rejected")
+	parse := func(payload []byte) error {
+		if string(payload) != "accepted" {
+			return errRejected
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name      string
+		content   []byte
+		parseErr  error
+		verifyErr error
+	}{
+		{
+			name:    "single envelope",
+			content: sign("accepted"),
+		},
+		{
+			name:    "envelope rejected by parse skipped",
+			content: append(append(sign("rejected"), '\n'), sign("accepted")...),
+		},
+		{
+			name:      "all envelopes rejected by parse",
+			content:   sign("rejected"),
+			verifyErr: serrors.ErrorNoValidSignature,
+		},
+		{
+			name:     "no envelope",
+			content:  []byte("\n\n"),
+			parseErr: serrors.ErrorInvalidDssePayload,
+		},
+		{
+			name:     "invalid envelope",
+			content:  []byte("{"),
+			parseErr: serrors.ErrorInvalidDssePayload,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			envelopes, err := SignedEnvelopesFromBytes(tt.content)
+			if !cmp.Equal(err, tt.parseErr, cmpopts.EquateErrors()) {
+				t.Fatalf(cmp.Diff(err, tt.parseErr, cmpopts.EquateErrors()))
+			}
+			if err != nil {
+				return
+			}
+			if _, err := envelopes.GetVerifiedIntotoStatement(); !errors.Is(err, serrors.ErrorNoValidSignature) {
+				t.Errorf("unexpected error before verification: %v", err)
+			}
+
+			err = envelopes.VerifySignature(context.Background(), publicKeys, 1, parse)
+			if !cmp.Equal(err, tt.verifyErr, cmpopts.EquateErrors()) {
+				t.Fatalf(cmp.Diff(err, tt.verifyErr, cmpopts.EquateErrors()))
+			}
+			if err != nil {
+				return
+			}
+			payload, err := envelopes.GetVerifiedIntotoStatement()
+			if err != nil {
+				t.Fatalf("GetVerifiedIntotoStatement: %v", err)
+			}
+			if string(payload) != "accepted" {
+				t.Errorf("unexpected verified payload %q", payload)
+			}
+		})
+	}
+}
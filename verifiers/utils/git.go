@@ -7,6 +7,10 @@ import (
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
 )
 
+// MinAbbreviatedCommitLength is the minimum number of hex characters accepted
+// for an abbreviated commit SHA. Shorter prefixes are cheap to collide.
+const MinAbbreviatedCommitLength = 12
+
 // NormalizeGitURI normalizes a git URI to include a git+https:// prefix.
 func NormalizeGitURI(s string) string {
 	if !strings.HasPrefix(s, "git+") {
@@ -66,3 +70,28 @@ func TagFromGitRef(ref string) (string, error) {
 func BranchFromGitRef(ref string) (string, error) {
 	return ValidateGitRef("heads", ref)
 }
+
+// VerifySourceCommit verifies that the commit matches the expected commit.
+// The expected commit may be abbreviated, in which case it must contain at
+// least MinAbbreviatedCommitLength characters.
+func VerifySourceCommit(commit, expectedCommit string) error {
+	expected := strings.ToLower(expectedCommit)
+	if len(expected) < MinAbbreviatedCommitLength {
+		return fmt.Errorf("%w: commit '%s' is shorter than %d characters",
+			serrors.ErrorInvalidFormat, expectedCommit, MinAbbreviatedCommitLength)
+	}
+	// Abbreviated commits may have an odd length, so we only check
+	// the characters rather than decoding the string.
+	if strings.Trim(expected, "0123456789abcdef") != "" {
+		return fmt.Errorf("%w: commit '%s' is not hex-encoded", serrors.ErrorInvalidFormat, expectedCommit)
+	}
+
+	if commit == "" {
+		return fmt.Errorf("%w: empty commit", serrors.ErrorMismatchSourceCommit)
+	}
+	if !strings.HasPrefix(strings.ToLower(commit), expected) {
+		return fmt.Errorf("%w: expected commit '%s', got '%s'",
+			serrors.ErrorMismatchSourceCommit, expectedCommit, commit)
+	}
+	return nil
+}
@@ -267,3 +267,83 @@ func Test_ValidateGitRef(t *testing.T) {
 		})
 	}
 }
+
+func Test_VerifySourceCommit(t *testing.T) {
+	t.Parallel()
+
+	commit := "2ce3f90facdb51aeb950d5bc641e981be61fdf48"
+	testCases := []struct {
+		name     string
+		commit   string
+		expected string
+		err      error
+	}{
+		{
+			name:     "full commit match",
+			commit:   commit,
+			expected: commit,
+		},
+		{
+			name:     "abbreviated commit match",
+			commit:   commit,
+			expected: "2ce3f90facdb",
+		},
+		{
+			name:     "odd-length abbreviated commit match",
+			commit:   commit,
+			expected: "2ce3f90facdb5",
+		},
+		{
+			name:     "uppercase commit match",
+			commit:   commit,
+			expected: "2CE3F90FACDB51AEB950D5BC641E981BE61FDF48",
+		},
+		{
+			name:     "commit mismatch",
+			commit:   commit,
+			expected: "3ce3f90facdb51aeb950d5bc641e981be61fdf48",
+			err:      serrors.ErrorMismatchSourceCommit,
+		},
+		{
+			name:     "abbreviated commit mismatch",
+			commit:   commit,
+			expected: "2ce3f90facdc",
+			err:      serrors.ErrorMismatchSourceCommit,
+		},
+		{
+			name:     "expected longer than commit",
+			commit:   "2ce3f90facdb51",
+			expected: commit,
+			err:      serrors.ErrorMismatchSourceCommit,
+		},
+		{
+			name:     "empty commit",
+			expected: commit,
+			err:      serrors.ErrorMismatchSourceCommit,
+		},
+		{
+			name:     "abbreviated commit too short",
+			commit:   commit,
+			expected: "2ce3f90",
+			err:      serrors.ErrorInvalidFormat,
+		},
+		{
+			name:     "non-hex commit",
+			commit:   commit,
+			expected: "2ce3f90facdbxyz",
+			err:      serrors.ErrorInvalidFormat,
+		},
+	}
+
+	for i := range testCases {
+		tt := testCases[i]
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := VerifySourceCommit(tt.commit, tt.expected)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Hosted is the runner environment of a GitHub Actions workflow.
+type Hosted int
+
+const (
+	HostedSelf Hosted = iota
+	HostedGitHub
+)
+
+// String returns the runner environment as recorded in Fulcio certificates.
+func (h Hosted) String() string {
+	switch h {
+	case HostedSelf:
+		return "self-hosted"
+	case HostedGitHub:
+		return "github-hosted"
+	default:
+		return fmt.Sprintf("Hosted(%d)", int(h))
+	}
+}
+
+// WorkflowIdentity is a identity captured from a Fulcio certificate.
+// See https://github.com/sigstore/fulcio/blob/main/docs/oid-info.md.
+type WorkflowIdentity struct {
+	// The source repository
+	SourceRepository string
+	// The commit SHA where the workflow was triggered.
+	SourceSha1 string
+	// Ref of the source.
+	SourceRef *string
+	// ID of the source repository.
+	SourceID *string
+	//  Source owner ID of repository.
+	SourceOwnerID *string
+
+	// Workflow path OIDC subject - ref of reuseable workflow or trigger workflow.
+	SubjectWorkflow *url.URL
+	// Subject commit sha1.
+	SubjectSha1 *string
+	// Hosted status of the subject.
+	SubjectHosted *Hosted
+
+	// BuildTrigger
+	BuildTrigger string
+	// Build config path, i.e. the trigger workflow.
+	BuildConfigPath *string
+
+	// Run ID
+	RunID *string
+	// Issuer
+	Issuer string
+}
+
+// SubjectWorkflowName returns the subject workflow without the git ref.
+func (id *WorkflowIdentity) SubjectWorkflowName() string {
+	// NOTE: You should be able to copy a net.URL struct safely.
+	// See: https://github.com/golang/go/issues/38351
+	withoutRef := *id.SubjectWorkflow
+	withoutRef.Path = id.SubjectWorkflowPath()
+	return withoutRef.String()
+}
+
+// SubjectWorkflowPath returns the subject workflow without the server url.
+func (id *WorkflowIdentity) SubjectWorkflowPath() string {
+	i := strings.LastIndex(id.SubjectWorkflow.Path, "@")
+	if i == -1 {
+		return id.SubjectWorkflow.Path
+	}
+	return id.SubjectWorkflow.Path[:i]
+}
+
+// SubjectWorkflowRef returns the ref for the subject workflow.
+func (id *WorkflowIdentity) SubjectWorkflowRef() string {
+	i := strings.LastIndex(id.SubjectWorkflow.Path, "@")
+	if i == -1 {
+		return ""
+	}
+	return id.SubjectWorkflow.Path[i+1:]
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/slices"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+// ProvenanceOption is a provenance expectation that only some builders
+// record, and so only some verifiers check.
+type ProvenanceOption string
+
+const (
+	// OptionWorkflowInputs is ProvenanceOpts.ExpectedWorkflowInputs.
+	OptionWorkflowInputs ProvenanceOption = "workflow inputs"
+	// OptionBuildTriggers is ProvenanceOpts.ExpectedBuildTriggers.
+	OptionBuildTriggers ProvenanceOption = "build trigger events"
+	// OptionHostedRunner is ProvenanceOpts.RequireHostedRunner.
+	OptionHostedRunner ProvenanceOption = "hosted runner requirement"
+	// OptionBuildSubstitutions is ProvenanceOpts.ExpectedBuildSubstitutions.
+	OptionBuildSubstitutions ProvenanceOption = "build substitutions"
+	// OptionGCBTrigger is ProvenanceOpts.ExpectedGCBTrigger.
+	OptionGCBTrigger ProvenanceOption = "Google Cloud Build trigger"
+)
+
+// RejectUnsupportedOptions returns ErrorNotSupported if opts sets an
+// expectation that is not in supported. Verifiers fail rather than silently
+// ignore the expectations they cannot check.
+func RejectUnsupportedOptions(opts *options.ProvenanceOpts, supported ...ProvenanceOption) error {
+	set := []struct {
+		option ProvenanceOption
+		isSet  bool
+	}{
+		{OptionWorkflowInputs, len(opts.ExpectedWorkflowInputs) > 0},
+		{OptionBuildTriggers, len(opts.ExpectedBuildTriggers) > 0},
+		{OptionHostedRunner, opts.RequireHostedRunner},
+		{OptionBuildSubstitutions, len(opts.ExpectedBuildSubstitutions) > 0},
+		{OptionGCBTrigger, opts.ExpectedGCBTrigger != nil},
+	}
+	for _, s := range set {
+		if s.isSet && !slices.Contains(supported, s.option) {
+			return fmt.Errorf("%w: %s", serrors.ErrorNotSupported, s.option)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+func Test_RejectUnsupportedOptions(t *testing.T) {
+	t.Parallel()
+
+	trigger := "release"
+	tests := []struct {
+		name      string
+		opts      options.ProvenanceOpts
+		supported []ProvenanceOption
+		expected  error
+	}{
+		{
+			name: "no option",
+		},
+		{
+			name: "unrelated options",
+			opts: options.ProvenanceOpts{
+				ExpectedSourceURI: "github.com/org/repo",
+				ExpectedDigest:    "abcd",
+			},
+		},
+		{
+			name: "workflow inputs",
+			opts: options.ProvenanceOpts{
+				ExpectedWorkflowInputs: map[string]string{"key": "value"},
+			},
+			expected: serrors.ErrorNotSupported,
+		},
+		{
+			name: "build triggers",
+			opts: options.ProvenanceOpts{
+				ExpectedBuildTriggers: []string{"push"},
+			},
+			expected: serrors.ErrorNotSupported,
+		},
+		{
+			name: "hosted runner",
+			opts: options.ProvenanceOpts{
+				RequireHostedRunner: true,
+			},
+			expected: serrors.ErrorNotSupported,
+		},
+		{
+			name: "build substitutions",
+			opts: options.ProvenanceOpts{
+				ExpectedBuildSubstitutions: map[string]string{"_KEY": "value"},
+			},
+			expected: serrors.ErrorNotSupported,
+		},
+		{
+			name: "gcb trigger",
+			opts: options.ProvenanceOpts{
+				ExpectedGCBTrigger: &trigger,
+			},
+			expected: serrors.ErrorNotSupported,
+		},
+		{
+			name: "supported options",
+			opts: options.ProvenanceOpts{
+				ExpectedWorkflowInputs: map[string]string{"key": "value"},
+				RequireHostedRunner:    true,
+			},
+			supported: []ProvenanceOption{OptionWorkflowInputs, OptionHostedRunner},
+		},
+		{
+			name: "one unsupported option",
+			opts: options.ProvenanceOpts{
+				ExpectedWorkflowInputs: map[string]string{"key": "value"},
+				RequireHostedRunner:    true,
+			},
+			supported: []ProvenanceOption{OptionWorkflowInputs},
+			expected:  serrors.ErrorNotSupported,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := RejectUnsupportedOptions(&tt.opts, tt.supported...)
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Errorf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// ProvenanceLine is an attestation of a provenance file.
+type ProvenanceLine struct {
+	// Number is the line of the attestation in the file, from 1.
+	Number  int
+	Content []byte
+}
+
+// ProvenanceLines returns the attestations of the provenance: the whole
+// content if it is a single JSON document, e.g. an indented envelope or
+// bundle, and otherwise each non-empty line, as in `.intoto.jsonl` files
+// with one envelope or bundle per line.
+func ProvenanceLines(provenance []byte) []ProvenanceLine {
+	if json.Valid(provenance) {
+		return []ProvenanceLine{{Number: 1, Content: provenance}}
+	}
+	var lines []ProvenanceLine
+	for i, line := range bytes.Split(provenance, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		lines = append(lines, ProvenanceLine{Number: i + 1, Content: line})
+	}
+	if len(lines) == 0 {
+		return []ProvenanceLine{{Number: 1, Content: provenance}}
+	}
+	return lines
+}
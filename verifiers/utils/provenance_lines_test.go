@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_ProvenanceLines(t *testing.T) {
+	t.Parallel()
+
+	envelope := `{"payloadType":"application/vnd.in-toto+json","payload":"e30=","signatures":[]}`
+	tests := []struct {
+		name       string
+		provenance string
+		lines      []ProvenanceLine
+	}{
+		{
+			name:       "single envelope",
+			provenance: envelope,
+			lines:      []ProvenanceLine{{Number: 1, Content: []byte(envelope)}},
+		},
+		{
+			name:       "single envelope with trailing newline",
+			provenance: envelope + "\n",
+			lines:      []ProvenanceLine{{Number: 1, Content: []byte(envelope + "\n")}},
+		},
+		{
+			name:       "indented envelope",
+			provenance: "{\n  \"payloadType\": \"application/vnd.in-toto+json\"\n}\n",
+			lines: []ProvenanceLine{
+				{Number: 1, Content: []byte("{\n  \"payloadType\": \"application/vnd.in-toto+json\"\n}\n")},
+			},
+		},
+		{
+			name:       "envelope per line",
+			provenance: envelope + "\n" + envelope + "\n",
+			lines: []ProvenanceLine{
+				{Number: 1, Content: []byte(envelope)},
+				{Number: 2, Content: []byte(envelope)},
+			},
+		},
+		{
+			name:       "blank lines",
+			provenance: "\n" + envelope + "\n  \n" + envelope,
+			lines: []ProvenanceLine{
+				{Number: 2, Content: []byte(envelope)},
+				{Number: 4, Content: []byte(envelope)},
+			},
+		},
+		{
+			name:       "malformed line",
+			provenance: envelope + "\nnot json\n",
+			lines: []ProvenanceLine{
+				{Number: 1, Content: []byte(envelope)},
+				{Number: 2, Content: []byte("not json")},
+			},
+		},
+		{
+			name:       "empty",
+			provenance: "",
+			lines:      []ProvenanceLine{{Number: 1, Content: []byte("")}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			lines := ProvenanceLines([]byte(tt.provenance))
+			if !cmp.Equal(lines, tt.lines) {
+				t.Errorf(cmp.Diff(lines, tt.lines))
+			}
+		})
+	}
+}
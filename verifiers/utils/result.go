@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+
+	"github.com/slsa-framework/slsa-verifier/v2/provenance"
+)
+
+// Check is a check performed during verification.
+type Check string
+
+const (
+	// CheckSignature is the verification of the signature on the provenance.
+	CheckSignature Check = "signature"
+	// CheckTransparencyLog is the verification of the transparency log entry
+	// for the signature.
+	CheckTransparencyLog Check = "transparency-log"
+	// CheckBuilderID is the verification of the builder ID and, for some
+	// builders, of the build type.
+	CheckBuilderID Check = "builder-id"
+	// CheckSubjectDigest is the verification of the subject digests.
+	CheckSubjectDigest Check = "subject-digest"
+	// CheckSubjectName is the verification of the subject name.
+	CheckSubjectName Check = "subject-name"
+	// CheckSourceURI is the verification of the source repository.
+	CheckSourceURI Check = "source-uri"
+	// CheckSourceCommit is the verification of the source commit.
+	CheckSourceCommit Check = "source-commit"
+	// CheckSourceCheckout is the verification against a local source checkout.
+	CheckSourceCheckout Check = "source-checkout"
+	// CheckBranch is the verification of the source branch.
+	CheckBranch Check = "branch"
+	// CheckTag is the verification of the source tag.
+	CheckTag Check = "tag"
+	// CheckVersionedTag is the verification of the source tag using semantic
+	// versioning.
+	CheckVersionedTag Check = "versioned-tag"
+	// CheckWorkflowInputs is the verification of the workflow inputs.
+	CheckWorkflowInputs Check = "workflow-inputs"
+	// CheckBuildTrigger is the verification of the event that triggered the
+	// build.
+	CheckBuildTrigger Check = "build-trigger"
+	// CheckBuildSubstitutions is the verification of the Google Cloud Build
+	// substitutions.
+	CheckBuildSubstitutions Check = "build-substitutions"
+	// CheckRunnerEnvironment is the verification that the build ran on a
+	// hosted runner.
+	CheckRunnerEnvironment Check = "runner-environment"
+	// CheckProvenanceConsistency is the verification that unsigned copies of
+	// the provenance, such as the GCB summary, match the signed provenance.
+	CheckProvenanceConsistency Check = "provenance-consistency"
+	// CheckPublishAttestation is the verification of the npm publish
+	// attestation.
+	CheckPublishAttestation Check = "publish-attestation"
+	// CheckPackageName is the verification of the npm package name.
+	CheckPackageName Check = "package-name"
+	// CheckPackageVersion is the verification of the npm package version.
+	CheckPackageVersion Check = "package-version"
+)
+
+// RekorEntry is a verified Rekor transparency log entry.
+type RekorEntry struct {
+	// LeafHash is the hex-encoded RFC 6962 leaf hash of the entry. It is
+	// not the Rekor UUID of the entry, which is prefixed by the ID of the
+	// log tree on sharded logs: the tree ID is not part of the entry.
+	LeafHash string
+	// LogIndex is the index of the entry in the log.
+	LogIndex int64
+	// IntegratedTime is the time the entry was added to the log.
+	IntegratedTime time.Time
+}
+
+// RekorEntryNew returns the RekorEntry for a log entry, whose body is either
+// base64-encoded, as returned by the Rekor API, or the canonicalized body.
+func RekorEntryNew(e *models.LogEntryAnon) *RekorEntry {
+	if e == nil {
+		return nil
+	}
+
+	r := &RekorEntry{}
+	if e.LogIndex != nil {
+		r.LogIndex = *e.LogIndex
+	}
+	if e.IntegratedTime != nil {
+		r.IntegratedTime = time.Unix(*e.IntegratedTime, 0).UTC()
+	}
+
+	var body []byte
+	switch b := e.Body.(type) {
+	case string:
+		body, _ = base64.StdEncoding.DecodeString(b)
+	case []byte:
+		body = b
+	}
+	if len(body) > 0 {
+		// RFC 6962 leaf hash.
+		leafHash := sha256.Sum256(append([]byte{0}, body...))
+		r.LeafHash = hex.EncodeToString(leafHash[:])
+	}
+	return r
+}
+
+// VerificationResult is the result of a successful verification.
+type VerificationResult struct {
+	// Statement is the verified in-toto statement.
+	Statement []byte
+
+	// Provenance is the verified provenance. It may be nil if the verifier
+	// does not return results and the statement is not SLSA provenance.
+	Provenance *provenance.Provenance
+
+	// BuilderID is the verified builder ID.
+	BuilderID *TrustedBuilderID
+
+	// Certificate is the signing certificate, for keyless signatures.
+	Certificate *x509.Certificate
+
+	// WorkflowIdentity is the identity in the signing certificate, for
+	// GitHub Actions builders.
+	WorkflowIdentity *WorkflowIdentity
+
+	// RekorEntry is the transparency log entry of the signature, if any.
+	RekorEntry *RekorEntry
+
+	// Timestamps are the times of the verified RFC 3161 timestamps of the
+	// signature, if any.
+	Timestamps []time.Time
+
+	// Bundle is the Sigstore bundle the provenance was read from, if any.
+	Bundle []byte
+
+	// ProvenanceLine is the line of the verified attestation, for provenance
+	// files with one attestation per line. It is 0 otherwise.
+	ProvenanceLine int
+
+	// Checks are the checks performed, in order.
+	Checks []Check
+}
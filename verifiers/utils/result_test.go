@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sigstore/rekor/pkg/generated/models"
+)
+
+func Test_RekorEntryNew(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"apiVersion":"0.0.1","kind":"intoto","spec":{}}`)
+	leafHash := sha256.Sum256(append([]byte{0}, body...))
+	hash := hex.EncodeToString(leafHash[:])
+	logIndex := int64(25579)
+	integratedTime := int64(1690000000)
+
+	tests := []struct {
+		name     string
+		entry    *models.LogEntryAnon
+		expected *RekorEntry
+	}{
+		{
+			name: "base64 body",
+			entry: &models.LogEntryAnon{
+				Body:           base64.StdEncoding.EncodeToString(body),
+				LogIndex:       &logIndex,
+				IntegratedTime: &integratedTime,
+			},
+			expected: &RekorEntry{
+				LeafHash:       hash,
+				LogIndex:       logIndex,
+				IntegratedTime: time.Unix(integratedTime, 0).UTC(),
+			},
+		},
+		{
+			name: "canonicalized body",
+			entry: &models.LogEntryAnon{
+				Body:           body,
+				LogIndex:       &logIndex,
+				IntegratedTime: &integratedTime,
+			},
+			expected: &RekorEntry{
+				LeafHash:       hash,
+				LogIndex:       logIndex,
+				IntegratedTime: time.Unix(integratedTime, 0).UTC(),
+			},
+		},
+		{
+			name: "no body",
+			entry: &models.LogEntryAnon{
+				LogIndex: &logIndex,
+			},
+			expected: &RekorEntry{
+				LogIndex: logIndex,
+			},
+		},
+		{
+			name: "no entry",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, RekorEntryNew(tt.entry)); diff != "" {
+				t.Errorf("unexpected entry (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// VerifySubjectName verifies that a subject matching the expected digests,
+// as defined by MatchDigests, is named expectedName. The expected name may be
+// a glob pattern, as accepted by path.Match.
+func VerifySubjectName(subjects []intoto.Subject, expected map[string]string, expectedName string) error {
+	if _, err := path.Match(expectedName, ""); err != nil {
+		return fmt.Errorf("%w: subject name pattern %q: %v", serrors.ErrorInvalidFormat, expectedName, err)
+	}
+
+	var names []string
+	for _, subject := range subjects {
+		if !MatchDigests(subject.Digest, expected) {
+			continue
+		}
+		// The pattern was validated above, so errors cannot occur.
+		if ok, _ := path.Match(expectedName, subject.Name); ok {
+			return nil
+		}
+		names = append(names, subject.Name)
+	}
+
+	if len(names) == 0 {
+		return fmt.Errorf("expected digests '%v' not found: %w", expected, serrors.ErrorMismatchHash)
+	}
+	return fmt.Errorf("%w: expected name '%s', got %q", serrors.ErrorMismatchSubjectName, expectedName, names)
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+func Test_VerifySubjectName(t *testing.T) {
+	t.Parallel()
+
+	hash := "2ce3f90facdb51aeb950d5bc641e981be61fdf482ce3f90facdb51aeb950d5bc"
+	subjects := []intoto.Subject{
+		{
+			Name:   "tool-linux-amd64",
+			Digest: map[string]string{"sha256": hash},
+		},
+		{
+			Name:   "tool-darwin-amd64",
+			Digest: map[string]string{"sha256": "0ce3f90facdb51aeb950d5bc641e981be61fdf482ce3f90facdb51aeb950d5bc"},
+		},
+		{
+			Name:   "tool-linux-amd64.tar.gz",
+			Digest: map[string]string{"sha256": hash},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		hash     string
+		expected string
+		err      error
+	}{
+		{
+			name:     "exact name",
+			hash:     hash,
+			expected: "tool-linux-amd64",
+		},
+		{
+			name:     "second subject with same digest",
+			hash:     hash,
+			expected: "tool-linux-amd64.tar.gz",
+		},
+		{
+			name:     "glob",
+			hash:     hash,
+			expected: "tool-linux-*",
+		},
+		{
+			name:     "renamed artifact",
+			hash:     hash,
+			expected: "tool-windows.exe",
+			err:      serrors.ErrorMismatchSubjectName,
+		},
+		{
+			name:     "name of another subject",
+			hash:     hash,
+			expected: "tool-darwin-amd64",
+			err:      serrors.ErrorMismatchSubjectName,
+		},
+		{
+			name:     "digest not present",
+			hash:     "1ce3f90facdb51aeb950d5bc641e981be61fdf482ce3f90facdb51aeb950d5bc",
+			expected: "tool-linux-amd64",
+			err:      serrors.ErrorMismatchHash,
+		},
+		{
+			name:     "invalid pattern",
+			hash:     hash,
+			expected: "tool-[linux",
+			err:      serrors.ErrorInvalidFormat,
+		},
+	}
+
+	for i := range testCases {
+		tt := testCases[i]
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := VerifySubjectName(subjects, map[string]string{"sha256": tt.hash}, tt.expected)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -2,19 +2,297 @@ package verifiers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"time"
+
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/sigstore/rekor/pkg/generated/client"
 
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/logging"
 	"github.com/slsa-framework/slsa-verifier/v2/options"
+	sprovenance "github.com/slsa-framework/slsa-verifier/v2/provenance"
 	"github.com/slsa-framework/slsa-verifier/v2/register"
-	_ "github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb/keys"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/statickey"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/tekton"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/vsa"
+)
+
+// TrustedRoot is the Sigstore trust material used to verify keyless
+// signatures.
+type TrustedRoot = gha.TrustedRoot
+
+// TrustedRootCache caches a trusted root and refreshes it in the
+// background.
+type TrustedRootCache = gha.TrustedRootCache
+
+// DefaultTrustedRootTTL is how long the public-good trusted root is used
+// before it is refreshed.
+const DefaultTrustedRootTTL = gha.DefaultTrustedRootTTL
+
+// TrustedRootCacheNew returns a cache of the trusted root returned by fetch,
+// or of the public-good Sigstore trusted root if fetch is nil. A TTL of 0
+// uses DefaultTrustedRootTTL. If set, onChange is called with the new root
+// when a refresh changes the trusted root.
+func TrustedRootCacheNew(fetch func(context.Context) (*TrustedRoot, error),
+	ttl time.Duration, onChange func(*TrustedRoot),
+) *TrustedRootCache {
+	return gha.TrustedRootCacheNew(fetch, ttl, onChange)
+}
+
+// Verifier verifies provenance with its own trust configuration, so that
+// one process can verify artifacts against several Sigstore instances or
+// trusted builder lists. It is safe for concurrent use.
+type Verifier struct {
+	// overrides are the verifiers configured for this instance, by name.
+	// Other verifiers are looked up in register.SLSAVerifiers.
+	overrides map[string]register.SLSAVerifier
+	gha       *gha.GHAVerifier
+	logger    logging.Logger
+	timeout   time.Duration
+}
+
+type config struct {
+	gha     gha.Config
+	gcb     gcb.Config
+	logger  logging.Logger
+	timeout time.Duration
+}
+
+// RetryPolicy is the retry policy of failed requests, with exponential
+// backoff.
+type RetryPolicy = gha.RetryPolicy
+
+// DefaultRetryPolicy is the retry policy of requests to Rekor.
+var DefaultRetryPolicy = gha.DefaultRetryPolicy
+
+// GCBKeySet is a set of Google Cloud Build signing keys, with their
+// regions, validity windows and algorithms.
+type GCBKeySet = keys.KeySet
+
+// GCBKeySetFromPath reads a GCB key set from a JSON file, or from all the
+// JSON files of a directory.
+func GCBKeySetFromPath(path string) (*GCBKeySet, error) {
+	return keys.KeySetFromPath(path)
+}
+
+// CheckpointConfig configures the verification of transparency log
+// checkpoints: their consistency with the last verified checkpoints and
+// their co-signatures by witnesses.
+type CheckpointConfig = gha.CheckpointConfig
+
+// CheckpointConfigNew returns a checkpoint configuration. If statePath is
+// set, the latest verified checkpoint of each log is persisted to this
+// file, and new checkpoints must be consistent with it. If witnesses are
+// set, at least threshold of them must have co-signed the checkpoint.
+// Witness keys are note verifier keys, e.g. witness.example.com+ba3a6e4f+AQ....
+func CheckpointConfigNew(statePath string, witnesses []string, threshold int) (*CheckpointConfig, error) {
+	return gha.CheckpointConfigNew(statePath, witnesses, threshold)
+}
+
+// TimestampPolicy is the source of signing time required in Sigstore
+// bundles: a transparency log entry, an RFC 3161 timestamp, or both.
+type TimestampPolicy = gha.TimestampPolicy
+
+const (
+	// TimestampPolicyTlog requires a transparency log entry. It is the
+	// default.
+	TimestampPolicyTlog = gha.TimestampPolicyTlog
+	// TimestampPolicyTSA requires an RFC 3161 timestamp.
+	TimestampPolicyTSA = gha.TimestampPolicyTSA
+	// TimestampPolicyBoth requires a transparency log entry and an
+	// RFC 3161 timestamp.
+	TimestampPolicyBoth = gha.TimestampPolicyBoth
 )
 
-func getVerifier(builderOpts *options.BuilderOpts) (register.SLSAVerifier, error) {
+// TimestampPolicyFromString returns the policy of the given name: tlog,
+// tsa or both.
+func TimestampPolicyFromString(name string) (TimestampPolicy, error) {
+	return gha.TimestampPolicyFromString(name)
+}
+
+// TimestampAuthority is the certificate chain of an RFC 3161 timestamp
+// authority.
+type TimestampAuthority = gha.TimestampAuthority
+
+// TimestampAuthorityFromPEM returns the timestamp authority of a PEM
+// certificate chain, ordered from the signing certificate, if any, to the
+// root certificate.
+func TimestampAuthorityFromPEM(content []byte) (*TimestampAuthority, error) {
+	return gha.TimestampAuthorityFromPEM(content)
+}
+
+// Option configures a Verifier.
+type Option func(*config)
+
+// WithTrustedRoot sets the Sigstore trusted root, e.g. for a private
+// Sigstore instance. It defaults to the public-good instance.
+func WithTrustedRoot(trustedRoot *TrustedRoot) Option {
+	return func(c *config) {
+		c.gha.TrustedRoot = trustedRoot
+	}
+}
+
+// WithTrustedRootCache sets the cache of the Sigstore trusted root, e.g. to
+// refresh it with a different TTL, unless a trusted root is set. It
+// defaults to a cache of the public-good instance, refreshed every
+// DefaultTrustedRootTTL.
+func WithTrustedRootCache(cache *TrustedRootCache) Option {
+	return func(c *config) {
+		c.gha.TrustedRootCache = cache
+	}
+}
+
+// WithRekorClient sets the client of the Rekor transparency log. It
+// defaults to a client of the public-good instance.
+func WithRekorClient(rekorClient *client.Rekor) Option {
+	return func(c *config) {
+		c.gha.RekorClient = rekorClient
+	}
+}
+
+// WithTrustedBuilders sets the GitHub Actions reusable workflows trusted
+// when no builder ID is expected, e.g.
+// https://github.com/slsa-framework/slsa-github-generator/.github/workflows/builder_go_slsa3.yml.
+// They replace the default trusted builders.
+func WithTrustedBuilders(builderIDs ...string) Option {
+	return func(c *config) {
+		c.gha.TrustedBuilders = make(map[string]bool, len(builderIDs))
+		for _, id := range builderIDs {
+			c.gha.TrustedBuilders[id] = true
+		}
+	}
+}
+
+// WithHTTPClient sets the HTTP client for requests to Rekor, unless a
+// Rekor client is set, and to OCI registries.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *config) {
+		c.gha.HTTPClient = httpClient
+	}
+}
+
+// WithTimeout sets the timeout of each verification, including all
+// network requests. Zero means no timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.timeout = timeout
+	}
+}
+
+// WithRequestTimeout sets the timeout of each request to Rekor, unless a
+// Rekor client is set, and to OCI registries. Zero means no timeout.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.gha.RequestTimeout = timeout
+	}
+}
+
+// WithRetryPolicy sets the retry policy of requests to Rekor, unless a
+// Rekor client is set, and to OCI registries. It defaults to
+// DefaultRetryPolicy for Rekor and to the default policy of the registry
+// client for registries.
+func WithRetryPolicy(retry RetryPolicy) Option {
+	return func(c *config) {
+		c.gha.Retry = &retry
+	}
+}
+
+// WithGCBKeySet sets the keys trusted to sign Google Cloud Build
+// provenance, e.g. after a key rotation. They replace the keys embedded in
+// slsa-verifier.
+func WithGCBKeySet(keySet *GCBKeySet) Option {
+	return func(c *config) {
+		c.gcb.KeySet = keySet
+	}
+}
+
+// WithCheckpoints sets the verification of the checkpoints of the
+// transparency log entries of GitHub Actions builders, found online or in
+// Sigstore bundles. By default, only the signature of the log on the
+// checkpoints is verified. Other verifiers, and npm packages, return
+// serrors.ErrorNotSupported.
+func WithCheckpoints(checkpoints *CheckpointConfig) Option {
+	return func(c *config) {
+		c.gha.Checkpoints = checkpoints
+	}
+}
+
+// WithTimestampPolicy sets the source of signing time required in Sigstore
+// bundles. By default, a transparency log entry is required.
+func WithTimestampPolicy(policy TimestampPolicy) Option {
+	return func(c *config) {
+		c.gha.TimestampPolicy = policy
+	}
+}
+
+// WithTimestampAuthorities adds timestamp authorities to those of the
+// trusted root.
+func WithTimestampAuthorities(authorities ...*TimestampAuthority) Option {
+	return func(c *config) {
+		c.gha.TimestampAuthorities = append(c.gha.TimestampAuthorities, authorities...)
+	}
+}
+
+// WithLogger sets the logger that receives the progress of verifications.
+// It takes precedence over a logger set on the context.
+func WithLogger(logger logging.Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+// VerifierNew returns a Verifier. Without options, it is configured like
+// the package-level functions.
+func VerifierNew(opts ...Option) *Verifier {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	ghaVerifier := gha.GHAVerifierWithConfig(c.gha)
+	return &Verifier{
+		overrides: map[string]register.SLSAVerifier{
+			gha.VerifierName: ghaVerifier,
+			gcb.VerifierName: gcb.GCBVerifierWithConfig(c.gcb),
+		},
+		gha:     ghaVerifier,
+		logger:  c.logger,
+		timeout: c.timeout,
+	}
+}
+
+// defaultVerifier is used by the package-level functions.
+var defaultVerifier = VerifierNew()
+
+// context returns the context of a verification, with the logger and the
+// timeout of the verifier.
+func (v *Verifier) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if v.logger != nil {
+		ctx = logging.WithLogger(ctx, v.logger)
+	}
+	if v.timeout > 0 {
+		return context.WithTimeout(ctx, v.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+func (v *Verifier) slsaVerifier(name string) register.SLSAVerifier {
+	if verifier, ok := v.overrides[name]; ok {
+		return verifier
+	}
+	return register.SLSAVerifiers[name]
+}
+
+func (v *Verifier) getVerifier(builderOpts *options.BuilderOpts) (register.SLSAVerifier, error) {
 	// By default, use the GHA builders
-	verifier := register.SLSAVerifiers[gha.VerifierName]
+	verifier := v.slsaVerifier(gha.VerifierName)
 
 	// If user provids a builderID, find the right verifier based on its ID.
 	if builderOpts.ExpectedID != nil &&
@@ -23,15 +301,27 @@ func getVerifier(builderOpts *options.BuilderOpts) (register.SLSAVerifier, error
 		if err != nil {
 			return nil, err
 		}
-		for _, v := range register.SLSAVerifiers {
-			if v.IsAuthoritativeFor(name) {
-				return v, nil
+		// Provenance signed with static keys is verified from the predicate
+		// only, unless the builder always signs with static keys.
+		if len(builderOpts.PublicKeys) > 0 {
+			if tv := v.slsaVerifier(tekton.VerifierName); tv.IsAuthoritativeFor(name) {
+				return tv, nil
+			}
+			return v.slsaVerifier(statickey.VerifierName), nil
+		}
+		for verifierName := range register.SLSAVerifiers {
+			if sv := v.slsaVerifier(verifierName); sv.IsAuthoritativeFor(name) {
+				return sv, nil
 			}
 		}
 		// No builder found.
 		return nil, fmt.Errorf("%w: %s", serrors.ErrorVerifierNotSupported, *builderOpts.ExpectedID)
 	}
 
+	if len(builderOpts.PublicKeys) > 0 {
+		return nil, fmt.Errorf("%w: a builder ID is required with public keys", serrors.ErrorInvalidBuilderID)
+	}
+
 	return verifier, nil
 }
 
@@ -40,37 +330,338 @@ func VerifyImage(ctx context.Context, artifactImage string,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
 ) ([]byte, *utils.TrustedBuilderID, error) {
-	verifier, err := getVerifier(builderOpts)
+	return defaultVerifier.VerifyImage(ctx, artifactImage, provenance, provenanceOpts, builderOpts)
+}
+
+func VerifyArtifact(ctx context.Context,
+	provenance []byte, artifactHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	return defaultVerifier.VerifyArtifact(ctx, provenance, artifactHash, provenanceOpts, builderOpts)
+}
+
+func VerifyNpmPackage(ctx context.Context,
+	attestations []byte, tarballHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	return defaultVerifier.VerifyNpmPackage(ctx, attestations, tarballHash, provenanceOpts, builderOpts)
+}
+
+// VerifyImageResult is like VerifyImage and returns the verification
+// result: the verified statement and provenance, the signing certificate,
+// the transparency log entry and the checks performed.
+func VerifyImageResult(ctx context.Context, artifactImage string,
+	provenance []byte,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	return defaultVerifier.VerifyImageResult(ctx, artifactImage, provenance, provenanceOpts, builderOpts)
+}
+
+// VerifyArtifactResult is like VerifyArtifact and returns the verification
+// result.
+func VerifyArtifactResult(ctx context.Context,
+	provenance []byte, artifactHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	return defaultVerifier.VerifyArtifactResult(ctx, provenance, artifactHash, provenanceOpts, builderOpts)
+}
+
+// VerifyNpmPackageResult is like VerifyNpmPackage and returns the
+// verification result.
+func VerifyNpmPackageResult(ctx context.Context,
+	attestations []byte, tarballHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	return defaultVerifier.VerifyNpmPackageResult(ctx, attestations, tarballHash, provenanceOpts, builderOpts)
+}
+
+// VerifyImage verifies provenance for an OCI image.
+func (v *Verifier) VerifyImage(ctx context.Context, artifactImage string,
+	provenance []byte,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	result, err := v.VerifyImageResult(ctx, artifactImage, provenance, provenanceOpts, builderOpts)
 	if err != nil {
 		return nil, nil, err
 	}
-	return verifier.VerifyImage(ctx, provenance, artifactImage, provenanceOpts, builderOpts)
+	return result.Statement, result.BuilderID, nil
 }
 
-func VerifyArtifact(ctx context.Context,
+// VerifyArtifact verifies provenance for an artifact.
+func (v *Verifier) VerifyArtifact(ctx context.Context,
 	provenance []byte, artifactHash string,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
 ) ([]byte, *utils.TrustedBuilderID, error) {
-	verifier, err := getVerifier(builderOpts)
+	result, err := v.VerifyArtifactResult(ctx, provenance, artifactHash, provenanceOpts, builderOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.Statement, result.BuilderID, nil
+}
+
+// VerifyNpmPackage verifies an npm package tarball.
+func (v *Verifier) VerifyNpmPackage(ctx context.Context,
+	attestations []byte, tarballHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	result, err := v.VerifyNpmPackageResult(ctx, attestations, tarballHash, provenanceOpts, builderOpts)
 	if err != nil {
 		return nil, nil, err
 	}
+	return result.Statement, result.BuilderID, nil
+}
 
-	return verifier.VerifyArtifact(ctx, provenance, artifactHash,
-		provenanceOpts, builderOpts)
+// VerifyImageResult is like VerifyImage and returns the verification
+// result.
+func (v *Verifier) VerifyImageResult(ctx context.Context, artifactImage string,
+	provenance []byte,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	verifier, err := v.getVerifier(builderOpts)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.checkCheckpoints(verifier); err != nil {
+		return nil, err
+	}
+	ctx, cancel := v.context(ctx)
+	defer cancel()
+	if rv, ok := verifier.(register.SLSAResultVerifier); ok {
+		return rv.VerifyImageResult(ctx, provenance, artifactImage, provenanceOpts, builderOpts)
+	}
+	return resultNew(verifier.VerifyImage(ctx, provenance, artifactImage, provenanceOpts, builderOpts))
 }
 
-func VerifyNpmPackage(ctx context.Context,
+// VerifyArtifactResult is like VerifyArtifact and returns the verification
+// result. The provenance may hold several attestations, one per line, of
+// which the first that passes is used.
+func (v *Verifier) VerifyArtifactResult(ctx context.Context,
+	provenance []byte, artifactHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	verifier, err := v.getVerifier(builderOpts)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.checkCheckpoints(verifier); err != nil {
+		return nil, err
+	}
+	ctx, cancel := v.context(ctx)
+	defer cancel()
+
+	lines := utils.ProvenanceLines(provenance)
+	if len(lines) == 1 {
+		return verifyArtifactResult(ctx, verifier, lines[0].Content, artifactHash, provenanceOpts, builderOpts)
+	}
+
+	var errs []error
+	for _, line := range lines {
+		result, err := verifyArtifactResult(ctx, verifier, line.Content, artifactHash, provenanceOpts, builderOpts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line.Number, err))
+			continue
+		}
+		result.ProvenanceLine = line.Number
+		return result, nil
+	}
+	return nil, errors.Join(errs...)
+}
+
+func verifyArtifactResult(ctx context.Context, verifier register.SLSAVerifier,
+	provenance []byte, artifactHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	if rv, ok := verifier.(register.SLSAResultVerifier); ok {
+		return rv.VerifyArtifactResult(ctx, provenance, artifactHash, provenanceOpts, builderOpts)
+	}
+	return resultNew(verifier.VerifyArtifact(ctx, provenance, artifactHash, provenanceOpts, builderOpts))
+}
+
+// VerifyNpmPackageResult is like VerifyNpmPackage and returns the
+// verification result.
+func (v *Verifier) VerifyNpmPackageResult(ctx context.Context,
+	attestations []byte, tarballHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) (*utils.VerificationResult, error) {
+	verifier, err := v.getVerifier(builderOpts)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.checkCheckpoints(verifier); err != nil {
+		return nil, err
+	}
+	ctx, cancel := v.context(ctx)
+	defer cancel()
+	if rv, ok := verifier.(register.SLSAResultVerifier); ok {
+		return rv.VerifyNpmPackageResult(ctx, attestations, tarballHash, provenanceOpts, builderOpts)
+	}
+	return resultNew(verifier.VerifyNpmPackage(ctx, attestations, tarballHash, provenanceOpts, builderOpts))
+}
+
+// checkCheckpoints returns an error if the checkpoints of transparency log
+// entries are configured for a verifier that does not verify them.
+func (v *Verifier) checkCheckpoints(verifier register.SLSAVerifier) error {
+	if v.gha.Checkpoints() == nil || verifier == register.SLSAVerifier(v.gha) {
+		return nil
+	}
+	return fmt.Errorf("%w: transparency log checkpoints with this builder", serrors.ErrorNotSupported)
+}
+
+// resultNew returns the result of a verifier that only returns the verified
+// statement and builder ID. The provenance is nil if the statement is not
+// SLSA provenance.
+func resultNew(content []byte, builderID *utils.TrustedBuilderID, err error) (*utils.VerificationResult, error) {
+	if err != nil {
+		return nil, err
+	}
+	prov, _ := sprovenance.FromStatement(content)
+	return &utils.VerificationResult{
+		Statement:  content,
+		Provenance: prov,
+		BuilderID:  builderID,
+	}, nil
+}
+
+// VerifyImageProvenance is like VerifyImage and also returns the verified
+// provenance.
+func VerifyImageProvenance(ctx context.Context, artifactImage string,
+	provenance []byte,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *sprovenance.Provenance, *utils.TrustedBuilderID, error) {
+	return withProvenance(VerifyImageResult(ctx, artifactImage, provenance, provenanceOpts, builderOpts))
+}
+
+// VerifyArtifactProvenance is like VerifyArtifact and also returns the
+// verified provenance.
+func VerifyArtifactProvenance(ctx context.Context,
+	provenance []byte, artifactHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *sprovenance.Provenance, *utils.TrustedBuilderID, error) {
+	return withProvenance(VerifyArtifactResult(ctx, provenance, artifactHash, provenanceOpts, builderOpts))
+}
+
+// VerifyNpmPackageProvenance is like VerifyNpmPackage and also returns the
+// verified provenance.
+func VerifyNpmPackageProvenance(ctx context.Context,
 	attestations []byte, tarballHash string,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
+) ([]byte, *sprovenance.Provenance, *utils.TrustedBuilderID, error) {
+	return withProvenance(VerifyNpmPackageResult(ctx, attestations, tarballHash, provenanceOpts, builderOpts))
+}
+
+func withProvenance(result *utils.VerificationResult, err error,
+) ([]byte, *sprovenance.Provenance, *utils.TrustedBuilderID, error) {
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	prov := result.Provenance
+	if prov == nil {
+		if prov, err = sprovenance.FromStatement(result.Statement); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return result.Statement, prov, result.BuilderID, nil
+}
+
+// VerifyVSA verifies a Verification Summary Attestation issued by a trusted
+// verifier and returns the verified statement and the verifier ID.
+// With a public key, the attestation is a DSSE envelope, or several, one per
+// line, of which one must pass: the lines that fail are skipped. Otherwise
+// it is a Sigstore bundle signed by the expected keyless identity.
+func VerifyVSA(ctx context.Context, attestation []byte,
+	vsaOpts *options.VSAOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	return defaultVerifier.VerifyVSA(ctx, attestation, vsaOpts)
+}
+
+// VerifyVSA verifies a Verification Summary Attestation issued by a trusted
+// verifier and returns the verified statement and the verifier ID.
+func (v *Verifier) VerifyVSA(ctx context.Context, attestation []byte,
+	vsaOpts *options.VSAOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	ctx, cancel := v.context(ctx)
+	defer cancel()
+
+	if vsaOpts.PublicKey == nil {
+		if vsaOpts.ExpectedSignerIdentity == "" || vsaOpts.ExpectedSignerIssuer == "" {
+			return nil, nil, fmt.Errorf("%w: a public key or a keyless identity and issuer are required",
+				serrors.ErrorInvalidPublicKey)
+		}
+		trustedRoot, err := v.gha.TrustedRoot(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		env, err := gha.VerifyBundleWithIdentity(ctx, attestation, trustedRoot, v.gha.TimestampPolicy(),
+			vsaOpts.ExpectedSignerIssuer, vsaOpts.ExpectedSignerIdentity)
+		if err != nil {
+			return nil, nil, err
+		}
+		return verifyVSAEnvelope(env, vsaOpts)
+	}
+
+	verifier, err := utils.DsseVerifierNew(vsaOpts.PublicKey, utils.KeyFormatPEM, "", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines := utils.ProvenanceLines(attestation)
+	var errs []error
+	for _, line := range lines {
+		payload, verifierID, err := verifyVSALine(ctx, verifier, line.Content, vsaOpts)
+		if err != nil {
+			if len(lines) == 1 {
+				return nil, nil, err
+			}
+			errs = append(errs, fmt.Errorf("line %d: %w", line.Number, err))
+			continue
+		}
+		return payload, verifierID, nil
+	}
+	return nil, nil, errors.Join(errs...)
+}
+
+// verifyVSALine verifies a VSA envelope signed with a public key.
+func verifyVSALine(ctx context.Context, verifier *dsselib.EnvelopeVerifier, line []byte,
+	vsaOpts *options.VSAOpts,
 ) ([]byte, *utils.TrustedBuilderID, error) {
-	verifier, err := getVerifier(builderOpts)
+	env, err := utils.EnvelopeFromBytes(line)
 	if err != nil {
 		return nil, nil, err
 	}
+	if _, err := verifier.Verify(ctx, env); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", serrors.ErrorInvalidSignature, err)
+	}
+	return verifyVSAEnvelope(env, vsaOpts)
+}
 
-	return verifier.VerifyNpmPackage(ctx, attestations, tarballHash,
-		provenanceOpts, builderOpts)
+// verifyVSAEnvelope verifies the VSA of an envelope whose signature is
+// verified.
+func verifyVSAEnvelope(env *dsselib.Envelope, vsaOpts *options.VSAOpts) ([]byte, *utils.TrustedBuilderID, error) {
+	payload, err := utils.PayloadFromEnvelope(env)
+	if err != nil {
+		return nil, nil, err
+	}
+	statement, err := vsa.Verify(payload, vsaOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	verifierID, err := utils.TrustedBuilderIDNew(statement.Predicate.Verifier.ID, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return payload, verifierID, nil
 }
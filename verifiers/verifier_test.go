@@ -0,0 +1,400 @@
+package verifiers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/register"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/tekton"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/vsa"
+)
+
+func Test_Verifier_getVerifier(t *testing.T) {
+	t.Parallel()
+
+	tenant := VerifierNew(WithTrustedBuilders("https://github.com/org/repo/.github/workflows/builder.yml"))
+	gcbBuilderID := "https://cloudbuild.googleapis.com/GoogleHostedWorker@v0.3"
+	ghaBuilderID := "https://github.com/org/repo/.github/workflows/builder.yml@v1.0.0"
+	tektonBuilderID := "https://tekton.dev/chains/v2@v1"
+
+	tests := []struct {
+		name      string
+		verifier  *Verifier
+		builderID *string
+		expected  register.SLSAVerifier
+	}{
+		{
+			name:     "default GHA",
+			verifier: tenant,
+			expected: tenant.overrides[gha.VerifierName],
+		},
+		{
+			name:      "GHA builder ID",
+			verifier:  tenant,
+			builderID: &ghaBuilderID,
+			expected:  tenant.overrides[gha.VerifierName],
+		},
+		{
+			name:      "GCB builder ID",
+			verifier:  tenant,
+			builderID: &gcbBuilderID,
+			expected:  tenant.overrides[gcb.VerifierName],
+		},
+		{
+			name:      "registered verifier",
+			verifier:  tenant,
+			builderID: &tektonBuilderID,
+			expected:  register.SLSAVerifiers[tekton.VerifierName],
+		},
+		{
+			name:     "default verifier",
+			verifier: defaultVerifier,
+			expected: defaultVerifier.overrides[gha.VerifierName],
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			v, err := tt.verifier.getVerifier(&options.BuilderOpts{ExpectedID: tt.builderID})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if v != tt.expected {
+				t.Errorf("unexpected verifier %T %p", v, v)
+			}
+		})
+	}
+
+	if tenant.overrides[gha.VerifierName] == defaultVerifier.overrides[gha.VerifierName] {
+		t.Errorf("verifiers share the GHA configuration")
+	}
+}
+
+func Test_Verifier_checkCheckpoints(t *testing.T) {
+	t.Parallel()
+
+	checkpoints, err := CheckpointConfigNew(t.TempDir()+"/checkpoints.json", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := VerifierNew(WithCheckpoints(checkpoints))
+	gcbBuilderID := "https://cloudbuild.googleapis.com/GoogleHostedWorker@v0.3"
+	tektonBuilderID := "https://tekton.dev/chains/v2@v1"
+
+	tests := []struct {
+		name      string
+		verifier  *Verifier
+		builderID *string
+		expected  error
+	}{
+		{
+			name:     "GHA builder",
+			verifier: verifier,
+		},
+		{
+			name:      "GCB builder",
+			verifier:  verifier,
+			builderID: &gcbBuilderID,
+			expected:  serrors.ErrorNotSupported,
+		},
+		{
+			name:      "registered verifier",
+			verifier:  verifier,
+			builderID: &tektonBuilderID,
+			expected:  serrors.ErrorNotSupported,
+		},
+		{
+			name:      "no checkpoints",
+			verifier:  VerifierNew(),
+			builderID: &gcbBuilderID,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			builderOpts := &options.BuilderOpts{ExpectedID: tt.builderID}
+			v, err := tt.verifier.getVerifier(builderOpts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := tt.verifier.checkCheckpoints(v); !errors.Is(err, tt.expected) {
+				t.Errorf("unexpected error %v, expected %v", err, tt.expected)
+			}
+			if tt.expected == nil {
+				return
+			}
+			_, err = tt.verifier.VerifyArtifactResult(context.Background(), []byte("{}"), "abc",
+				&options.ProvenanceOpts{}, builderOpts)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("unexpected error %v, expected %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_Verifier_VerifyArtifactResultLines(t *testing.T) {
+	t.Parallel()
+
+	bundle := `{"mediaType":"application/vnd.dev.sigstore.bundle+json;version=0.4"}`
+	envelope := `{"payloadType":"application/vnd.in-toto+json","payload":"e30=","signatures":[]}`
+	tests := []struct {
+		name       string
+		provenance string
+		policy     TimestampPolicy
+		// lines are the lines expected in the error.
+		lines    []string
+		expected []error
+	}{
+		{
+			name:       "single bundle",
+			provenance: bundle,
+			expected:   []error{gha.ErrorUnsupportedBundle},
+		},
+		{
+			name:       "bundle per line",
+			provenance: bundle + "\n\n" + bundle + "\n",
+			lines:      []string{"line 1: ", "line 3: "},
+			expected:   []error{gha.ErrorUnsupportedBundle},
+		},
+		{
+			name:       "bundle and envelope",
+			provenance: bundle + "\n" + envelope + "\n",
+			policy:     TimestampPolicyTSA,
+			lines:      []string{"line 1: ", "line 2: "},
+			expected:   []error{gha.ErrorUnsupportedBundle, serrors.ErrorMissingTimestamp},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			v := VerifierNew(WithTrustedRoot(&TrustedRoot{}), WithTimestampPolicy(tt.policy))
+			_, err := v.VerifyArtifactResult(context.Background(), []byte(tt.provenance),
+				"0000000000000000000000000000000000000000000000000000000000000000",
+				&options.ProvenanceOpts{}, &options.BuilderOpts{})
+			for _, expected := range tt.expected {
+				if !errors.Is(err, expected) {
+					t.Fatalf(cmp.Diff(err, expected))
+				}
+			}
+			for _, line := range tt.lines {
+				if !strings.Contains(err.Error(), line) {
+					t.Errorf("error %q does not contain %q", err, line)
+				}
+			}
+			if len(tt.lines) == 0 && strings.Contains(err.Error(), "line ") {
+				t.Errorf("unexpected line in error %q", err)
+			}
+		})
+	}
+}
+
+func Test_Verifier_VerifyArtifactResultLinesPublicKey(t *testing.T) {
+	t.Parallel()
+
+	digest := "8fb8a7c1a2b5a8d3d8f0cd9a35a0e1b1d3b0b8a3c7a0d96d9e7ab2a42e2c6ad2"
+	builderID := "https://ci.example.com/builder"
+	statement := `{"_type":"https://in-toto.io/Statement/v1",` +
+		`"predicateType":"https://slsa.dev/provenance/v1",` +
+		`"subject":[{"name":"app","digest":{"sha256":"` + digest + `"}}],` +
+		`"predicate":{"buildDefinition":{"buildType":"https://ci.example.com/build/v1",` +
+		`"resolvedDependencies":[{"uri":"git+https://github.com/org/app@refs/heads/main",` +
+		`"digest":{"gitCommit":"6f3a9c3d4b2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a"}}]},` +
+		`"runDetails":{"builder":{"id":"` + builderID + `"}}}}`
+
+	// sign returns the statement signed with a new key, and the PEM-encoded
+	// public key.
+	sign := func() (string, []byte) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signer, err := utils.DsseSignerNew(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		env, err := signer.SignPayload(context.Background(), intoto.PayloadType, []byte(statement))
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := json.Marshal(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		der, err = x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	}
+	untrusted, _ := sign()
+	trusted, publicKey := sign()
+
+	result, err := VerifierNew().VerifyArtifactResult(context.Background(),
+		[]byte(untrusted+"\n\n"+trusted+"\n"), digest,
+		&options.ProvenanceOpts{ExpectedSourceURI: "github.com/org/app", ExpectedDigest: digest},
+		&options.BuilderOpts{ExpectedID: &builderID, PublicKeys: [][]byte{publicKey}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProvenanceLine != 3 {
+		t.Errorf("unexpected provenance line %d", result.ProvenanceLine)
+	}
+
+	_, err = VerifierNew().VerifyArtifactResult(context.Background(),
+		[]byte(untrusted+"\n"+untrusted+"\n"), digest,
+		&options.ProvenanceOpts{ExpectedSourceURI: "github.com/org/app", ExpectedDigest: digest},
+		&options.BuilderOpts{ExpectedID: &builderID, PublicKeys: [][]byte{publicKey}})
+	if !errors.Is(err, serrors.ErrorNoValidSignature) || !strings.Contains(err.Error(), "line 2: ") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func Test_Verifier_VerifyVSALines(t *testing.T) {
+	t.Parallel()
+
+	digest := "8fb8a7c1a2b5a8d3d8f0cd9a35a0e1b1d3b0b8a3c7a0d96d9e7ab2a42e2c6ad2"
+	resourceURI := "app"
+	statement := vsa.New(
+		[]intoto.Subject{{Name: resourceURI, Digest: map[string]string{"sha256": digest}}},
+		resourceURI, "", vsa.ResourceDescriptor{}, nil, []string{"SLSA_BUILD_LEVEL_1"},
+	)
+
+	// newKey returns a VSA signer with a new key, and the PEM-encoded public
+	// key.
+	newKey := func() (*dsselib.EnvelopeSigner, []byte) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signer, err := utils.DsseSignerNew(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		der, err = x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signer, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	}
+	sign := func(signer *dsselib.EnvelopeSigner, statement *vsa.Statement) string {
+		env, err := vsa.Sign(context.Background(), statement, signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := json.Marshal(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+	trustedSigner, publicKey := newKey()
+	untrustedSigner, _ := newKey()
+	trusted := sign(trustedSigner, statement)
+	untrusted := sign(untrustedSigner, statement)
+	other := *statement
+	other.Predicate.ResourceURI = "other"
+	mismatch := sign(trustedSigner, &other)
+
+	tests := []struct {
+		name        string
+		attestation string
+		// lines are the lines expected in the error.
+		lines    []string
+		expected []error
+	}{
+		{
+			name:        "single VSA",
+			attestation: trusted,
+		},
+		{
+			name:        "foreign VSA skipped",
+			attestation: untrusted + "\n" + trusted + "\n",
+		},
+		{
+			name:        "corrupted VSA skipped",
+			attestation: "{\n" + trusted + "\n",
+		},
+		{
+			name:        "mismatching VSA skipped",
+			attestation: mismatch + "\n\n" + trusted + "\n",
+		},
+		{
+			name:        "single foreign VSA",
+			attestation: untrusted,
+			expected:    []error{serrors.ErrorInvalidSignature},
+		},
+		{
+			name:        "no VSA verifies",
+			attestation: untrusted + "\n\n" + mismatch + "\n",
+			lines:       []string{"line 1: ", "line 3: "},
+			expected:    []error{serrors.ErrorInvalidSignature, serrors.ErrorMismatchResourceURI},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			payload, verifierID, err := VerifierNew().VerifyVSA(context.Background(), []byte(tt.attestation),
+				&options.VSAOpts{
+					ExpectedDigests:     map[string]string{"sha256": digest},
+					ExpectedVerifierID:  vsa.VerifierID,
+					ExpectedResourceURI: resourceURI,
+					PublicKey:           publicKey,
+				})
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if payload == nil || verifierID.Name() != vsa.VerifierID {
+					t.Errorf("unexpected result %q, %v", payload, verifierID)
+				}
+				return
+			}
+			for _, expected := range tt.expected {
+				if !errors.Is(err, expected) {
+					t.Fatalf(cmp.Diff(err, expected))
+				}
+			}
+			for _, line := range tt.lines {
+				if !strings.Contains(err.Error(), line) {
+					t.Errorf("error %q does not contain %q", err, line)
+				}
+			}
+			if len(tt.lines) == 0 && strings.Contains(err.Error(), "line ") {
+				t.Errorf("unexpected line in error %q", err)
+			}
+		})
+	}
+}
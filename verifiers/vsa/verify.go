@@ -0,0 +1,96 @@
+package vsa
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+// levelRegexp matches levels such as SLSA_BUILD_LEVEL_3.
+var levelRegexp = regexp.MustCompile(`^SLSA_([A-Z_]+)_LEVEL_([0-9]+)$`)
+
+// Verify verifies the content of a VSA, whose signature must already have
+// been verified, against the options and returns the parsed statement.
+func Verify(payload []byte, vsaOpts *options.VSAOpts) (*Statement, error) {
+	var statement Statement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, fmt.Errorf("%w: decoding json: %w", serrors.ErrorInvalidDssePayload, err)
+	}
+
+	if statement.Type != StatementType && statement.Type != intoto.StatementInTotoV01 {
+		return nil, fmt.Errorf("%w: unexpected statement type %q", serrors.ErrorInvalidDssePayload, statement.Type)
+	}
+	if statement.PredicateType != PredicateType {
+		return nil, fmt.Errorf("%w: expected predicate type %q, got %q",
+			serrors.ErrorInvalidDssePayload, PredicateType, statement.PredicateType)
+	}
+
+	if err := utils.VerifySubjectDigests(statement.Subject, vsaOpts.ExpectedDigests); err != nil {
+		return nil, err
+	}
+
+	predicate := statement.Predicate
+	if predicate.Verifier.ID != vsaOpts.ExpectedVerifierID {
+		return nil, fmt.Errorf("%w: expected %q, got %q", serrors.ErrorMismatchVerifierID,
+			vsaOpts.ExpectedVerifierID, predicate.Verifier.ID)
+	}
+	if predicate.ResourceURI != vsaOpts.ExpectedResourceURI {
+		return nil, fmt.Errorf("%w: expected %q, got %q", serrors.ErrorMismatchResourceURI,
+			vsaOpts.ExpectedResourceURI, predicate.ResourceURI)
+	}
+	if predicate.VerificationResult != ResultPassed {
+		return nil, fmt.Errorf("%w: got %q", serrors.ErrorInvalidVerificationResult, predicate.VerificationResult)
+	}
+	if err := verifyLevels(predicate.VerifiedLevels, vsaOpts.ExpectedLevels); err != nil {
+		return nil, err
+	}
+
+	return &statement, nil
+}
+
+// verifyLevels verifies that each expected level is met by a verified level
+// of the same track.
+func verifyLevels(verified, expected []string) error {
+	highest := make(map[string]int)
+	for _, l := range verified {
+		track, level, err := parseLevel(l)
+		if err != nil {
+			// Levels of other frameworks may be present.
+			continue
+		}
+		if level > highest[track] {
+			highest[track] = level
+		}
+	}
+
+	for _, l := range expected {
+		track, level, err := parseLevel(l)
+		if err != nil {
+			return err
+		}
+		if highest[track] < level {
+			return fmt.Errorf("%w: %s not in %v", serrors.ErrorMismatchVerifiedLevels, l, verified)
+		}
+	}
+	return nil
+}
+
+// parseLevel returns the track and level of a SLSA level.
+func parseLevel(l string) (string, int, error) {
+	match := levelRegexp.FindStringSubmatch(l)
+	if match == nil {
+		return "", 0, fmt.Errorf("%w: level %q", serrors.ErrorInvalidFormat, l)
+	}
+	level, err := strconv.Atoi(match[2])
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: level %q", serrors.ErrorInvalidFormat, l)
+	}
+	return match[1], level, nil
+}
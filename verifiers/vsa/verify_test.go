@@ -0,0 +1,160 @@
+package vsa
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+const (
+	testDigest      = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	testResourceURI = "binary-linux-amd64"
+)
+
+func testStatement() *Statement {
+	return New(
+		[]intoto.Subject{{Name: testResourceURI, Digest: map[string]string{"sha256": testDigest}}},
+		testResourceURI, "v2.5.0", ResourceDescriptor{}, nil, []string{"SLSA_BUILD_LEVEL_2"},
+	)
+}
+
+func Test_Verify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		statement func(*Statement)
+		opts      func(*options.VSAOpts)
+		expected  error
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name: "statement v0.1",
+			statement: func(s *Statement) {
+				s.Type = intoto.StatementInTotoV01
+			},
+		},
+		{
+			name: "invalid statement type",
+			statement: func(s *Statement) {
+				s.Type = "https://in-toto.io/Statement/v0.2"
+			},
+			expected: serrors.ErrorInvalidDssePayload,
+		},
+		{
+			name: "provenance predicate",
+			statement: func(s *Statement) {
+				s.PredicateType = "https://slsa.dev/provenance/v1"
+			},
+			expected: serrors.ErrorInvalidDssePayload,
+		},
+		{
+			name: "mismatch digest",
+			opts: func(o *options.VSAOpts) {
+				o.ExpectedDigests = map[string]string{"sha256": "abcd"}
+			},
+			expected: serrors.ErrorMismatchHash,
+		},
+		{
+			name: "mismatch verifier id",
+			opts: func(o *options.VSAOpts) {
+				o.ExpectedVerifierID = "https://example.com/verifier"
+			},
+			expected: serrors.ErrorMismatchVerifierID,
+		},
+		{
+			name: "mismatch resource uri",
+			opts: func(o *options.VSAOpts) {
+				o.ExpectedResourceURI = "binary-darwin-amd64"
+			},
+			expected: serrors.ErrorMismatchResourceURI,
+		},
+		{
+			name: "failed result",
+			statement: func(s *Statement) {
+				s.Predicate.VerificationResult = ResultFailed
+			},
+			expected: serrors.ErrorInvalidVerificationResult,
+		},
+		{
+			name: "lower level met",
+			opts: func(o *options.VSAOpts) {
+				o.ExpectedLevels = []string{"SLSA_BUILD_LEVEL_1"}
+			},
+		},
+		{
+			name: "same level met",
+			opts: func(o *options.VSAOpts) {
+				o.ExpectedLevels = []string{"SLSA_BUILD_LEVEL_2"}
+			},
+		},
+		{
+			name: "higher level not met",
+			opts: func(o *options.VSAOpts) {
+				o.ExpectedLevels = []string{"SLSA_BUILD_LEVEL_3"}
+			},
+			expected: serrors.ErrorMismatchVerifiedLevels,
+		},
+		{
+			name: "other track not met",
+			opts: func(o *options.VSAOpts) {
+				o.ExpectedLevels = []string{"SLSA_SOURCE_LEVEL_1"}
+			},
+			expected: serrors.ErrorMismatchVerifiedLevels,
+		},
+		{
+			name: "other framework levels ignored",
+			statement: func(s *Statement) {
+				s.Predicate.VerifiedLevels = []string{"FRSCA_LEVEL_3", "SLSA_BUILD_LEVEL_3"}
+			},
+			opts: func(o *options.VSAOpts) {
+				o.ExpectedLevels = []string{"SLSA_BUILD_LEVEL_3"}
+			},
+		},
+		{
+			name: "invalid expected level",
+			opts: func(o *options.VSAOpts) {
+				o.ExpectedLevels = []string{"BUILD_LEVEL_3"}
+			},
+			expected: serrors.ErrorInvalidFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			statement := testStatement()
+			if tt.statement != nil {
+				tt.statement(statement)
+			}
+			payload, err := json.Marshal(statement)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			opts := &options.VSAOpts{
+				ExpectedDigests:     map[string]string{"sha256": testDigest},
+				ExpectedVerifierID:  VerifierID,
+				ExpectedResourceURI: testResourceURI,
+			}
+			if tt.opts != nil {
+				tt.opts(opts)
+			}
+
+			_, err = Verify(payload, opts)
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Errorf(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
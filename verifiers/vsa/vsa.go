@@ -0,0 +1,177 @@
+package vsa
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+const (
+	// StatementType is the in-toto statement type of VSAs.
+	StatementType = "https://in-toto.io/Statement/v1"
+
+	// PredicateType is the SLSA v1.0 verification summary predicate type.
+	PredicateType = "https://slsa.dev/verification_summary/v1"
+
+	// VerifierID is the ID of this verifier, recorded in the VSAs it emits.
+	VerifierID = "https://github.com/slsa-framework/slsa-verifier"
+
+	// ResultPassed is the verification result of a successful verification.
+	ResultPassed = "PASSED"
+
+	// ResultFailed is the verification result of a failed verification.
+	ResultFailed = "FAILED"
+)
+
+// ResourceDescriptor describes a resource, such as the policy or an input
+// attestation.
+type ResourceDescriptor struct {
+	URI         string            `json:"uri,omitempty"`
+	Digest      map[string]string `json:"digest,omitempty"`
+	Name        string            `json:"name,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Verifier identifies the verifier that performed the verification.
+type Verifier struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
+
+// Predicate is the verification summary predicate.
+type Predicate struct {
+	Verifier           Verifier             `json:"verifier"`
+	TimeVerified       time.Time            `json:"timeVerified"`
+	ResourceURI        string               `json:"resourceUri"`
+	Policy             ResourceDescriptor   `json:"policy"`
+	InputAttestations  []ResourceDescriptor `json:"inputAttestations,omitempty"`
+	VerificationResult string               `json:"verificationResult"`
+	VerifiedLevels     []string             `json:"verifiedLevels"`
+	DependencyLevels   map[string]int       `json:"dependencyLevels,omitempty"`
+	SlsaVersion        string               `json:"slsaVersion,omitempty"`
+}
+
+// Statement is an in-toto statement with a verification summary predicate.
+type Statement struct {
+	Type          string           `json:"_type"`
+	Subject       []intoto.Subject `json:"subject"`
+	PredicateType string           `json:"predicateType"`
+	Predicate     Predicate        `json:"predicate"`
+}
+
+// Policy describes the expectations a verification was performed against.
+// Each expectation is recorded as an annotation, and the policy digest
+// covers all of them.
+func Policy(uri string, expectations map[string]string) (ResourceDescriptor, error) {
+	// Map keys are sorted, so the encoding is deterministic.
+	content, err := json.Marshal(expectations)
+	if err != nil {
+		return ResourceDescriptor{}, err
+	}
+	digest := sha256.Sum256(content)
+	return ResourceDescriptor{
+		URI:         uri,
+		Digest:      map[string]string{"sha256": hex.EncodeToString(digest[:])},
+		Annotations: expectations,
+	}, nil
+}
+
+// InputAttestation describes a verified provenance used as input to
+// the verification.
+func InputAttestation(uri string, provenance []byte) ResourceDescriptor {
+	digest := sha256.Sum256(provenance)
+	return ResourceDescriptor{
+		URI:    uri,
+		Digest: map[string]string{"sha256": hex.EncodeToString(digest[:])},
+	}
+}
+
+// l3Builders are the builders, without version, trusted to meet SLSA build
+// level 3: the SLSA GitHub generators and delegators, and the Google-hosted
+// Cloud Build workers.
+var l3Builders = map[string]bool{
+	common.GenericGeneratorBuilderID:         true,
+	common.ContainerGeneratorBuilderID:       true,
+	common.GoBuilderID:                       true,
+	common.ContainerBasedBuilderID:           true,
+	common.GenericDelegatorBuilderID:         true,
+	common.GenericLowPermsDelegatorBuilderID: true,
+	gcbHostedWorkerBuilderID:                 true,
+}
+
+// gcbHostedWorkerBuilderID is the builder ID of the Google-hosted Cloud
+// Build workers, without version.
+const gcbHostedWorkerBuilderID = "https://cloudbuild.googleapis.com/GoogleHostedWorker"
+
+// VerifiedLevels returns the SLSA build levels met by artifacts built by
+// a verified builder. Only the builders of l3Builders meet level 3. The
+// npm CLI builders on hosted runners meet level 2. Other builders, whose
+// isolation is unknown, only meet level 1: their provenance was verified.
+// Provenance verified with public keys also only meets level 1, whatever
+// its builder: the builder ID is read from a predicate signed by the
+// caller's keys rather than attested by the builder's platform.
+func VerifiedLevels(builderID *utils.TrustedBuilderID, builderOpts *options.BuilderOpts) []string {
+	if builderOpts != nil && len(builderOpts.PublicKeys) > 0 {
+		return []string{"SLSA_BUILD_LEVEL_1"}
+	}
+
+	name := builderID.Name()
+	switch {
+	case l3Builders[name]:
+		return []string{"SLSA_BUILD_LEVEL_3"}
+	case name == common.NpmCLILegacyBuilderID, name == common.NpmCLIHostedBuilderID:
+		return []string{"SLSA_BUILD_LEVEL_2"}
+	default:
+		return []string{"SLSA_BUILD_LEVEL_1"}
+	}
+}
+
+// New creates a VSA stating that the subjects passed verification at the
+// current time.
+func New(subjects []intoto.Subject, resourceURI, version string, policy ResourceDescriptor,
+	inputs []ResourceDescriptor, levels []string,
+) *Statement {
+	verifier := Verifier{ID: VerifierID}
+	if version != "" {
+		verifier.Version = map[string]string{"slsa-verifier": version}
+	}
+	return &Statement{
+		Type:          StatementType,
+		Subject:       subjects,
+		PredicateType: PredicateType,
+		Predicate: Predicate{
+			Verifier:           verifier,
+			TimeVerified:       time.Now().UTC().Truncate(time.Second),
+			ResourceURI:        resourceURI,
+			Policy:             policy,
+			InputAttestations:  inputs,
+			VerificationResult: ResultPassed,
+			VerifiedLevels:     levels,
+			SlsaVersion:        "1.0",
+		},
+	}
+}
+
+// Sign signs the VSA into a DSSE envelope.
+func Sign(ctx context.Context, statement *Statement, signer *dsselib.EnvelopeSigner) (*dsselib.Envelope, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", serrors.ErrorInvalidDssePayload, err)
+	}
+	env, err := signer.SignPayload(ctx, intoto.PayloadType, payload)
+	if err != nil {
+		return nil, fmt.Errorf("signing VSA: %w", err)
+	}
+	return env, nil
+}
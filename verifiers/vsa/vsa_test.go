@@ -0,0 +1,200 @@
+package vsa
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+func Test_VerifiedLevels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		builder    string
+		publicKeys bool
+		expected   []string
+	}{
+		{
+			name:     "generic generator",
+			builder:  common.GenericGeneratorBuilderID + "@v1.9.0",
+			expected: []string{"SLSA_BUILD_LEVEL_3"},
+		},
+		{
+			name:     "gcb",
+			builder:  "https://cloudbuild.googleapis.com/GoogleHostedWorker@v0.3",
+			expected: []string{"SLSA_BUILD_LEVEL_3"},
+		},
+		{
+			name:     "delegator",
+			builder:  common.GenericLowPermsDelegatorBuilderID + "@v1.9.0",
+			expected: []string{"SLSA_BUILD_LEVEL_3"},
+		},
+		{
+			name:     "gcb private pool",
+			builder:  "https://cloudbuild.googleapis.com/projects/p/locations/us-central1/workerPools/pool@v0.3",
+			expected: []string{"SLSA_BUILD_LEVEL_1"},
+		},
+		{
+			name:     "untrusted reusable workflow",
+			builder:  "https://github.com/org/repo/.github/workflows/builder.yml@v1.0.0",
+			expected: []string{"SLSA_BUILD_LEVEL_1"},
+		},
+		{
+			name:     "tekton",
+			builder:  "https://tekton.dev/chains/v2@v1",
+			expected: []string{"SLSA_BUILD_LEVEL_1"},
+		},
+		{
+			name:     "npm cli legacy",
+			builder:  common.NpmCLILegacyBuilderID,
+			expected: []string{"SLSA_BUILD_LEVEL_2"},
+		},
+		{
+			name:     "npm cli hosted",
+			builder:  common.NpmCLIHostedBuilderID,
+			expected: []string{"SLSA_BUILD_LEVEL_2"},
+		},
+		{
+			name:     "npm cli self-hosted",
+			builder:  common.NpmCLISelfHostedBuilderID,
+			expected: []string{"SLSA_BUILD_LEVEL_1"},
+		},
+		{
+			name:       "generic generator with public keys",
+			builder:    common.GenericGeneratorBuilderID + "@v1.9.0",
+			publicKeys: true,
+			expected:   []string{"SLSA_BUILD_LEVEL_1"},
+		},
+		{
+			name:       "gcb with public keys",
+			builder:    "https://cloudbuild.googleapis.com/GoogleHostedWorker@v0.3",
+			publicKeys: true,
+			expected:   []string{"SLSA_BUILD_LEVEL_1"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Re-initializing variable so it is not changed while executing the closure below
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			builderID, err := utils.TrustedBuilderIDNew(tt.builder, false)
+			if err != nil {
+				t.Fatalf("TrustedBuilderIDNew: %v", err)
+			}
+			builderOpts := &options.BuilderOpts{}
+			if tt.publicKeys {
+				builderOpts.PublicKeys = [][]byte{[]byte("key")}
+			}
+			if diff := cmp.Diff(tt.expected, VerifiedLevels(builderID, builderOpts)); diff != "" {
+				t.Errorf("unexpected levels (-want +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_Policy(t *testing.T) {
+	t.Parallel()
+
+	p1, err := Policy("https://example.com/policy", map[string]string{
+		"sourceUri": "github.com/some/repo",
+		"sourceTag": "v1.0.0",
+	})
+	if err != nil {
+		t.Fatalf("Policy: %v", err)
+	}
+	p2, err := Policy("https://example.com/policy", map[string]string{
+		"sourceTag": "v1.0.0",
+		"sourceUri": "github.com/some/repo",
+	})
+	if err != nil {
+		t.Fatalf("Policy: %v", err)
+	}
+	if diff := cmp.Diff(p1, p2); diff != "" {
+		t.Errorf("policy not deterministic (-want +got): \n%s", diff)
+	}
+
+	p3, err := Policy("https://example.com/policy", map[string]string{
+		"sourceUri": "github.com/some/repo",
+	})
+	if err != nil {
+		t.Fatalf("Policy: %v", err)
+	}
+	if p1.Digest["sha256"] == p3.Digest["sha256"] {
+		t.Errorf("different policies have the same digest %v", p1.Digest)
+	}
+}
+
+func Test_Sign(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := utils.DsseSignerNew(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), "")
+	if err != nil {
+		t.Fatalf("DsseSignerNew: %v", err)
+	}
+
+	subject := intoto.Subject{Name: "binary", Digest: map[string]string{"sha256": "abcd"}}
+	policy, err := Policy("https://example.com/policy", map[string]string{"sourceUri": "github.com/some/repo"})
+	if err != nil {
+		t.Fatalf("Policy: %v", err)
+	}
+	statement := New([]intoto.Subject{subject}, "binary", "v2.5.0", policy,
+		[]ResourceDescriptor{InputAttestation("binary.intoto.jsonl", []byte("{}"))},
+		[]string{"SLSA_BUILD_LEVEL_3"})
+
+	env, err := Sign(context.Background(), statement, signer)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if env.PayloadType != intoto.PayloadType {
+		t.Errorf("unexpected payload type %q", env.PayloadType)
+	}
+
+	pubDer, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := utils.DsseVerifierNew(pubDer, utils.KeyFormatDER, "", nil)
+	if err != nil {
+		t.Fatalf("DsseVerifierNew: %v", err)
+	}
+	if _, err := verifier.Verify(context.Background(), env); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	payload, err := utils.PayloadFromEnvelope(env)
+	if err != nil {
+		t.Fatalf("PayloadFromEnvelope: %v", err)
+	}
+	var got Statement
+	if err := json.Unmarshal(payload, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if diff := cmp.Diff(*statement, got); diff != "" {
+		t.Errorf("unexpected statement (-want +got): \n%s", diff)
+	}
+	if got.Predicate.VerificationResult != ResultPassed {
+		t.Errorf("unexpected verification result %q", got.Predicate.VerificationResult)
+	}
+}